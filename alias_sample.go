@@ -23,17 +23,59 @@
 package alias_sample
 
 import (
+	"math"
 	r "math/rand"
+	"sync/atomic"
+	"time"
 )
 
 type AliasSampler struct {
-	seed int64 // I save the initial seed since I want to use it in a different project
-	rand *r.Rand
+	seed int64   // I save the initial seed since I want to use it in a different project
+	rand *r.Rand // seeded from seed on first use; see source
+	name string  // for profiles and logs, under WithName
 
-	probability []float64
-	alias       []int
+	n    int
+	mode tableMode
+	only int // the one index with nonzero weight, under modeConstant
+
+	probability   []float64
+	probability32 []float32 // used instead of probability under WithFloat32
+	probability16 []uint16  // used instead of probability under WithFixed16
+	alias         []int
+	alias32       []uint32 // used instead of alias when the indices fit
+
+	/* Tables of up to tinyLen entries keep their columns here, inside
+	 * the struct, and probability and alias point into them.
+	 */
+	tiny      bool
+	tinyProb  [tinyLen]float64
+	tinyAlias [tinyLen]int
+
+	parent []int       // maps indices back to the original sampler, for Subset
+	cache  *tableCache // what is worked out from the table on demand
+
+	counts  []atomic.Uint64 // draws of each index, under WithTracking
+	metrics Metrics         // told about draws and builds, under WithMetrics
+	stats   Stats           // what the build recorded; see Stats
 }
 
+/* tableMode records which shortcut, if any, the sampler takes instead of
+ * consulting a table.
+ */
+type tableMode uint8
+
+const (
+	modeTable    tableMode = iota
+	modeUniform            // all weights are equal, so there is no table
+	modeConstant           // only one index can be drawn at all
+)
+
+/* tinyLen is the largest table kept inline.  It matches LinearMaxLen, past
+ * which a separate allocation costs little next to the table itself, and
+ * must be a power of two; see nextFrom.
+ */
+const tinyLen = 4
+
 type SampleError struct {
 	message string
 }
@@ -42,16 +84,8 @@ func (e *SampleError) Error() string {
 	return e.message
 }
 
-func Init(probs []float64) (*AliasSampler, error) {
-	// grab a random seed
-	seed := r.Int63()
-	return InitWithSeed(probs, seed)
-}
-
-func InitWithSeed(probs []float64, seed int64) (*AliasSampler, error) {
-	source := r.NewSource(seed)
-	rand := r.New(source)
-
+func Init(probs []float64, opts ...Option) (*AliasSampler, error) {
+	cfg := newConfig(opts)
 	if len(probs) == 0 {
 		return nil, &SampleError{"no probabilities provided"}
 	}
@@ -62,35 +96,304 @@ func InitWithSeed(probs []float64, seed int64) (*AliasSampler, error) {
 	probs2 := make([]float64, len(probs))
 	copy(probs2, probs)
 
-	var tot float64
-	for _, p := range probs2 {
-		tot += p
+	return build(probs2, cfg)
+}
+
+func InitWithSeed(probs []float64, seed int64, opts ...Option) (*AliasSampler, error) {
+	return Init(probs, append(opts[:len(opts):len(opts)], WithSeed(seed))...)
+}
+
+// MustInit is like Init, but panics if the sampler can't be built.  It is
+// meant for package-level variables and tests, where the weights are fixed
+// and an error could only be a programming mistake.
+func MustInit(probs []float64, opts ...Option) *AliasSampler {
+	s, err := Init(probs, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// MustInitWithSeed is like InitWithSeed, but panics if the sampler can't be
+// built.
+func MustInitWithSeed(probs []float64, seed int64, opts ...Option) *AliasSampler {
+	s, err := InitWithSeed(probs, seed, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// InitInPlace is like Init, but uses probs itself as scratch space instead
+// of copying it.  The contents of probs are undefined once it returns, and
+// probs must not be modified while the call is in progress.  This is meant
+// for very large weight slices, where the copy would double peak memory.
+func InitInPlace(probs []float64, opts ...Option) (*AliasSampler, error) {
+	cfg := newConfig(opts)
+	if len(probs) == 0 {
+		return nil, &SampleError{"no probabilities provided"}
+	}
+
+	return build(probs, cfg)
+}
+
+/* build constructs the table from probs2, which it is free to overwrite. */
+func build(probs2 []float64, cfg *config) (*AliasSampler, error) {
+	s := &AliasSampler{seed: cfg.seed, cache: &tableCache{}}
+	if err := s.fill(probs2, cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/* fill builds the table for probs2 into s, taking its storage from cfg. */
+func (s *AliasSampler) fill(probs2 []float64, cfg *config) (err error) {
+	s.name = cfg.name
+	if s.name == "" {
+		return s.fillTable(probs2, cfg)
+	}
+	profiled(s.name, "alias_sample.build", func() {
+		err = s.fillTable(probs2, cfg)
+	})
+	return err
+}
+
+func (s *AliasSampler) fillTable(probs2 []float64, cfg *config) (err error) {
+	start := time.Now()
+	var v vose
+	defer func() {
+		s.stats.BuildTime = time.Since(start)
+		if err == nil && s.metrics != nil {
+			s.metrics.Built(s.stats.BuildTime)
+		}
+		if cfg.logger != nil {
+			s.logBuild(cfg, v.diag, err)
+		}
+	}()
+
+	s.n = len(probs2)
+	if cfg.track {
+		s.counts = make([]atomic.Uint64, s.n)
+	}
+	s.metrics = cfg.metrics
+
+	if cfg.minProb != 0 || cfg.maxProb != 1 {
+		if err := clampProbs(probs2, cfg.minProb, cfg.maxProb); err != nil {
+			return err
+		}
+	}
+
+	/* If only one index can come up there is nothing to build, nor any
+	 * need to roll the dice.
+	 */
+	if only, ok := soleNonzero(probs2); ok {
+		s.mode = modeConstant
+		s.only = only
+		return nil
+	}
+
+	/* Uniform weights don't need a table at all: a fair die roll is the
+	 * whole answer.
+	 */
+	if isUniform(probs2) {
+		s.mode = modeUniform
+		return nil
+	}
+
+	v = vose{
+		probs2: probs2,
+		/* Compute the average probability and cache it for later use. */
+		average: 1.0 / float64(len(probs2)),
+	}
+	tiny := s.fitsInline(cfg)
+	switch {
+	case tiny:
+		v.alias = s.tinyAlias[:s.n:s.n]
+	case cap(cfg.alias) >= s.n || cfg.intAliases || s.wide():
+		v.alias = reuse(cfg.alias, s.n)
+	default:
+		v.alias32 = reuse(cfg.alias32, s.n)
+	}
+	switch {
+	case tiny:
+		v.probability = s.tinyProb[:s.n:s.n]
+	case cfg.column == columnFloat32:
+		v.probability32 = reuse(cfg.probability32, len(probs2))
+	case cfg.column == columnFixed16:
+		v.probability16 = reuse(cfg.probability16, len(probs2))
+	default:
+		v.probability = reuse(cfg.probability, len(probs2))
+	}
+
+	/* The squared and parallel builds share v with closures, so they
+	 * get their own copy to keep v itself off the heap.
+	 */
+	switch {
+	case cfg.squared:
+		hv := v
+		hv.buildSquared()
+		v = hv
+	case cfg.workers != 1 && len(probs2) >= 2*parallelChunk:
+		hv := v
+		hv.buildParallel(cfg.workers)
+		v = hv
+	default:
+		v.diag.total = v.total(0, len(probs2))
+		v.normalize(0, len(probs2), v.diag.total)
+
+		/* The small and large worklists are both plain stacks, and between
+		 * them they never hold more than n entries, so they share a single
+		 * scratch slice: small grows up from the front, large grows down
+		 * from the back.
+		 */
+		work := reuse(cfg.work, len(probs2))
+		nSmall, nLarge := v.classify(0, work)
+		v.diag.small, v.diag.large = nSmall, nLarge
+		nSmall, nLarge = v.pair(work, nSmall, nLarge)
+		v.finish(work, nSmall, nLarge)
+	}
+
+	s.mode = modeTable
+	s.tiny = tiny
+	s.probability = v.probability
+	s.probability32 = v.probability32
+	s.probability16 = v.probability16
+	s.alias = v.alias
+	s.alias32 = v.alias32
+	s.stats.Pairings = len(probs2) - v.leftover
+	s.stats.Residual = v.residual
+	return nil
+}
+
+/* fitsInline reports whether the table being built can live in s itself:
+ * it has to be small, use the float64 column, and not have been given
+ * buffers big enough to hold it.
+ */
+func (s *AliasSampler) fitsInline(cfg *config) bool {
+	return s.n <= tinyLen && cfg.column == columnFloat64 &&
+		cap(cfg.probability) < s.n && cap(cfg.alias) < s.n && cap(cfg.alias32) < s.n
+}
+
+/* inline moves a small float64 table into s itself, or, after s has been
+ * copied from another sampler, points its columns back at its own arrays.
+ */
+func (s *AliasSampler) inline() {
+	s.tiny = s.mode == modeTable && s.n <= tinyLen && s.probability != nil
+	if !s.tiny {
+		return
+	}
+	copy(s.tinyProb[:], s.probability)
+	for i := range s.n {
+		s.tinyAlias[i] = s.aliasOf(i)
+	}
+	s.probability = s.tinyProb[:s.n:s.n]
+	s.alias = s.tinyAlias[:s.n:s.n]
+	s.alias32 = nil
+}
+
+/* soleNonzero returns the only index with nonzero weight, if there is
+ * exactly one.
+ */
+func soleNonzero(probs []float64) (int, bool) {
+	only := -1
+	for i, p := range probs {
+		if p != 0 {
+			if only >= 0 {
+				return 0, false
+			}
+			only = i
+		}
 	}
+	return only, only >= 0
+}
 
-	for i := range probs2 {
-		probs2[i] /= tot
+/* uniformTolerance is how far apart, relative to the largest weight, the
+ * weights may be while still counting as uniform.
+ */
+const uniformTolerance = 1e-12
+
+func isUniform(probs []float64) bool {
+	lo, hi := probs[0], probs[0]
+	for _, p := range probs[1:] {
+		lo = min(lo, p)
+		hi = max(hi, p)
 	}
+	return hi > 0 && hi-lo <= uniformTolerance*hi
+}
+
+/* vose holds the state shared by the steps of Vose's algorithm. */
+type vose struct {
+	probs2        []float64
+	probability   []float64
+	probability32 []float32
+	probability16 []uint16
+	alias         []int
+	alias32       []uint32
+	average       float64
 
-	probability := make([]float64, len(probs))
-	alias := make([]int, len(probs))
+	leftover int     // entries that were never paired
+	residual float64 // how far the worst of them was from full
+	diag     buildDiag
+}
 
-	/* Compute the average probability and cache it for later use. */
-	average := 1.0 / float64(len(probs))
+func (v *vose) setProb(i int, p float64) {
+	switch {
+	case v.probability32 != nil:
+		v.probability32[i] = float32(p)
+	case v.probability16 != nil:
+		v.probability16[i] = toFixed16(p)
+	default:
+		v.probability[i] = p
+	}
+}
 
-	var small []int
-	var large []int
+func (v *vose) setAlias(i, a int) {
+	if v.alias32 != nil {
+		v.alias32[i] = uint32(a)
+	} else {
+		v.alias[i] = a
+	}
+}
 
+func (v *vose) total(lo, hi int) float64 {
+	var tot float64
+	for _, p := range v.probs2[lo:hi] {
+		tot += p
+	}
+	return tot
+}
+
+func (v *vose) normalize(lo, hi int, tot float64) {
+	for i := lo; i < hi; i++ {
+		v.probs2[i] /= tot
+	}
+}
+
+/* classify sorts the indices lo..lo+len(work)-1 into the small and large
+ * stacks held in work.
+ */
+func (v *vose) classify(lo int, work []int) (nSmall, nLarge int) {
 	/* Populate the stacks with the input probabilities. */
-	for i := range len(probs) {
+	for i := lo; i < lo+len(work); i++ {
 		/* If the probability is below the average probability, then we add
 		 * it to the small list; otherwise we add it to the large list.
 		 */
-		if probs2[i] >= average {
-			large = append(large, i)
+		if v.probs2[i] >= v.average {
+			nLarge++
+			work[len(work)-nLarge] = i
 		} else {
-			small = append(small, i)
+			work[nSmall] = i
+			nSmall++
 		}
 	}
+	return nSmall, nLarge
+}
+
+/* pair runs the main loop of the algorithm over the stacks in work, and
+ * returns what is left of them.
+ */
+func (v *vose) pair(work []int, nSmall, nLarge int) (int, int) {
+	n := float64(len(v.probs2))
 
 	/* As a note: in the mathematical specification of the algorithm, we
 	 * will always exhaust the small list before the big list.  However,
@@ -98,67 +401,193 @@ func InitWithSeed(probs []float64, seed int64) (*AliasSampler, error) {
 	 * Consequently, this inner loop (which tries to pair small and large
 	 * elements) will have to check that both lists aren't empty.
 	 */
-	for !(len(small) == 0) && !(len(large) == 0) {
+	for nSmall != 0 && nLarge != 0 {
 		/* Get the index of the small and the large probabilities. */
-		less := small[len(small)-1]
-		small = small[0 : len(small)-1]
-		more := large[len(large)-1]
-		large = large[0 : len(large)-1]
+		nSmall--
+		less := work[nSmall]
+		more := work[len(work)-nLarge]
+		nLarge--
 
 		/* These probabilities have not yet been scaled up to be such that
 		 * 1/n is given weight 1.0.  We do this here instead.
 		 */
-		probability[less] = probs2[less] * float64(len(probs))
-		alias[less] = more
+		v.setProb(less, v.probs2[less]*n)
+		v.setAlias(less, more)
 
 		/* Decrease the probability of the larger one by the appropriate
 		 * amount.
 		 */
-		probs2[more] = (probs2[more] + probs2[less]) - average
+		v.probs2[more] = (v.probs2[more] + v.probs2[less]) - v.average
 
 		/* If the new probability is less than the average, add it into the
 		 * small list; otherwise add it to the large list.
 		 */
-		if probs2[more] >= 1.0/float64(len(probs2)) {
-			large = append(large, more)
+		if v.probs2[more] >= v.average {
+			nLarge++
+			work[len(work)-nLarge] = more
 		} else {
-			small = append(small, more)
+			work[nSmall] = more
+			nSmall++
 		}
 	}
+	return nSmall, nLarge
+}
 
+func (v *vose) finish(work []int, nSmall, nLarge int) {
 	/* At this point, everything is in one list, which means that the
 	 * remaining probabilities should all be 1/n.  Based on this, set them
 	 * appropriately.  Due to numerical issues, we can't be sure which
 	 * stack will hold the entries, so we empty both.
 	 */
-	for _, s := range small {
-
-		probability[s] = 1.0
+	for _, s := range work[:nSmall] {
+		v.settle(s)
 	}
 
-	for _, l := range large {
-		probability[l] = 1.0
+	for _, l := range work[len(work)-nLarge:] {
+		v.settle(l)
 	}
+}
 
-	return &AliasSampler{
-		seed:        seed,
-		rand:        rand,
-		probability: probability,
-		alias:       alias,
-	}, nil
+/* settle makes i a full column of its own, keeping track of how much it
+ * had to be rounded to get there.
+ */
+func (v *vose) settle(i int) {
+	v.leftover++
+	v.residual = max(v.residual, math.Abs(v.probs2[i]/v.average-1))
+	v.setProb(i, 1.0)
+	v.setAlias(i, i)
 }
 
 func (s *AliasSampler) Next() int {
+	return s.NextFrom(s.source())
+}
+
+/* source returns the sampler's own random source, creating it on first
+ * use.  A source is several kilobytes, far more than a tiny table, so
+ * samplers only ever drawn from with NextFrom never pay for one.
+ */
+func (s *AliasSampler) source() *r.Rand {
+	if s.rand == nil {
+		s.rand = r.New(r.NewSource(s.seed))
+	}
+	return s.rand
+}
+
+// NextFrom is like Next, but draws its randomness from rng rather than the
+// sampler's own source.  The table is never modified after construction,
+// so NextFrom may be called concurrently as long as each goroutine uses
+// its own rng.
+func (s *AliasSampler) NextFrom(rng *r.Rand) int {
+	i := s.nextFrom(rng)
+	if s.counts != nil {
+		s.counts[i].Add(1)
+	}
+	if s.metrics != nil {
+		s.metrics.Drew(i)
+	}
+	return i
+}
+
+/* nextFrom is NextFrom without the tracking, for draws made on the way to
+ * some other result.
+ */
+func (s *AliasSampler) nextFrom(rng *r.Rand) int {
+	/* Tiny tables skip the mode checks and read the inline arrays
+	 * directly; the mask costs nothing, since the column is already below
+	 * tinyLen, and lets the compiler drop the bounds checks.
+	 */
+	if s.tiny {
+		column := rng.Intn(s.n) & (tinyLen - 1)
+		if rng.Float64() < s.tinyProb[column] {
+			return column
+		}
+		return s.tinyAlias[column]
+	}
+
+	if s.mode == modeConstant {
+		return s.only
+	}
+
 	/* Generate a fair die roll to determine which column to inspect. */
-	column := s.rand.Intn(len(s.probability))
+	column := rng.Intn(s.n)
+	if s.mode == modeUniform {
+		return column
+	}
 
 	/* Generate a biased coin toss to determine which option to pick. */
-	coinToss := s.rand.Float64() < s.probability[column]
+	coinToss := rng.Float64() < s.prob(column)
 
 	/* Based on the outcome, return either the column or its alias. */
 	if coinToss {
 		return column
+	} else if s.alias32 != nil {
+		return int(s.alias32[column])
 	} else {
 		return s.alias[column]
 	}
 }
+
+// Len returns the number of indices the sampler draws from.
+func (s *AliasSampler) Len() int {
+	return s.n
+}
+
+/* prob returns the probability column of the table, whichever way it is
+ * stored.
+ */
+func (s *AliasSampler) prob(column int) float64 {
+	switch {
+	case s.mode == modeUniform:
+		return 1.0
+	case s.mode == modeConstant:
+		if column == s.only {
+			return 1.0
+		}
+		return 0.0
+	case s.probability32 != nil:
+		return float64(s.probability32[column])
+	case s.probability16 != nil:
+		return fromFixed16(s.probability16[column])
+	default:
+		return s.probability[column]
+	}
+}
+
+/* wideIndices is the most columns a table can have before its indices
+ * outgrow the int32 range.  Indices are ints throughout, so wider tables
+ * work on 64-bit platforms, but batched draws split one 64-bit random
+ * value between the column and the coin toss, which past this size would
+ * leave the coin with fewer than 33 bits.
+ */
+const wideIndices = math.MaxInt32
+
+/* wide reports whether s has more columns than fit in an int32. */
+func (s *AliasSampler) wide() bool {
+	return uint64(s.n) > wideIndices
+}
+
+/* aliasOf returns the alias column of the table. */
+func (s *AliasSampler) aliasOf(column int) int {
+	switch s.mode {
+	case modeUniform:
+		return column
+	case modeConstant:
+		return s.only
+	}
+	if s.alias32 != nil {
+		return int(s.alias32[column])
+	}
+	return s.alias[column]
+}
+
+/* distribution recovers the probability of each index from the table, by
+ * adding up the shares each column hands out.
+ */
+func (s *AliasSampler) distribution() []float64 {
+	res := make([]float64, s.n)
+	s.eachShare(func(i int, share float64) bool {
+		res[i] += share
+		return true
+	})
+	return res
+}
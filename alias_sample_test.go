@@ -3,11 +3,27 @@ package alias_sample
 import (
 	"log"
 	"math"
+	r "math/rand"
 	"testing"
 
+	"github.com/evanmcc/alias_sample/testutil"
 	"pgregory.net/rapid"
 )
 
+/* tableProbs recovers the distribution a table samples from: each column
+ * contributes its own probability to itself and the remainder to its alias.
+ */
+func tableProbs(s *AliasSampler) []float64 {
+	n := float64(s.n)
+	res := make([]float64, s.n)
+	for i := range s.n {
+		p := s.prob(i)
+		res[i] += p / n
+		res[s.aliasOf(i)] += (1 - p) / n
+	}
+	return res
+}
+
 func TestInit(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
@@ -45,3 +61,212 @@ func TestInit(t *testing.T) {
 		}
 	})
 }
+
+func TestInitInPlace(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
+		seed := rapid.Int64().Draw(t, "seed")
+
+		want, err := InitWithSeed(probs, seed)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		scratch := make([]float64, len(probs))
+		copy(scratch, probs)
+		probBuf := make([]float64, 0, len(probs))
+		aliasBuf := make([]int, len(probs)+3)
+		got, err := InitInPlace(scratch, WithSeed(seed), WithBuffers(probBuf, aliasBuf))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if got.mode == modeTable && (&got.probability[0] != &probBuf[:1][0] || &got.alias[0] != &aliasBuf[0]) {
+			t.Fatalf("caller buffers were not used")
+		}
+
+		for i := range 1000 {
+			if w, g := want.Next(), got.Next(); w != g {
+				t.Fatalf("draw %d: got %d, want %d\n", i, g, w)
+			}
+		}
+	})
+}
+
+func TestMustInit(t *testing.T) {
+	probs := []float64{1, 2, 3, 4, 5}
+	a, b := MustInitWithSeed(probs, 3), MustInit(probs, WithSeed(3))
+	for range 100 {
+		if x, y := a.Next(), b.Next(); x != y {
+			t.Fatalf("same seed drew %d and %d\n", x, y)
+		}
+	}
+
+	for _, build := range []func(){
+		func() { MustInit(nil) },
+		func() { MustInitWithSeed([]float64{}, 1) },
+	} {
+		func() {
+			defer func() {
+				if _, ok := recover().(*SampleError); !ok {
+					t.Fatalf("no weights did not panic with a SampleError\n")
+				}
+			}()
+			build()
+		}()
+	}
+}
+
+func TestUniform(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(2, 100).Draw(t, "n")
+		w := rapid.Float64Range(0.001, 5.0).Draw(t, "w")
+		probs := make([]float64, n)
+		for i := range probs {
+			probs[i] = w
+		}
+		as, err := Init(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if as.mode != modeUniform || as.probability != nil || as.alias != nil || as.alias32 != nil {
+			t.Fatalf("uniform weights built a table")
+		}
+
+		probs[rapid.IntRange(0, n-1).Draw(t, "i")] *= 1 + 1e-9
+		as, err = Init(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if as.mode == modeUniform {
+			t.Fatalf("non-uniform weights were treated as uniform")
+		}
+	})
+}
+
+func TestConstant(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 100).Draw(t, "n")
+		only := rapid.IntRange(0, n-1).Draw(t, "only")
+		probs := make([]float64, n)
+		probs[only] = rapid.Float64Range(0.001, 5.0).Draw(t, "w")
+
+		as, err := Init(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if as.mode != modeConstant || as.probability != nil || as.alias != nil || as.alias32 != nil {
+			t.Fatalf("degenerate weights built a table")
+		}
+		draws := make([]int, 100)
+		as.NextN(draws)
+		for _, d := range append(draws, as.Next()) {
+			if d != only {
+				t.Fatalf("drew %d, want %d\n", d, only)
+			}
+		}
+	})
+}
+
+func TestTiny(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(2, tinyLen).Draw(t, "n")
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), n, n).Draw(t, "probs")
+		probs[0] += 10 /* above the rest, so the weights aren't uniform */
+		seed := rapid.Int64().Draw(t, "seed")
+
+		as, err := InitWithSeed(probs, seed)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if !as.tiny || &as.probability[0] != &as.tinyProb[0] || &as.alias[0] != &as.tinyAlias[0] {
+			t.Fatalf("%d-entry table was not kept inline\n", n)
+		}
+
+		/* A table built into the caller's buffers takes the general path,
+		 * and has to draw exactly the same indices.
+		 */
+		heap, err := InitWithSeed(probs, seed, WithBuffers(make([]float64, n), make([]int, n)))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if heap.tiny {
+			t.Fatalf("table built into buffers was kept inline\n")
+		}
+
+		data, err := as.MarshalBinary()
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		var loaded AliasSampler
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if !loaded.tiny || &loaded.probability[0] != &loaded.tinyProb[0] {
+			t.Fatalf("decoded table does not point at its own storage\n")
+		}
+
+		for range 200 {
+			a, h, l := as.Next(), heap.Next(), loaded.Next()
+			if a != h || a != l {
+				t.Fatalf("inline draw %d, general draw %d, decoded draw %d\n", a, h, l)
+			}
+		}
+	})
+
+	probs := make([]float64, tinyLen+1)
+	for i := range probs {
+		probs[i] = float64(i + 1)
+	}
+	if as, _ := Init(probs); as.tiny {
+		t.Fatalf("%d-entry table was kept inline\n", len(probs))
+	}
+	if as, _ := Init(probs[:3], WithFloat32()); as.tiny {
+		t.Fatalf("float32 table was kept inline\n")
+	}
+}
+
+func TestLazySource(t *testing.T) {
+	/* Drawing with NextFrom alone never creates the sampler's source. */
+	as, err := InitWithSeed([]float64{1, 2, 3}, 5)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	rng := r.New(r.NewSource(1))
+	for range 10 {
+		as.NextFrom(rng)
+	}
+	if as.rand != nil {
+		t.Fatalf("NextFrom created a source\n")
+	}
+
+	/* Next seeds it as Init used to, so the draws are unchanged. */
+	want := r.New(r.NewSource(5))
+	ref, _ := InitWithSeed([]float64{1, 2, 3}, 5)
+	for range 100 {
+		if got, w := as.Next(), ref.NextFrom(want); got != w {
+			t.Fatalf("drew %d, want %d\n", got, w)
+		}
+	}
+}
+
+func TestInitAdversarial(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := testutil.Weights().Draw(t, "weights")
+		as, err := Init(weights)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		for i, p := range as.probabilities() {
+			if want := weights[i] / total; math.Abs(p-want) > 1e-9 {
+				t.Fatalf("index %d: table gives %v, want %v\n", i, p, want)
+			}
+		}
+		if i := as.Next(); weights[i] == 0 {
+			t.Fatalf("drew %d, which has weight 0\n", i)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+// Package aliasbalancer is a gRPC load balancing policy that sends each
+// RPC to a ready backend chosen with probability proportional to its
+// weight.  Importing it registers the policy under Name; select it in the
+// service config:
+//
+//	grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"alias_weighted": {}}]}`)
+//
+// The resolver supplies the weights by attaching them to its addresses with
+// SetWeight.  Anything that knows the backends' load, such as a control
+// plane aggregating ORCA load reports, can steer traffic by pushing new
+// weights through the resolver; each update takes effect with the next
+// picker.  Picks never take a lock: every change of weights or backend
+// state builds a fresh, immutable alias table.
+package aliasbalancer
+
+import (
+	"cmp"
+	r "math/rand"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Name is the name the policy is registered under.
+const Name = "alias_weighted"
+
+func init() {
+	balancer.Register(builder{})
+}
+
+type weightKey struct{}
+
+// SetWeight returns addr with its weight set to weight.  Addresses without
+// a weight get 1; negative and non-finite weights count as zero.  If every
+// ready backend has weight zero they are all treated as equal, rather than
+// failing RPCs that could be served.
+func SetWeight(addr resolver.Address, weight float64) resolver.Address {
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(weightKey{}, weight)
+	return addr
+}
+
+// Weight returns the weight SetWeight attached to addr, if any.
+func Weight(addr resolver.Address) (float64, bool) {
+	w, ok := addr.BalancerAttributes.Value(weightKey{}).(float64)
+	return w, ok
+}
+
+type builder struct{}
+
+func (builder) Name() string {
+	return Name
+}
+
+/* Build wraps the base balancer, which manages the SubConns, in one that
+ * remembers the latest weights.  base keeps the address each SubConn was
+ * created with, attributes and all, so the weights have to be looked up
+ * by address instead of read from the addresses it hands the picker
+ * builder.
+ */
+func (builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	w := &weighted{weights: map[string]float64{}}
+	pb := &pickerBuilder{weights: w}
+	w.Balancer = base.NewBalancerBuilder(Name, pb, base.Config{HealthCheck: true}).Build(cc, opts)
+	return w
+}
+
+/* weighted is the balancer.  gRPC serializes calls into it, and picker
+ * builds happen within those calls, so weights needs no lock.
+ */
+type weighted struct {
+	balancer.Balancer
+	weights map[string]float64
+}
+
+func (w *weighted) UpdateClientConnState(s balancer.ClientConnState) error {
+	weights := make(map[string]float64, len(s.ResolverState.Addresses))
+	for _, addr := range s.ResolverState.Addresses {
+		if wt, ok := Weight(addr); ok {
+			weights[addr.Addr] = wt
+		}
+	}
+	w.weights = weights
+	return w.Balancer.UpdateClientConnState(s)
+}
+
+type pickerBuilder struct {
+	weights *weighted
+}
+
+func (pb *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	type ready struct {
+		addr string
+		sc   balancer.SubConn
+	}
+	scs := make([]ready, 0, len(info.ReadySCs))
+	for sc, sci := range info.ReadySCs {
+		scs = append(scs, ready{sci.Address.Addr, sc})
+	}
+	/* Sort so that the table doesn't depend on map order. */
+	slices.SortFunc(scs, func(a, b ready) int {
+		return cmp.Compare(a.addr, b.addr)
+	})
+
+	p := &picker{scs: make([]balancer.SubConn, len(scs))}
+	weights := make([]float64, len(scs))
+	var total float64
+	for i, rd := range scs {
+		p.scs[i] = rd.sc
+		weights[i] = 1
+		if w, ok := pb.weights.weights[rd.addr]; ok {
+			weights[i] = w
+			if !alias_sample.ValidWeight(w) {
+				weights[i] = 0
+			}
+		}
+		total += weights[i]
+	}
+	if total == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	s, err := alias_sample.Init(weights)
+	if err != nil {
+		return base.NewErrPicker(err)
+	}
+	p.s = s
+	return p
+}
+
+/* rands hands each concurrent pick a random source of its own. */
+var rands = sync.Pool{
+	New: func() any {
+		return r.New(r.NewSource(r.Int63()))
+	},
+}
+
+type picker struct {
+	scs []balancer.SubConn
+	s   *alias_sample.AliasSampler
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	rng := rands.Get().(*r.Rand)
+	i := p.s.NextFrom(rng)
+	rands.Put(rng)
+	return balancer.PickResult{SubConn: p.scs[i]}, nil
+}
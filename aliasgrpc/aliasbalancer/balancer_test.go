@@ -0,0 +1,140 @@
+package aliasbalancer
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	"github.com/evanmcc/alias_sample/aliasgrpc"
+)
+
+/* startBackends starts n servers on loopback and returns their addresses. */
+func startBackends(t *testing.T, n int) []string {
+	var addrs []string
+	for range n {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listening: %v\n", err)
+		}
+		srv := grpc.NewServer()
+		aliasgrpc.RegisterAliasSampleServer(srv, aliasgrpc.NewServer())
+		go srv.Serve(lis)
+		t.Cleanup(srv.Stop)
+		addrs = append(addrs, lis.Addr().String())
+	}
+	return addrs
+}
+
+/* share makes calls RPCs and returns the fraction each backend served. */
+func share(t *testing.T, client aliasgrpc.AliasSampleClient, calls int) map[string]float64 {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	counts := map[string]float64{}
+	for range calls {
+		var p peer.Peer
+		/* The distribution doesn't exist, but the error still says
+		 * which backend answered.
+		 */
+		client.Sample(ctx, &aliasgrpc.SampleRequest{Name: "none"}, grpc.Peer(&p), grpc.WaitForReady(true))
+		if p.Addr == nil {
+			t.Fatalf("call reached no backend\n")
+		}
+		counts[p.Addr.String()] += 1 / float64(calls)
+	}
+	return counts
+}
+
+func TestBalancer(t *testing.T) {
+	addrs := startBackends(t, 3)
+	res := manual.NewBuilderWithScheme("aliasbalancer")
+	res.InitialState(resolver.State{Addresses: []resolver.Address{
+		SetWeight(resolver.Address{Addr: addrs[0]}, 1),
+		SetWeight(resolver.Address{Addr: addrs[1]}, 3),
+		SetWeight(resolver.Address{Addr: addrs[2]}, 0),
+	}})
+	conn, err := grpc.NewClient(res.Scheme()+":///backends",
+		grpc.WithResolvers(res),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"alias_weighted": {}}]}`))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	defer conn.Close()
+	client := aliasgrpc.NewAliasSampleClient(conn)
+
+	/* Wait for both weighted backends to be ready. */
+	deadline := time.Now().Add(10 * time.Second)
+	for len(share(t, client, 100)) < 2 && time.Now().Before(deadline) {
+	}
+
+	got := share(t, client, 4000)
+	if math.Abs(got[addrs[0]]-0.25) > 0.04 || math.Abs(got[addrs[1]]-0.75) > 0.04 || got[addrs[2]] != 0 {
+		t.Fatalf("got shares %v for weights 1, 3, 0\n", got)
+	}
+
+	/* New weights take effect without reconnecting; the unweighted
+	 * address gets 1.
+	 */
+	res.UpdateState(resolver.State{Addresses: []resolver.Address{
+		SetWeight(resolver.Address{Addr: addrs[0]}, 0),
+		SetWeight(resolver.Address{Addr: addrs[1]}, 1),
+		{Addr: addrs[2]},
+	}})
+	deadline = time.Now().Add(10 * time.Second)
+	for share(t, client, 100)[addrs[0]] > 0 && time.Now().Before(deadline) {
+	}
+	got = share(t, client, 4000)
+	if got[addrs[0]] != 0 || math.Abs(got[addrs[1]]-0.5) > 0.04 || math.Abs(got[addrs[2]]-0.5) > 0.04 {
+		t.Fatalf("got shares %v for weights 0, 1, unset\n", got)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	addr := resolver.Address{Addr: "x"}
+	if _, ok := Weight(addr); ok {
+		t.Fatalf("weight of a plain address\n")
+	}
+	if w, ok := Weight(SetWeight(addr, 2.5)); !ok || w != 2.5 {
+		t.Fatalf("got weight %v, %v\n", w, ok)
+	}
+}
+
+type fakeSubConn struct {
+	balancer.SubConn
+	name string
+}
+
+func TestAllZero(t *testing.T) {
+	w := &weighted{weights: map[string]float64{"a": 0, "b": math.NaN()}}
+	a, b := &fakeSubConn{name: "a"}, &fakeSubConn{name: "b"}
+	p := (&pickerBuilder{weights: w}).Build(base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+		a: {Address: resolver.Address{Addr: "a"}},
+		b: {Address: resolver.Address{Addr: "b"}},
+	}})
+	seen := map[balancer.SubConn]bool{}
+	for range 100 {
+		res, err := p.Pick(balancer.PickInfo{})
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		seen[res.SubConn] = true
+	}
+	if !seen[a] || !seen[b] {
+		t.Fatalf("zero weights didn't fall back to equal ones: picked %v\n", seen)
+	}
+
+	p = (&pickerBuilder{weights: w}).Build(base.PickerBuildInfo{})
+	if _, err := p.Pick(balancer.PickInfo{}); err != balancer.ErrNoSubConnAvailable {
+		t.Fatalf("no ready SubConns: got err %v\n", err)
+	}
+}
@@ -0,0 +1,49 @@
+package aliasgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Client is a convenience wrapper around AliasSampleClient that speaks in
+// plain Go values.
+type Client struct {
+	c AliasSampleClient
+}
+
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{c: NewAliasSampleClient(conn)}
+}
+
+// Create registers a distribution.  A nil seed leaves the server to pick
+// one, so draws are not reproducible.
+func (c *Client) Create(ctx context.Context, name string, weights []float64, seed *int64) error {
+	_, err := c.c.CreateDistribution(ctx, &CreateDistributionRequest{Name: name, Weights: weights, Seed: seed})
+	return err
+}
+
+func (c *Client) Sample(ctx context.Context, name string) (int, error) {
+	resp, err := c.c.Sample(ctx, &SampleRequest{Name: name})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.Index), nil
+}
+
+func (c *Client) SampleBatch(ctx context.Context, name string, count int) ([]int, error) {
+	resp, err := c.c.SampleBatch(ctx, &SampleBatchRequest{Name: name, Count: int64(count)})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int, len(resp.Indices))
+	for i, idx := range resp.Indices {
+		res[i] = int(idx)
+	}
+	return res, nil
+}
+
+func (c *Client) UpdateWeights(ctx context.Context, name string, weights []float64) error {
+	_, err := c.c.UpdateWeights(ctx, &UpdateWeightsRequest{Name: name, Weights: weights})
+	return err
+}
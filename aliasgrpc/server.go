@@ -0,0 +1,116 @@
+// Package aliasgrpc serves alias_sample distributions over gRPC, so that
+// services in other languages can draw from weighted distributions that
+// are managed centrally.  The service is defined in aliassample.proto.  It
+// lives in its own module so that the main package doesn't depend on gRPC.
+package aliasgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative aliassample.proto
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// MaxBatch is the largest count a SampleBatch request may ask for.
+const MaxBatch = 1 << 20
+
+// Server implements AliasSampleServer, holding its distributions in
+// memory.  Each distribution is an alias_sample.Dynamic, so updates never
+// block draws, and draws for a seeded distribution are serialized so that
+// its sequence is reproducible.
+type Server struct {
+	UnimplementedAliasSampleServer
+
+	opts []alias_sample.Option
+
+	mu    sync.RWMutex
+	dists map[string]*distribution
+}
+
+type distribution struct {
+	mu sync.Mutex // serializes draws, which share the seeded source
+	d  *alias_sample.Dynamic
+}
+
+// NewServer returns an empty Server.  The options apply to every table it
+// builds; a seed given in a CreateDistribution request overrides any
+// WithSeed among them.
+func NewServer(opts ...alias_sample.Option) *Server {
+	return &Server{opts: opts, dists: map[string]*distribution{}}
+}
+
+func (s *Server) CreateDistribution(ctx context.Context, req *CreateDistributionRequest) (*CreateDistributionResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "distribution name is empty")
+	}
+	opts := s.opts
+	if req.Seed != nil {
+		opts = append(opts[:len(opts):len(opts)], alias_sample.WithSeed(*req.Seed))
+	}
+	d, err := alias_sample.NewDynamic(req.Weights, opts...)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.dists[req.Name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "distribution %q already exists", req.Name)
+	}
+	s.dists[req.Name] = &distribution{d: d}
+	return &CreateDistributionResponse{Len: int64(d.Len())}, nil
+}
+
+func (s *Server) Sample(ctx context.Context, req *SampleRequest) (*SampleResponse, error) {
+	dist, err := s.lookup(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	dist.mu.Lock()
+	defer dist.mu.Unlock()
+	return &SampleResponse{Index: int64(dist.d.Next())}, nil
+}
+
+func (s *Server) SampleBatch(ctx context.Context, req *SampleBatchRequest) (*SampleBatchResponse, error) {
+	if req.Count < 0 || req.Count > MaxBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 0 and %d", MaxBatch)
+	}
+	dist, err := s.lookup(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]int64, req.Count)
+	dist.mu.Lock()
+	defer dist.mu.Unlock()
+	for i := range indices {
+		indices[i] = int64(dist.d.Next())
+	}
+	return &SampleBatchResponse{Indices: indices}, nil
+}
+
+func (s *Server) UpdateWeights(ctx context.Context, req *UpdateWeightsRequest) (*UpdateWeightsResponse, error) {
+	dist, err := s.lookup(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := dist.d.Update(req.Weights); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &UpdateWeightsResponse{Len: int64(dist.d.Len())}, nil
+}
+
+func (s *Server) lookup(name string) (*distribution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dist, ok := s.dists[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no distribution %q", name)
+	}
+	return dist, nil
+}
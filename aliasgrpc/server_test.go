@@ -0,0 +1,84 @@
+package aliasgrpc
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func startServer(t *testing.T) *Client {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterAliasSampleServer(srv, NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	c := startServer(t)
+
+	seed := int64(42)
+	weights := []float64{1, 0, 3}
+	if err := c.Create(ctx, "colors", weights, &seed); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if err := c.Create(ctx, "colors", weights, nil); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("duplicate create: got err %v\n", err)
+	}
+
+	/* The server's draws follow the seeded sequence a local Dynamic gives. */
+	local, _ := alias_sample.NewDynamic(weights, alias_sample.WithSeed(seed))
+	want := make([]int, 101)
+	for i := range want {
+		want[i] = local.Next()
+	}
+	first, err := c.Sample(ctx, "colors")
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	rest, err := c.SampleBatch(ctx, "colors", 100)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if got := append([]int{first}, rest...); !slices.Equal(got, want) {
+		t.Fatalf("got draws %v, want %v\n", got, want)
+	}
+
+	if err := c.UpdateWeights(ctx, "colors", []float64{0, 1}); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if i, _ := c.Sample(ctx, "colors"); i != 1 {
+		t.Fatalf("after update drew %d\n", i)
+	}
+
+	if _, err := c.Sample(ctx, "nope"); status.Code(err) != codes.NotFound {
+		t.Fatalf("unknown distribution: got err %v\n", err)
+	}
+	if err := c.UpdateWeights(ctx, "colors", []float64{-1}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("negative weight: got err %v\n", err)
+	}
+	if _, err := c.SampleBatch(ctx, "colors", MaxBatch+1); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("oversized batch: got err %v\n", err)
+	}
+}
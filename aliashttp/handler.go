@@ -0,0 +1,151 @@
+// Package aliashttp serves named alias_sample distributions over HTTP.
+package aliashttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	r "math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// MaxBatch is the largest number of draws one request may ask for.
+const MaxBatch = 10_000
+
+// Handler serves these endpoints:
+//
+//	PUT /distributions/{name}         create or replace a distribution
+//	GET /distributions/{name}/sample  draw from it
+//
+// The PUT body is a JSON object {"weights": [...], "seed": 123}, where the
+// seed is optional and only used when the distribution is created.  The
+// sample endpoint takes an optional count n (default 1) and an optional
+// sticky key: draws for a key are derived from the key alone, so the same
+// key keeps getting the same answer until the weights change.  It responds
+// with {"indices": [...]}.
+//
+// To mount the handler under a prefix of another mux, strip the prefix:
+//
+//	mux.Handle("/weights/", http.StripPrefix("/weights", h))
+type Handler struct {
+	opts []alias_sample.Option
+	mux  *http.ServeMux
+
+	mu    sync.RWMutex
+	dists map[string]*distribution
+}
+
+type distribution struct {
+	mu sync.Mutex // serializes unkeyed draws, which share the seeded source
+	d  *alias_sample.Dynamic
+}
+
+// NewHandler returns a Handler with no distributions.  The options apply
+// to every table it builds.
+func NewHandler(opts ...alias_sample.Option) *Handler {
+	h := &Handler{opts: opts, mux: http.NewServeMux(), dists: map[string]*distribution{}}
+	h.mux.HandleFunc("PUT /distributions/{name}", h.put)
+	h.mux.HandleFunc("GET /distributions/{name}/sample", h.sample)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(w, req)
+}
+
+type putRequest struct {
+	Weights []float64 `json:"weights"`
+	Seed    *int64    `json:"seed,omitempty"`
+}
+
+type sampleResponse struct {
+	Indices []int `json:"indices"`
+}
+
+func (h *Handler) put(w http.ResponseWriter, req *http.Request) {
+	var body putRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64<<20)).Decode(&body); err != nil {
+		http.Error(w, "bad request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := req.PathValue("name")
+
+	/* Build outside the lock, so that draws from other distributions
+	 * don't wait on it.
+	 */
+	h.mu.RLock()
+	dist, ok := h.dists[name]
+	h.mu.RUnlock()
+	if ok {
+		if err := dist.d.Update(body.Weights); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	opts := h.opts
+	if body.Seed != nil {
+		opts = append(opts[:len(opts):len(opts)], alias_sample.WithSeed(*body.Seed))
+	}
+	d, err := alias_sample.NewDynamic(body.Weights, opts...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.dists[name]; ok {
+		/* Another request created it first. */
+		http.Error(w, fmt.Sprintf("distribution %q was created concurrently", name), http.StatusConflict)
+		return
+	}
+	h.dists[name] = &distribution{d: d}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) sample(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+	h.mu.RLock()
+	dist, ok := h.dists[name]
+	h.mu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no distribution %q", name), http.StatusNotFound)
+		return
+	}
+
+	n := 1
+	if s := req.URL.Query().Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n < 0 || n > MaxBatch {
+			http.Error(w, fmt.Sprintf("n must be between 0 and %d", MaxBatch), http.StatusBadRequest)
+			return
+		}
+	}
+
+	indices := make([]int, n)
+	if key, ok := req.URL.Query()["key"]; ok {
+		hash := fnv.New64a()
+		hash.Write([]byte(key[0]))
+		rng := r.New(r.NewSource(int64(hash.Sum64())))
+		s := dist.d.Sampler()
+		for i := range indices {
+			indices[i] = s.NextFrom(rng)
+		}
+	} else {
+		dist.mu.Lock()
+		for i := range indices {
+			indices[i] = dist.d.Next()
+		}
+		dist.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sampleResponse{Indices: indices})
+}
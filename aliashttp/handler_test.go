@@ -0,0 +1,69 @@
+package aliashttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(NewHandler())
+	defer srv.Close()
+
+	put := func(name, body string) int {
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/distributions/"+name, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	sample := func(query string) (int, []int) {
+		resp, err := http.Get(srv.URL + "/distributions/colors/sample" + query)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		defer resp.Body.Close()
+		var body sampleResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Indices
+	}
+
+	if code := put("colors", `{"weights": [1, 0, 3], "seed": 7}`); code != http.StatusCreated {
+		t.Fatalf("create: got status %d\n", code)
+	}
+	code, got := sample("?n=50")
+	if code != http.StatusOK || len(got) != 50 || slices.Contains(got, 1) {
+		t.Fatalf("sample: got status %d, indices %v\n", code, got)
+	}
+
+	/* A sticky key always gets the same draws. */
+	_, first := sample("?n=5&key=user-1")
+	_, again := sample("?n=5&key=user-1")
+	if !slices.Equal(first, again) {
+		t.Fatalf("sticky draws changed: %v then %v\n", first, again)
+	}
+
+	if code := put("colors", `{"weights": [0, 1]}`); code != http.StatusNoContent {
+		t.Fatalf("update: got status %d\n", code)
+	}
+	if _, got := sample(""); !slices.Equal(got, []int{1}) {
+		t.Fatalf("after update drew %v\n", got)
+	}
+
+	if code := put("bad", `{"weights": [-1]}`); code != http.StatusBadRequest {
+		t.Fatalf("negative weight: got status %d\n", code)
+	}
+	if code, _ := sample("?n=-1"); code != http.StatusBadRequest {
+		t.Fatalf("negative n: got status %d\n", code)
+	}
+	resp, _ := http.Get(srv.URL + "/distributions/nope/sample")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown distribution: got status %d\n", resp.StatusCode)
+	}
+	resp.Body.Close()
+}
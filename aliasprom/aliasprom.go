@@ -0,0 +1,111 @@
+// Package aliasprom exports the draws and builds of alias_sample samplers
+// as Prometheus metrics.  It lives in its own module so that the main
+// package doesn't depend on the Prometheus client.
+package aliasprom
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Metrics implements alias_sample.Metrics for one named sampler, and
+// prometheus.Collector so that it can be registered.  It exports:
+//
+//   - alias_sample_draws_total{sampler, index}, a counter of draws of each
+//     index, labelled by the name given to New if there is one;
+//   - alias_sample_builds_total{sampler}, a counter of table builds,
+//     rebuilds included;
+//   - alias_sample_build_seconds{sampler}, a histogram of build times.
+type Metrics struct {
+	labels []string
+
+	draws  *prometheus.CounterVec
+	builds prometheus.Counter
+	build  prometheus.Histogram
+
+	mu      sync.RWMutex
+	byIndex []prometheus.Counter // draws counters, resolved on first use
+}
+
+var _ alias_sample.Metrics = (*Metrics)(nil)
+
+// New returns Metrics for the sampler called name.  labels[i], if present,
+// is the value of the index label for index i; other indices are labelled
+// by number.  Every index that is drawn becomes its own time series, so
+// samplers with very many indices should have their draws bucketed into
+// coarser labels, which may repeat.
+func New(name string, labels []string) *Metrics {
+	constLabels := prometheus.Labels{"sampler": name}
+	return &Metrics{
+		labels: labels,
+		draws: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "alias_sample_draws_total",
+			Help:        "Draws returned by the sampler, by index.",
+			ConstLabels: constLabels,
+		}, []string{"index"}),
+		builds: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "alias_sample_builds_total",
+			Help:        "Tables built for the sampler, rebuilds included.",
+			ConstLabels: constLabels,
+		}),
+		build: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "alias_sample_build_seconds",
+			Help:        "Time taken to build the sampler's table.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(1e-6, 4, 12),
+		}),
+	}
+}
+
+func (m *Metrics) Drew(index int) {
+	m.counter(index).Inc()
+}
+
+func (m *Metrics) Built(d time.Duration) {
+	m.builds.Inc()
+	m.build.Observe(d.Seconds())
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.draws.Describe(ch)
+	m.builds.Describe(ch)
+	m.build.Describe(ch)
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.draws.Collect(ch)
+	m.builds.Collect(ch)
+	m.build.Collect(ch)
+}
+
+/* counter returns the draws counter for index, caching it so that the hot
+ * path doesn't have to format and hash the label on every draw.
+ */
+func (m *Metrics) counter(index int) prometheus.Counter {
+	m.mu.RLock()
+	if index < len(m.byIndex) && m.byIndex[index] != nil {
+		c := m.byIndex[index]
+		m.mu.RUnlock()
+		return c
+	}
+	m.mu.RUnlock()
+
+	label := strconv.Itoa(index)
+	if index < len(m.labels) {
+		label = m.labels[index]
+	}
+	c := m.draws.WithLabelValues(label)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if index >= len(m.byIndex) {
+		m.byIndex = append(m.byIndex, make([]prometheus.Counter, index+1-len(m.byIndex))...)
+	}
+	m.byIndex[index] = c
+	return c
+}
@@ -0,0 +1,49 @@
+package aliasprom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New("backends", []string{"a", "b"})
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(m); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	as, err := alias_sample.Init([]float64{0, 1, 0}, alias_sample.WithMetrics(m))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for range 10 {
+		as.Next()
+	}
+
+	want := `
+# HELP alias_sample_draws_total Draws returned by the sampler, by index.
+# TYPE alias_sample_draws_total counter
+alias_sample_draws_total{index="b",sampler="backends"} 10
+# HELP alias_sample_builds_total Tables built for the sampler, rebuilds included.
+# TYPE alias_sample_builds_total counter
+alias_sample_builds_total{sampler="backends"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"alias_sample_draws_total", "alias_sample_builds_total"); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if n := testutil.CollectAndCount(m, "alias_sample_build_seconds"); n != 1 {
+		t.Fatalf("got %d build histograms\n", n)
+	}
+
+	/* Indices past the labels are labelled by number. */
+	m.Drew(5)
+	if got := testutil.ToFloat64(m.draws.WithLabelValues("5")); got != 1 {
+		t.Fatalf("got %g draws of index 5\n", got)
+	}
+}
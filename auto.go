@@ -0,0 +1,112 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// An AutoOption configures InitAuto.  Every Option is also an AutoOption,
+// which is passed on to the backend InitAuto picks.
+type AutoOption interface {
+	applyAuto(c *autoConfig)
+}
+
+type autoConfig struct {
+	draws   int
+	updates int
+	opts    []Option
+}
+
+type autoOption func(c *autoConfig)
+
+func (o autoOption) applyAuto(c *autoConfig) {
+	o(c)
+}
+
+func (o Option) applyAuto(c *autoConfig) {
+	c.opts = append(c.opts, o)
+}
+
+// WithExpectedDraws tells InitAuto roughly how many draws the sampler will
+// serve over its lifetime.  Without it, InitAuto assumes the sampler is
+// long-lived.
+func WithExpectedDraws(draws int) AutoOption {
+	return autoOption(func(c *autoConfig) {
+		c.draws = draws
+	})
+}
+
+// WithExpectedUpdates tells InitAuto roughly how many single-weight
+// updates the sampler will see over its lifetime.  Each one is an O(n)
+// Update on a CDFSampler, or a full rebuild of an AliasSampler.
+func WithExpectedUpdates(updates int) AutoOption {
+	return autoOption(func(c *autoConfig) {
+		c.updates = updates
+	})
+}
+
+/* Rough per-entry and per-draw costs in nanoseconds, measured on amd64
+ * with n = 100k.  Only their ratios matter.
+ */
+const (
+	costAliasBuild = 16.0 // per entry
+	costAliasDraw  = 23.0
+	costCDFBuild   = 1.5 // per entry
+	costCDFUpdate  = 0.5 // per entry
+	costCDFStep    = 8.0 // per level of the binary search
+)
+
+// InitAuto picks a backend for probs based on its length and on the hints
+// given by WithExpectedDraws and WithExpectedUpdates, and otherwise takes
+// the same options as Init:
+//
+//   - an AliasSampler if any option only it honors is given: WithMinProb,
+//     WithMaxProb, WithTracking, WithMetrics, WithFloat32, WithFixed16,
+//     WithSquaredHistogram, WithIntAliases, WithBuffers, WithParallelism,
+//     WithName or WithLogger;
+//   - up to LinearMaxLen entries, a LinearSampler;
+//   - a CDFSampler when cheap construction and updates outweigh its
+//     slower draws;
+//   - an AliasSampler otherwise.
+//
+// Callers that expect to update weights should check whether they got a
+// *CDFSampler, which has an Update method.
+func InitAuto(probs []float64, autoOpts ...AutoOption) (Sampler, error) {
+	var ac autoConfig
+	for _, opt := range autoOpts {
+		opt.applyAuto(&ac)
+	}
+	opts := ac.opts
+	cfg := newConfig(opts)
+	n := float64(len(probs))
+	if cfg.aliasOnly() {
+		return asSampler(Init(probs, opts...))
+	}
+	if len(probs) <= LinearMaxLen {
+		return asSampler(InitLinear(probs, opts...))
+	}
+	if ac.draws <= 0 && ac.updates <= 0 {
+		return asSampler(Init(probs, opts...))
+	}
+
+	draws, updates := float64(ac.draws), float64(max(ac.updates, 0))
+	if ac.draws <= 0 {
+		draws = math.Inf(1)
+	}
+	alias := (1+updates)*costAliasBuild*n + draws*costAliasDraw
+	cdf := costCDFBuild*n + updates*costCDFUpdate*n + draws*costCDFStep*math.Log2(n)
+	if cdf < alias {
+		return asSampler(InitCDF(probs, opts...))
+	}
+	return asSampler(Init(probs, opts...))
+}
+
+/* aliasOnly reports whether c sets an option that only the alias table
+ * implements, and that the other backends would silently ignore.
+ */
+func (c *config) aliasOnly() bool {
+	return c.minProb > 0 || c.maxProb < 1 || c.track || c.metrics != nil ||
+		c.column != columnFloat64 || c.squared || c.intAliases ||
+		c.probability != nil || c.probability32 != nil || c.probability16 != nil ||
+		c.alias != nil || c.alias32 != nil || c.workers != 1 ||
+		c.name != "" || c.logger != nil
+}
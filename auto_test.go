@@ -0,0 +1,55 @@
+package alias_sample
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestInitAuto(t *testing.T) {
+	cases := []struct {
+		name string
+		n    int
+		opts []AutoOption
+		want string
+	}{
+		{"tiny", LinearMaxLen, nil, "linear"},
+		{"no hints", 1000, nil, "alias"},
+		{"many draws", 1000, []AutoOption{WithExpectedDraws(1e9)}, "alias"},
+		{"few draws", 1_000_000, []AutoOption{WithExpectedDraws(100)}, "cdf"},
+		{"frequent updates", 1000, []AutoOption{WithExpectedDraws(1e6), WithExpectedUpdates(1e4)}, "cdf"},
+		{"tiny with a floor", LinearMaxLen, []AutoOption{WithMinProb(0.01)}, "alias"},
+		{"tiny with a cap", LinearMaxLen, []AutoOption{WithMaxProb(0.5)}, "alias"},
+		{"tracked", LinearMaxLen, []AutoOption{WithTracking()}, "alias"},
+		{"few draws, float32", 1_000_000, []AutoOption{WithExpectedDraws(100), WithFloat32()}, "alias"},
+		{"few draws, metrics", 1_000_000, []AutoOption{WithExpectedDraws(100), WithMetrics(&countingMetrics{draws: map[int]int{}})}, "alias"},
+		{"tiny, int aliases", LinearMaxLen, []AutoOption{WithIntAliases()}, "alias"},
+		{"tiny, buffers", LinearMaxLen, []AutoOption{WithBuffers(make([]float64, 8), nil)}, "alias"},
+		{"tiny, named", LinearMaxLen, []AutoOption{WithName("auto")}, "alias"},
+		{"tiny, logged", LinearMaxLen, []AutoOption{WithLogger(slog.New(slog.DiscardHandler))}, "alias"},
+		{"few draws, parallel", 1_000_000, []AutoOption{WithExpectedDraws(100), WithParallelism(4)}, "alias"},
+	}
+	for _, c := range cases {
+		s, err := InitAuto(benchmarkProbs(c.n), c.opts...)
+		if err != nil {
+			t.Fatalf("%s: got err %v\n", c.name, err)
+		}
+		var got string
+		switch s.(type) {
+		case *LinearSampler:
+			got = "linear"
+		case *CDFSampler:
+			got = "cdf"
+		case *AliasSampler:
+			got = "alias"
+		}
+		if got != c.want {
+			t.Errorf("%s: got %s backend, want %s\n", c.name, got, c.want)
+		}
+	}
+}
+
+func TestInitAutoError(t *testing.T) {
+	if s, err := InitAuto(nil); err == nil || s != nil {
+		t.Fatalf("got %v, %v for empty probabilities\n", s, err)
+	}
+}
@@ -0,0 +1,75 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+/* bag is a growable multiset of weighted items, taken without replacement
+ * by weight, on top of a Weighted tree.  Slots freed by takes are reused.
+ * It does no locking of its own.
+ */
+type bag[T any] struct {
+	w       *Weighted
+	items   []T
+	weights []float64 // the weight of each slot; 0 if it is free
+	free    []int
+	count   int
+}
+
+const bagInitial = 16
+
+func newBag[T any]() *bag[T] {
+	b := &bag[T]{
+		w:       &Weighted{n: bagInitial, leaves: bagInitial, tree: make([]float64, 2*bagInitial)},
+		items:   make([]T, bagInitial),
+		weights: make([]float64, bagInitial),
+	}
+	for i := bagInitial - 1; i >= 0; i-- {
+		b.free = append(b.free, i)
+	}
+	return b
+}
+
+/* add puts item in the bag with weight, which must be positive. */
+func (b *bag[T]) add(item T, weight float64) {
+	if len(b.free) == 0 {
+		n := len(b.items)
+		b.w.grow(2 * n)
+		b.items = append(b.items, make([]T, n)...)
+		b.weights = append(b.weights, make([]float64, n)...)
+		for i := 2*n - 1; i >= n; i-- {
+			b.free = append(b.free, i)
+		}
+	}
+	i := b.free[len(b.free)-1]
+	b.free = b.free[:len(b.free)-1]
+	b.items[i], b.weights[i] = item, weight
+	b.w.set(i, weight)
+	b.count++
+}
+
+/* take removes an item chosen by weight, or reports false if the bag is
+ * empty.
+ */
+func (b *bag[T]) take(rng *r.Rand) (T, bool) {
+	var zero T
+	i, ok := b.w.TakeFrom(rng)
+	if !ok {
+		return zero, false
+	}
+	item := b.items[i]
+	b.items[i], b.weights[i] = zero, 0
+	b.free = append(b.free, i)
+	b.count--
+	return item, true
+}
+
+/* rescale replaces each item's weight w with f(w). */
+func (b *bag[T]) rescale(f func(float64) float64) {
+	for i, w := range b.weights {
+		if w > 0 {
+			b.weights[i] = f(w)
+		}
+	}
+	b.w.ReweightAll(b.weights)
+}
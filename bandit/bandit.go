@@ -0,0 +1,206 @@
+// Package bandit implements Thompson sampling for Bernoulli rewards, with
+// arm selection served from an alias table so that choosing an arm costs
+// the same as any other weighted draw, however many requests a second
+// need one.
+package bandit
+
+import (
+	"fmt"
+	r "math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Option configures a Bandit.
+type Option func(*config)
+
+type config struct {
+	refresh     int
+	samples     int
+	alpha, beta float64
+	seed        int64
+	seeded      bool
+}
+
+// WithRefresh rebuilds the selection table after every n updates.  The
+// default is 100.  Smaller values track the posterior more closely at the
+// cost of more rebuilds.
+func WithRefresh(n int) Option {
+	return func(c *config) {
+		c.refresh = n
+	}
+}
+
+// WithSamples sets how many posterior draws each rebuild uses to estimate
+// the arms' chances of being best.  The default is 1000.
+func WithSamples(n int) Option {
+	return func(c *config) {
+		c.samples = n
+	}
+}
+
+// WithPrior sets the Beta(alpha, beta) prior every arm starts from.  The
+// default is the uniform Beta(1, 1).
+func WithPrior(alpha, beta float64) Option {
+	return func(c *config) {
+		c.alpha, c.beta = alpha, beta
+	}
+}
+
+// WithSeed seeds the posterior draws made by rebuilds, and Select's random
+// source, making a Bandit reproducible when used from one goroutine.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed, c.seeded = seed, true
+	}
+}
+
+// Bandit chooses among arms by Thompson sampling: each arm is chosen with
+// the posterior probability that it has the highest success rate.  Rather
+// than drawing from every arm's posterior on each Select, a Bandit
+// estimates those probabilities from a batch of posterior draws every so
+// many updates and materializes them into an alias table, which Select
+// then draws from without locking.  Each arm's estimated chance counts
+// one extra win, so that no arm's chance of being tried falls to zero
+// between rebuilds.  All methods are safe for concurrent use.
+type Bandit struct {
+	table atomic.Pointer[snapshot]
+	rands sync.Pool
+
+	mu          sync.Mutex
+	rng         *r.Rand
+	alpha, beta []float64
+	refresh     int
+	samples     int
+	pending     int
+}
+
+/* snapshot is what a rebuild publishes: the selection table, and the
+ * chances it was built from, kept so that Probabilities reports them
+ * exactly rather than as the table rounds them.
+ */
+type snapshot struct {
+	s     *alias_sample.AliasSampler
+	probs []float64
+}
+
+// New returns a Bandit with the given number of arms, each at the prior.
+func New(arms int, opts ...Option) (*Bandit, error) {
+	cfg := &config{refresh: 100, samples: 1000, alpha: 1, beta: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if arms < 1 {
+		return nil, fmt.Errorf("bandit: need at least one arm, not %d", arms)
+	}
+	if cfg.refresh < 1 || cfg.samples < 1 {
+		return nil, fmt.Errorf("bandit: refresh and samples must be positive")
+	}
+	if !(cfg.alpha > 0 && cfg.beta > 0) {
+		return nil, fmt.Errorf("bandit: prior Beta(%v, %v) is not proper", cfg.alpha, cfg.beta)
+	}
+	if !cfg.seeded {
+		cfg.seed = r.Int63()
+	}
+
+	b := &Bandit{
+		rng:     r.New(r.NewSource(cfg.seed)),
+		alpha:   make([]float64, arms),
+		beta:    make([]float64, arms),
+		refresh: cfg.refresh,
+		samples: cfg.samples,
+	}
+	for i := range arms {
+		b.alpha[i], b.beta[i] = cfg.alpha, cfg.beta
+	}
+	seeds := r.New(r.NewSource(b.rng.Int63()))
+	var seedMu sync.Mutex
+	b.rands.New = func() any {
+		seedMu.Lock()
+		defer seedMu.Unlock()
+		return r.New(r.NewSource(seeds.Int63()))
+	}
+	b.rebuild()
+	return b, nil
+}
+
+// Select returns the arm to play.
+func (b *Bandit) Select() int {
+	rng := b.rands.Get().(*r.Rand)
+	arm := b.SelectFrom(rng)
+	b.rands.Put(rng)
+	return arm
+}
+
+// SelectFrom is Select using rng, which must not be used concurrently.
+func (b *Bandit) SelectFrom(rng *r.Rand) int {
+	return b.table.Load().s.NextFrom(rng)
+}
+
+// Update records the reward from playing arm: 1 for a success, 0 for a
+// failure, or anything in between as a fractional success.  Every
+// WithRefresh updates it rebuilds the selection table, on the calling
+// goroutine.
+func (b *Bandit) Update(arm int, reward float64) error {
+	if !(reward >= 0 && reward <= 1) {
+		return fmt.Errorf("bandit: reward %v is not between 0 and 1", reward)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if arm < 0 || arm >= len(b.alpha) {
+		return fmt.Errorf("bandit: no arm %d", arm)
+	}
+	b.alpha[arm] += reward
+	b.beta[arm] += 1 - reward
+	b.pending++
+	if b.pending >= b.refresh {
+		b.rebuild()
+	}
+	return nil
+}
+
+// Refresh rebuilds the selection table now, folding in every update so
+// far.
+func (b *Bandit) Refresh() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rebuild()
+}
+
+// Probabilities returns the chance Select gives each arm, as of the last
+// rebuild.
+func (b *Bandit) Probabilities() []float64 {
+	return slices.Clone(b.table.Load().probs)
+}
+
+/* rebuild estimates each arm's chance of having the best draw from its
+ * posterior and swaps in a table for them.  b.mu must be held, except
+ * during construction.
+ */
+func (b *Bandit) rebuild() {
+	wins := make([]float64, len(b.alpha))
+	for i := range wins {
+		wins[i] = 1
+	}
+	for range b.samples {
+		best, bestDraw := 0, -1.0
+		for i := range wins {
+			if x := alias_sample.Dirichlet([]float64{b.alpha[i], b.beta[i]}, b.rng)[0]; x > bestDraw {
+				best, bestDraw = i, x
+			}
+		}
+		wins[best]++
+	}
+	probs := make([]float64, len(wins))
+	total := float64(b.samples + len(wins))
+	for i, w := range wins {
+		probs[i] = w / total
+	}
+	/* Wins are positive, so the build can't fail. */
+	s, _ := alias_sample.InitInPlace(wins, alias_sample.WithSeed(b.rng.Int63()))
+	b.table.Store(&snapshot{s: s, probs: probs})
+	b.pending = 0
+}
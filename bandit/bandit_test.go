@@ -0,0 +1,96 @@
+package bandit
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestBanditLearns(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		rates := rapid.SliceOfN(rapid.Float64Range(0, 1), 2, 5).Draw(t, "rates")
+		best := 0
+		for i, p := range rates {
+			if p > rates[best] {
+				best = i
+			}
+		}
+		second := 0.0
+		for i, p := range rates {
+			if i != best {
+				second = max(second, p)
+			}
+		}
+		if rates[best]-second < 0.2 {
+			t.Skip("arms too close to tell apart quickly")
+		}
+
+		seed := rapid.Int64().Draw(t, "seed")
+		b, err := New(len(rates), WithSeed(seed), WithRefresh(20), WithSamples(200))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		rng := r.New(r.NewSource(seed))
+		for range 2000 {
+			arm := b.SelectFrom(rng)
+			reward := 0.0
+			if rng.Float64() < rates[arm] {
+				reward = 1
+			}
+			b.Update(arm, reward)
+		}
+		b.Refresh()
+		if p := b.Probabilities(); p[best] < 0.8 {
+			t.Fatalf("rates %v: best arm %d chosen with probability %v (%v)\n", rates, best, p[best], p)
+		}
+	})
+}
+
+func TestBanditPrior(t *testing.T) {
+	/* With nothing learned, every arm is equally likely. */
+	b, _ := New(4, WithSeed(1), WithSamples(20_000))
+	for i, p := range b.Probabilities() {
+		if math.Abs(p-0.25) > 0.02 {
+			t.Fatalf("arm %d has probability %v before any updates\n", i, p)
+		}
+	}
+
+	if err := b.Update(4, 1); err == nil {
+		t.Fatalf("update of a missing arm was accepted\n")
+	}
+	if err := b.Update(0, 1.5); err == nil {
+		t.Fatalf("reward above 1 was accepted\n")
+	}
+	for _, opts := range [][]Option{{WithRefresh(0)}, {WithSamples(-1)}, {WithPrior(0, 1)}} {
+		if _, err := New(2, opts...); err == nil {
+			t.Errorf("accepted options %v\n", opts)
+		}
+	}
+	if _, err := New(0); err == nil {
+		t.Fatalf("zero arms were accepted\n")
+	}
+}
+
+func TestBanditConcurrent(t *testing.T) {
+	b, _ := New(3, WithRefresh(10), WithSamples(50))
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 2000 {
+				arm := b.Select()
+				if g%2 == 0 {
+					b.Update(arm, float64(i%2))
+				} else if p := b.Probabilities(); len(p) != 3 {
+					t.Errorf("got %d probabilities for 3 arms\n", len(p))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,116 @@
+package alias_sample
+
+import (
+	"math/bits"
+	r "math/rand"
+)
+
+// NextN fills dst with independent draws.  It is much faster than calling
+// Next in a loop, because each draw uses a single 64-bit random value: the
+// high half of its product with n picks the column and the low half is the
+// coin toss.  (Picking the column this way is biased by at most n/2^64.)
+//
+// For a given seed NextN produces a different sequence than repeated
+// calls to Next, and the two can be interleaved freely.
+func (s *AliasSampler) NextN(dst []int) {
+	if s.name != "" && len(dst) >= profiledBatch {
+		profiled(s.name, "alias_sample.NextN", func() {
+			s.nextN(dst)
+		})
+	} else {
+		s.nextN(dst)
+	}
+	s.record(dst)
+}
+
+/* record counts the draws in dst, as NextFrom counts its single draw. */
+func (s *AliasSampler) record(dst []int) {
+	if s.counts != nil {
+		for _, i := range dst {
+			s.counts[i].Add(1)
+		}
+	}
+	if s.metrics != nil {
+		for _, i := range dst {
+			s.metrics.Drew(i)
+		}
+	}
+}
+
+func (s *AliasSampler) nextN(dst []int) {
+	s.nextNFrom(dst, s.source())
+}
+
+/* nextNFrom is nextN drawing from rng. */
+func (s *AliasSampler) nextNFrom(dst []int, rng *r.Rand) {
+	n := uint64(s.n)
+	switch s.mode {
+	case modeConstant:
+		for i := range dst {
+			dst[i] = s.only
+		}
+		return
+	case modeUniform:
+		for i := range dst {
+			column, _ := bits.Mul64(rng.Uint64(), n)
+			dst[i] = int(column)
+		}
+		return
+	}
+	if s.wide() {
+		s.nextNWide(dst, rng)
+		return
+	}
+	if s.probability == nil {
+		for i := range dst {
+			column, frac := bits.Mul64(rng.Uint64(), n)
+			if toUnit(frac) < s.prob(int(column)) {
+				dst[i] = int(column)
+			} else {
+				dst[i] = s.aliasOf(int(column))
+			}
+		}
+		return
+	}
+	if s.alias32 != nil {
+		nextNTable(dst, rng, s.probability, s.alias32)
+	} else {
+		nextNTable(dst, rng, s.probability, s.alias)
+	}
+}
+
+/* nextNWide is nextNFrom for tables wider than the int32 range.  One
+ * 64-bit value can't give both a column among that many and a fine enough
+ * coin, so it draws them separately.
+ */
+func (s *AliasSampler) nextNWide(dst []int, rng *r.Rand) {
+	for i := range dst {
+		dst[i] = s.nextFrom(rng)
+	}
+}
+
+/* nextNTable fills dst with draws from a table with a float64 probability
+ * column, whichever way its aliases are stored.
+ */
+func nextNTable[A int | uint32](dst []int, rng *r.Rand, probability []float64, alias []A) {
+	/* Reslice to a common length so that the compiler only has to bounds
+	 * check column once per draw.
+	 */
+	n := uint64(len(probability))
+	alias = alias[:n]
+	for i := range dst {
+		column, frac := bits.Mul64(rng.Uint64(), n)
+		if toUnit(frac) < probability[column] {
+			dst[i] = int(column)
+		} else {
+			dst[i] = int(alias[column])
+		}
+	}
+}
+
+/* toUnit maps a uniform 64-bit value onto [0, 1) with 53 bits of
+ * precision, the same way rand.Float64 does.
+ */
+func toUnit(x uint64) float64 {
+	return float64(x>>11) * 0x1p-53
+}
@@ -0,0 +1,144 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestNextN(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
+		opt := rapid.SampledFrom([]Option{WithSeed(1), WithFloat32(), WithFixed16()}).Draw(t, "opt")
+		as, err := Init(probs, opt)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		sz := 1_000_000
+		draws := make([]int, sz)
+		as.NextN(draws)
+		res := make([]int, len(probs))
+		for _, d := range draws {
+			res[d] += 1
+		}
+
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		for i, c := range res {
+			if p := float64(c) / float64(sz); math.Abs(p-probs[i]/tot) > 0.01 {
+				t.Fatalf("failed: index %d, %f, %f, %v\n", i, p, probs[i]/tot, res)
+			}
+		}
+	})
+}
+
+func benchmarkProbs(n int) []float64 {
+	probs := make([]float64, n)
+	for i := range probs {
+		probs[i] = float64(i%17 + 1)
+	}
+	return probs
+}
+
+func BenchmarkNext(b *testing.B) {
+	as, _ := InitWithSeed(benchmarkProbs(1000), 1)
+	for b.Loop() {
+		as.Next()
+	}
+}
+
+func BenchmarkNextN(b *testing.B) {
+	as, _ := InitWithSeed(benchmarkProbs(1000), 1)
+	dst := make([]int, 1024)
+	for b.Loop() {
+		as.NextN(dst)
+	}
+	b.ReportMetric(float64(b.N*len(dst))/b.Elapsed().Seconds(), "draws/s")
+}
+
+func TestWideIndices(t *testing.T) {
+	if math.MaxInt == math.MaxInt32 {
+		t.Skip("ints are 32 bits")
+	}
+
+	/* No table this wide fits in memory, but uniform and constant ones
+	 * have no columns to store, so they exercise the index handling.
+	 */
+	shift := 40
+	n := 1 << shift
+	uniform := &AliasSampler{n: n, mode: modeUniform, seed: 1}
+	uniform.rand = (&config{seed: 1}).newRand()
+	constant := &AliasSampler{n: n, mode: modeConstant, only: n - 3, seed: 2}
+	constant.rand = (&config{seed: 2}).newRand()
+
+	for _, s := range []*AliasSampler{uniform, constant} {
+		data, _ := s.MarshalBinary()
+		loaded, err := Load(data)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if loaded.Len() != n {
+			t.Fatalf("loaded %d indices, want %d\n", loaded.Len(), n)
+		}
+		dst := make([]int, 1000)
+		loaded.NextN(dst)
+		dst = append(dst, loaded.Next())
+		high := false
+		for _, i := range dst {
+			if i < 0 || i >= n {
+				t.Fatalf("drew %d of %d\n", i, n)
+			}
+			if s.mode == modeConstant && i != n-3 {
+				t.Fatalf("constant sampler drew %d, want %d\n", i, n-3)
+			}
+			high = high || uint64(i) > math.MaxUint32
+		}
+		if !high {
+			t.Fatalf("no draw of 1001 landed past the 32-bit range\n")
+		}
+	}
+}
+
+func TestNextNWide(t *testing.T) {
+	/* A table past the int32 range doesn't fit in memory, so check where
+	 * the width cutoff falls, and run the wide path on a table that fits.
+	 */
+	limit := int(wideIndices)
+	if (&AliasSampler{n: limit}).wide() {
+		t.Fatalf("a table of %d columns is wide\n", limit)
+	}
+	if limit < math.MaxInt && !(&AliasSampler{n: limit + 1}).wide() {
+		t.Fatalf("a table of %d columns is not wide\n", limit+1)
+	}
+
+	probs := []float64{1, 0, 3, 6}
+	as, err := InitWithSeed(probs, 1)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if as.mode != modeTable {
+		t.Fatalf("got mode %v, want a table\n", as.mode)
+	}
+
+	/* Draws match NextFrom's, one for one. */
+	sz := 100_000
+	got := make([]int, sz)
+	as.nextNWide(got, (&config{seed: 2}).newRand())
+	rng := (&config{seed: 2}).newRand()
+	res := make([]int, len(probs))
+	for i, d := range got {
+		if want := as.nextFrom(rng); d != want {
+			t.Fatalf("draw %d: got %d, want %d\n", i, d, want)
+		}
+		res[d]++
+	}
+	for i, c := range res {
+		if p := float64(c) / float64(sz); math.Abs(p-probs[i]/10) > 0.01 {
+			t.Fatalf("index %d drawn %f of the time, want %f\n", i, p, probs[i]/10)
+		}
+	}
+}
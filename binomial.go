@@ -0,0 +1,253 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+)
+
+/* binomialMaxTable is the largest n served from an exact table. */
+const binomialMaxTable = 1024
+
+// BinomialSampler draws the number of successes in n independent trials
+// that each succeed with probability p.
+//
+// For n up to 1024 it samples an exact alias table of the probability
+// mass function.  Beyond that it uses the BTPE algorithm of Kachitvichyanukul
+// and Schmeiser ("Binomial Random Variate Generation", CACM 31(2), 1988),
+// or plain inversion when n*min(p, 1-p) is below 30, where BTPE does not
+// apply.  Either way draws come from the same seeded stream as the rest
+// of the package.
+type BinomialSampler struct {
+	seed int64
+	rand *r.Rand
+
+	n     int
+	p     float64
+	table *AliasSampler // nil when n is too large for a table
+
+	/* parameters for the large-n algorithms, in terms of r = min(p, 1-p) */
+	r, q                      float64
+	m                         int
+	p1, p2, p3, p4            float64
+	xm, xl, xr, c, laml, lamr float64
+}
+
+// NewBinomial returns a binomial sampler for n trials with success
+// probability p.  Further options are applied to the table, if one is
+// used, as for Init.
+func NewBinomial(n int, p float64, seed int64, opts ...Option) (*BinomialSampler, error) {
+	if n < 0 {
+		return nil, &SampleError{"binomial: n must be non-negative"}
+	}
+	if !(p >= 0 && p <= 1) {
+		return nil, &SampleError{"binomial: p must be in [0, 1]"}
+	}
+
+	b := &BinomialSampler{seed: seed, rand: r.New(r.NewSource(seed)), n: n, p: p}
+	if n <= binomialMaxTable {
+		table, err := InitInPlace(binomialPMF(n, p), append(opts[:len(opts):len(opts)], WithSeed(seed))...)
+		if err != nil {
+			return nil, err
+		}
+		b.table = table
+		b.rand = table.source()
+		return b, nil
+	}
+
+	b.r = min(p, 1-p)
+	b.q = 1 - b.r
+	if float64(n)*b.r >= 30 {
+		b.setupBTPE()
+	}
+	return b, nil
+}
+
+/* binomialPMF returns P(k) for k = 0..n, computed in logs. */
+func binomialPMF(n int, p float64) []float64 {
+	pmf := make([]float64, n+1)
+	switch p {
+	case 0:
+		pmf[0] = 1
+		return pmf
+	case 1:
+		pmf[n] = 1
+		return pmf
+	}
+
+	lgn, _ := math.Lgamma(float64(n + 1))
+	lp, lq := math.Log(p), math.Log1p(-p)
+	for k := range pmf {
+		lgk, _ := math.Lgamma(float64(k + 1))
+		lgnk, _ := math.Lgamma(float64(n - k + 1))
+		pmf[k] = math.Exp(lgn - lgk - lgnk + float64(k)*lp + float64(n-k)*lq)
+	}
+	return pmf
+}
+
+func (b *BinomialSampler) setupBTPE() {
+	n, r, q := float64(b.n), b.r, b.q
+	fm := n*r + r
+	b.m = int(math.Floor(fm))
+	m := float64(b.m)
+	b.p1 = math.Floor(2.195*math.Sqrt(n*r*q)-4.6*q) + 0.5
+	b.xm = m + 0.5
+	b.xl = b.xm - b.p1
+	b.xr = b.xm + b.p1
+	b.c = 0.134 + 20.5/(15.3+m)
+	a := (fm - b.xl) / (fm - b.xl*r)
+	b.laml = a * (1 + a/2)
+	a = (b.xr - fm) / (b.xr * q)
+	b.lamr = a * (1 + a/2)
+	b.p2 = b.p1 * (1 + 2*b.c)
+	b.p3 = b.p2 + b.c/b.laml
+	b.p4 = b.p3 + b.c/b.lamr
+}
+
+func (b *BinomialSampler) Next() int {
+	return b.NextFrom(b.rand)
+}
+
+func (b *BinomialSampler) NextFrom(rng *r.Rand) int {
+	if b.table != nil {
+		return b.table.NextFrom(rng)
+	}
+
+	var y int
+	if float64(b.n)*b.r < 30 {
+		y = b.inversion(rng)
+	} else {
+		y = b.btpe(rng)
+	}
+
+	/* Both algorithms work with the smaller of p and 1-p. */
+	if b.p > 0.5 {
+		return b.n - y
+	}
+	return y
+}
+
+/* inversion walks the mass function up from zero, which takes O(n*r)
+ * expected steps.
+ */
+func (b *BinomialSampler) inversion(rng *r.Rand) int {
+	n := float64(b.n)
+	qn := math.Exp(n * math.Log(b.q))
+	np := n * b.r
+	bound := min(n, np+10*math.Sqrt(np*b.q+1))
+
+	x, px, u := 0, qn, rng.Float64()
+	for u > px {
+		x++
+		if float64(x) > bound {
+			x, px, u = 0, qn, rng.Float64()
+			continue
+		}
+		u -= px
+		px = (n - float64(x) + 1) * b.r * px / (float64(x) * b.q)
+	}
+	return x
+}
+
+/* btpe follows the step numbering of the original paper. */
+func (b *BinomialSampler) btpe(rng *r.Rand) int {
+	n, r, q := float64(b.n), b.r, b.q
+	m := float64(b.m)
+	nrq := n * r * q
+
+	for {
+		/* Step 1: the triangular region in the middle. */
+		u := rng.Float64() * b.p4
+		v := rng.Float64()
+		if u <= b.p1 {
+			return int(math.Floor(b.xm - b.p1*v + u))
+		}
+
+		var y float64
+		switch {
+		case u <= b.p2:
+			/* Step 2: the parallelograms. */
+			x := b.xl + (u-b.p1)/b.c
+			v = v*b.c + 1 - math.Abs(m-x+0.5)/b.p1
+			if v > 1 {
+				continue
+			}
+			y = math.Floor(x)
+		case u <= b.p3:
+			/* Step 3: the left exponential tail. */
+			y = math.Floor(b.xl + math.Log(v)/b.laml)
+			if y < 0 {
+				continue
+			}
+			v *= (u - b.p2) * b.laml
+		default:
+			/* Step 4: the right exponential tail. */
+			y = math.Floor(b.xr - math.Log(v)/b.lamr)
+			if y > n {
+				continue
+			}
+			v *= (u - b.p3) * b.lamr
+		}
+
+		/* Step 5.0: decide which acceptance test to use. */
+		k := math.Abs(y - m)
+		if k <= 20 || k >= nrq/2-1 {
+			/* Step 5.1: evaluate f(y)/f(m) by recursion. */
+			s := r / q
+			a := s * (n + 1)
+			f := 1.0
+			if m < y {
+				for i := m + 1; i <= y; i++ {
+					f *= a/i - s
+				}
+			} else if m > y {
+				for i := y + 1; i <= m; i++ {
+					f /= a/i - s
+				}
+			}
+			if v > f {
+				continue
+			}
+			return int(y)
+		}
+
+		/* Step 5.2: squeeze using upper and lower bounds on log(f(y)). */
+		rho := (k / nrq) * ((k*(k/3+0.625)+1.0/6)/nrq + 0.5)
+		t := -k * k / (2 * nrq)
+		lv := math.Log(v)
+		if lv < t-rho {
+			return int(y)
+		}
+		if lv > t+rho {
+			continue
+		}
+
+		/* Step 5.3: the final test against Stirling's approximation. */
+		if lv > btpeBound(n, r, q, m, y) {
+			continue
+		}
+		return int(y)
+	}
+}
+
+/* btpeBound is Stirling's approximation of log(f(y)/f(m)) from step 5.3
+ * of BTPE. The tails of the mode's factorials add to it and those of y's
+ * subtract.
+ */
+func btpeBound(n, r, q, m, y float64) float64 {
+	x1 := y + 1
+	f1 := m + 1
+	z := n + 1 - m
+	w := n - y + 1
+	return (m+0.5)*math.Log(f1/x1) +
+		(n-m+0.5)*math.Log(z/w) +
+		(y-m)*math.Log(w*r/(x1*q)) +
+		stirlingTail(f1) + stirlingTail(z) - stirlingTail(x1) - stirlingTail(w)
+}
+
+/* stirlingTail is the correction term of Stirling's series used in step
+ * 5.3 of BTPE.
+ */
+func stirlingTail(x float64) float64 {
+	x2 := x * x
+	return (13860 - (462-(132-(99-140/x2)/x2)/x2)/x2) / x / 166320
+}
@@ -0,0 +1,161 @@
+package alias_sample
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestBinomial(t *testing.T) {
+	cases := []struct {
+		n int
+		p float64
+	}{
+		{10, 0.3},        // table
+		{1000, 0.95},     // table
+		{100_000, 0.3},   // BTPE
+		{5000, 0.9},      // BTPE with p > 1/2
+		{100_000, 1e-4},  // inversion
+		{200_000, 0.999}, // inversion with p > 1/2
+	}
+	for _, c := range cases {
+		b, err := NewBinomial(c.n, c.p, 1)
+		if err != nil {
+			t.Fatalf("%v: got err %v\n", c, err)
+		}
+
+		sz := 100_000
+		counts := map[int]int{}
+		var sum, sumSq float64
+		for range sz {
+			k := b.Next()
+			if k < 0 || k > c.n {
+				t.Fatalf("%v: drew %d\n", c, k)
+			}
+			counts[k]++
+			sum += float64(k)
+			sumSq += float64(k) * float64(k)
+		}
+
+		n := float64(c.n)
+		mean, variance := n*c.p, n*c.p*(1-c.p)
+		gotMean := sum / float64(sz)
+		gotVar := sumSq/float64(sz) - gotMean*gotMean
+		if math.Abs(gotMean-mean) > 5*math.Sqrt(variance/float64(sz)) {
+			t.Errorf("%v: mean %g, want %g\n", c, gotMean, mean)
+		}
+		if math.Abs(gotVar-variance) > 0.03*variance {
+			t.Errorf("%v: variance %g, want %g\n", c, gotVar, variance)
+		}
+
+		/* Check the mass at and around the mode against the exact pmf. */
+		mode := int(math.Floor((n + 1) * c.p))
+		pmf := binomialPMF(c.n, c.p)
+		for k := max(mode-2, 0); k <= min(mode+2, c.n); k++ {
+			got := float64(counts[k]) / float64(sz)
+			if math.Abs(got-pmf[k]) > 5*math.Sqrt(pmf[k]/float64(sz))+1e-4 {
+				t.Errorf("%v: P(%d) = %g, want %g\n", c, k, got, pmf[k])
+			}
+		}
+	}
+
+	for _, n := range []int{10, 1 << 30} {
+		for _, p := range []float64{0, 1} {
+			b, err := NewBinomial(n, p, 1)
+			if err != nil {
+				t.Fatalf("p=%g: got err %v\n", p, err)
+			}
+			if got, want := b.Next(), int(p)*n; got != want {
+				t.Errorf("n=%d, p=%g: drew %d, want %d\n", n, p, got, want)
+			}
+		}
+	}
+	if _, err := NewBinomial(-1, 0.5, 1); err == nil {
+		t.Errorf("negative n was accepted")
+	}
+	if _, err := NewBinomial(10, math.NaN(), 1); err == nil {
+		t.Errorf("NaN p was accepted")
+	}
+}
+
+func TestStirlingTail(t *testing.T) {
+	/* The tail is what Stirling's formula leaves out of log(x!), which
+	 * is only known to within the rounding of Lgamma's result.
+	 */
+	for _, x := range []float64{10, 31, 100, 1234.5} {
+		lg, _ := math.Lgamma(x + 1)
+		want := lg - ((x+0.5)*math.Log(x) - x + 0.5*math.Log(2*math.Pi))
+		if got := stirlingTail(x); math.Abs(got-want) > 1e-13*lg {
+			t.Errorf("stirlingTail(%g) = %.15g, want %.15g\n", x, got, want)
+		}
+	}
+}
+
+func TestBinomialChiSquare(t *testing.T) {
+	/* With n*p*(1-p) this large, every draw more than 20 from the mode
+	 * that the squeeze of step 5.2 can't settle goes to step 5.3.
+	 */
+	n, p := 1_000_000, 0.3
+	b, err := NewBinomial(n, p, 1)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	pmf := binomialPMF(n, p)
+
+	/* Split the support into bins of about equal probability. */
+	bins := 50
+	edges := []int{}
+	var acc float64
+	for k, q := range pmf {
+		acc += q
+		if acc >= float64(len(edges)+1)/float64(bins) && len(edges) < bins-1 {
+			edges = append(edges, k+1)
+		}
+	}
+	expected := make([]float64, bins)
+	for k, q := range pmf {
+		expected[sort.SearchInts(edges, k+1)] += q
+	}
+
+	sz := 200_000
+	counts := make([]float64, bins)
+	for range sz {
+		counts[sort.SearchInts(edges, b.Next()+1)]++
+	}
+	var chi2 float64
+	for i, e := range expected {
+		e *= float64(sz)
+		chi2 += (counts[i] - e) * (counts[i] - e) / e
+	}
+	/* Mean bins-1, standard deviation sqrt(2(bins-1)); allow six. */
+	if df := float64(bins - 1); chi2 > df+6*math.Sqrt(2*df) {
+		t.Fatalf("chi-square %g with %d bins\n", chi2, bins)
+	}
+}
+
+func TestBTPEBound(t *testing.T) {
+	/* Step 5.3 is only reached away from the mode, where the bound should
+	 * match the exact log(f(y)/f(m)) to well within the squeeze.
+	 */
+	for _, n := range []int{1000, 5000, 100_000} {
+		for _, p := range []float64{0.05, 0.3, 0.5} {
+			nf, q := float64(n), 1-p
+			m := math.Floor((nf + 1) * p)
+			lm, _ := math.Lgamma(m + 1)
+			lnm, _ := math.Lgamma(nf - m + 1)
+			sd := math.Sqrt(nf * p * q)
+			for _, d := range []float64{-3, -2, -1, 1, 2, 3} {
+				y := math.Floor(m + d*sd)
+				if y < 0 || y > nf || y == m {
+					continue
+				}
+				ly, _ := math.Lgamma(y + 1)
+				lny, _ := math.Lgamma(nf - y + 1)
+				want := lm + lnm - ly - lny + (y-m)*math.Log(p/q)
+				if got := btpeBound(nf, p, q, m, y); math.Abs(got-want) > 1e-9*max(1, math.Abs(want)) {
+					t.Errorf("n=%d p=%g y=%g: bound %.10g, want %.10g\n", n, p, y, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"slices"
+)
+
+// BucketedSampler splits the indices into about √n buckets, each with an
+// alias table of its own, under a top-level alias table over the
+// buckets' total weights.  A draw costs two alias draws, and changing one
+// weight rebuilds only its bucket and the top table, O(√n) in all.  That
+// sits between an AliasSampler, whose draws are cheaper but whose updates
+// rebuild everything, and a CDFSampler, whose updates are O(n) and draws
+// O(log n).
+//
+// As with CDFSampler, Update must not be called concurrently with draws.
+type BucketedSampler struct {
+	seed int64
+	rand *r.Rand
+	opts []Option
+
+	weights []float64
+	size    int // indices per bucket; the last may have fewer
+	masses  []float64
+	buckets []*AliasSampler // nil for buckets with no weight
+	top     *AliasSampler
+}
+
+// InitBucketed builds a bucketed sampler over probs, which must be finite
+// and non-negative with some weight, and stay so through every Update.
+// The options apply to every table it builds, apart from WithBuffers, as
+// every table needs storage of its own.  WithMinProb and WithMaxProb are
+// rejected: the tables only see bucket totals and bucket members, so they
+// can't bound the probability of each index.
+func InitBucketed(probs []float64, opts ...Option) (*BucketedSampler, error) {
+	if err := checkWeights(probs); err != nil {
+		return nil, err
+	}
+	opts = ownBuffers(opts)
+	cfg := newConfig(opts)
+	if cfg.minProb != 0 || cfg.maxProb != 1 {
+		return nil, &SampleError{"bucketed sampler does not support probability bounds"}
+	}
+	n := len(probs)
+	size := int(math.Ceil(math.Sqrt(float64(n))))
+	s := &BucketedSampler{
+		seed:    cfg.seed,
+		rand:    cfg.newRand(),
+		opts:    opts,
+		weights: slices.Clone(probs),
+		size:    size,
+		masses:  make([]float64, (n+size-1)/size),
+		buckets: make([]*AliasSampler, (n+size-1)/size),
+	}
+
+	/* Build every bucket in one go, as BuildMany shares their storage. */
+	var sets [][]float64
+	var full []int
+	for b := range s.masses {
+		ws := s.bucket(b)
+		s.masses[b] = sum(ws)
+		if s.masses[b] > 0 {
+			sets = append(sets, ws)
+			full = append(full, b)
+		}
+	}
+	built, err := BuildMany(sets, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for i, b := range full {
+		s.buckets[b] = built[i]
+	}
+	if s.top, err = Init(s.masses, opts...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *BucketedSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *BucketedSampler) NextFrom(rng *r.Rand) int {
+	b := s.top.NextFrom(rng)
+	return b*s.size + s.buckets[b].NextFrom(rng)
+}
+
+func (s *BucketedSampler) Len() int {
+	return len(s.weights)
+}
+
+// Weight returns the current weight of index i.
+func (s *BucketedSampler) Weight(i int) float64 {
+	return s.weights[i]
+}
+
+// Update sets the weight of index i to w, rebuilding i's bucket and the
+// top table.  It fails, changing nothing, if w is negative or not finite,
+// or if it would leave no weight at all.
+func (s *BucketedSampler) Update(i int, w float64) error {
+	if i < 0 || i >= len(s.weights) {
+		return &SampleError{"index out of range"}
+	}
+	if !ValidWeight(w) {
+		return &SampleError{"weights must be finite and non-negative"}
+	}
+	b := i / s.size
+	old := s.weights[i]
+	s.weights[i] = w
+
+	/* Sum the bucket afresh rather than adjusting its mass, so rounding
+	 * can't build up over many updates.
+	 */
+	mass := sum(s.bucket(b))
+	masses := slices.Clone(s.masses)
+	masses[b] = mass
+	var bucket *AliasSampler
+	var err error
+	if mass > 0 {
+		bucket, err = Init(s.bucket(b), s.opts...)
+	}
+	var top *AliasSampler
+	if err == nil {
+		if err = checkWeights(masses); err == nil {
+			top, err = Init(masses, s.opts...)
+		}
+	}
+	if err != nil {
+		s.weights[i] = old
+		return err
+	}
+	s.masses, s.buckets[b], s.top = masses, bucket, top
+	return nil
+}
+
+// Prob returns the probability of drawing index i.
+func (s *BucketedSampler) Prob(i int) float64 {
+	if i < 0 || i >= len(s.weights) || s.weights[i] == 0 {
+		return 0
+	}
+	b := i / s.size
+	return s.top.Prob(b) * s.buckets[b].Prob(i-b*s.size)
+}
+
+/* bucket returns the weights of bucket b. */
+func (s *BucketedSampler) bucket(b int) []float64 {
+	return s.weights[b*s.size : min((b+1)*s.size, len(s.weights))]
+}
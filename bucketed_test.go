@@ -0,0 +1,124 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestBucketedSampler(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 3), 1, 60).Draw(t, "probs")
+		probs[0] += 0.5
+		s, err := InitBucketed(probs, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Apply some updates, checking each rebuilds the right way. */
+		for range rapid.IntRange(0, 10).Draw(t, "updates") {
+			i := rapid.IntRange(0, len(probs)-1).Draw(t, "i")
+			w := rapid.Float64Range(0, 3).Draw(t, "w")
+			var rest float64
+			for j, p := range probs {
+				if j != i {
+					rest += p
+				}
+			}
+			err := s.Update(i, w)
+			if rest+w == 0 {
+				if err == nil {
+					t.Fatalf("update left no weight but succeeded\n")
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+			probs[i] = w
+		}
+
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		sz := 50_000
+		got := make([]float64, len(probs))
+		for range sz {
+			got[s.Next()]++
+		}
+		for i, p := range probs {
+			if s.Weight(i) != p {
+				t.Fatalf("weight %d is %v, want %v\n", i, s.Weight(i), p)
+			}
+			p /= tot
+			if math.Abs(s.Prob(i)-p) > 1e-9 {
+				t.Fatalf("prob %d is %v, want %v\n", i, s.Prob(i), p)
+			}
+			if math.Abs(got[i]-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+				t.Fatalf("drew %d %v times of %d, want p %v\n", i, got[i], sz, p)
+			}
+		}
+	})
+}
+
+func TestBucketedSamplerInvalid(t *testing.T) {
+	s, _ := InitBucketed([]float64{1, 2, 3, 4, 5})
+	for name, c := range map[string]struct {
+		i int
+		w float64
+	}{
+		"range":    {5, 1},
+		"negative": {0, -1},
+		"infinite": {0, math.Inf(1)},
+	} {
+		if err := s.Update(c.i, c.w); err == nil {
+			t.Fatalf("%s: expected error\n", name)
+		}
+	}
+	if _, err := InitBucketed([]float64{0, 0}); err == nil {
+		t.Fatalf("expected error for all-zero weights\n")
+	}
+}
+
+func TestBucketedSamplerOptions(t *testing.T) {
+	w := make([]float64, 16)
+	w[0] = 1
+	for _, opt := range []Option{WithMinProb(0.1), WithMaxProb(0.5)} {
+		if _, err := InitBucketed(w, opt); err == nil {
+			t.Fatalf("probability bounds were accepted\n")
+		}
+	}
+
+	/* Every table needs its own storage, whatever buffers are given. */
+	probs := make([]float64, 16)
+	for i := range probs {
+		probs[i] = float64(i%5 + 1)
+	}
+	s, err := InitBucketed(probs, WithSeed(1), WithBuffers(make([]float64, 64), make([]int, 64)))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for _, u := range []struct {
+		i int
+		w float64
+	}{{3, 9}, {12, 0.5}} {
+		if err := s.Update(u.i, u.w); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		probs[u.i] = u.w
+	}
+	tot := sum(probs)
+	sz := 100_000
+	got := make([]float64, len(probs))
+	for range sz {
+		got[s.Next()]++
+	}
+	for i, w := range probs {
+		p := w / tot
+		if math.Abs(got[i]-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p)) {
+			t.Fatalf("drew %d %v times of %d, want p %v\n", i, got[i], sz, p)
+		}
+	}
+}
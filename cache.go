@@ -0,0 +1,207 @@
+package alias_sample
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// Cache hands out shared samplers for weight vectors that are requested
+// over and over, building each distinct one only once.  Entries are keyed
+// by a SHA-256 hash of the weights together with the options that shape
+// the table (WithName, WithFloat32, WithFixed16, WithIntAliases,
+// WithSquaredHistogram, WithMinProb, WithMaxProb and WithTracking), and
+// evicted least recently used first once there are more than a set number
+// or their tables hold more than a set number of bytes.  Other options, such as WithSeed and WithMetrics, take effect
+// from whichever Get built the entry.
+//
+// All methods are safe for concurrent use.  Concurrent Gets for the same
+// weights wait for one build rather than each making their own.  The
+// samplers returned are shared, so callers on different goroutines must
+// draw with NextFrom and their own rand.Rand.
+type Cache struct {
+	maxEntries int
+	maxBytes   int
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List // of *cacheEntry, most recently used first
+	bytes   int
+	hits    uint64
+	misses  uint64
+}
+
+type cacheKey [sha256.Size]byte
+
+/* cacheHashBatch is how many words cacheKeyOf encodes before handing them
+ * to the hash.
+ */
+const cacheHashBatch = 512
+
+type cacheEntry struct {
+	key   cacheKey
+	done  chan struct{} // closed once s and err are set
+	s     *AliasSampler
+	err   error
+	bytes int
+	built bool // set under Cache.mu once s is, so eviction can see it
+}
+
+// NewCache returns a cache of at most maxEntries samplers whose tables
+// hold at most maxBytes between them, by Stats.TableBytes.  Zero or less
+// means no limit of that kind.  The most recently built entry is kept
+// even if it alone exceeds maxBytes.
+func NewCache(maxEntries, maxBytes int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		entries:    map[cacheKey]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// Get returns the cached sampler for probs and opts, building it with Init
+// if there is none.  A failed build is not cached.
+func (c *Cache) Get(probs []float64, opts ...Option) (*AliasSampler, error) {
+	key := cacheKeyOf(probs, newConfig(opts))
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.hits++
+		c.lru.MoveToFront(el)
+		c.mu.Unlock()
+		e := el.Value.(*cacheEntry)
+		<-e.done
+		return e.s, e.err
+	}
+	c.misses++
+	e := &cacheEntry{key: key, done: make(chan struct{})}
+	c.entries[key] = c.lru.PushFront(e)
+	c.mu.Unlock()
+
+	e.s, e.err = Init(probs, opts...)
+	if e.err == nil {
+		e.bytes = e.s.Stats().TableBytes
+	}
+	close(e.done)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.err != nil {
+		c.remove(e)
+		return nil, e.err
+	}
+	e.built = true
+	if el, ok := c.entries[key]; ok && el.Value == e {
+		c.bytes += e.bytes
+		c.evict(e)
+	}
+	return e.s, nil
+}
+
+// Len returns the number of cached samplers, counting any being built.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Bytes returns the memory held by the cached tables.
+func (c *Cache) Bytes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
+
+// Hits returns how many Gets found their sampler in the cache, and how
+// many had to build it.
+func (c *Cache) Hits() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Purge empties the cache.  Samplers already handed out stay usable.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[cacheKey]*list.Element{}
+	c.lru.Init()
+	c.bytes = 0
+}
+
+/* evict drops least recently used entries until the cache is within its
+ * limits, never dropping keep or entries still being built.  c.mu must be
+ * held.
+ */
+func (c *Cache) evict(keep *cacheEntry) {
+	for el := c.lru.Back(); el != nil; {
+		over := c.maxEntries > 0 && c.lru.Len() > c.maxEntries ||
+			c.maxBytes > 0 && c.bytes > c.maxBytes
+		if !over {
+			return
+		}
+		prev := el.Prev()
+		e := el.Value.(*cacheEntry)
+		if e != keep && e.built {
+			c.remove(e)
+		}
+		el = prev
+	}
+}
+
+/* remove drops e if it is still the entry for its key.  c.mu must be
+ * held.
+ */
+func (c *Cache) remove(e *cacheEntry) {
+	el, ok := c.entries[e.key]
+	if !ok || el.Value != e {
+		return
+	}
+	delete(c.entries, e.key)
+	c.lru.Remove(el)
+	c.bytes -= e.bytes
+}
+
+/* cacheKeyOf hashes the weights and the parts of cfg that change what Init
+ * builds.
+ */
+func cacheKeyOf(probs []float64, cfg *config) cacheKey {
+	h := sha256.New()
+	buf := make([]byte, 0, 8*cacheHashBatch)
+	put := func(v uint64) {
+		buf = binary.LittleEndian.AppendUint64(buf, v)
+		if len(buf) == cap(buf) {
+			h.Write(buf)
+			buf = buf[:0]
+		}
+	}
+	flags := uint64(0)
+	if cfg.squared {
+		flags |= 1
+	}
+	if cfg.track {
+		flags |= 2
+	}
+	if cfg.intAliases {
+		flags |= 4
+	}
+	put(flags)
+	put(uint64(cfg.column))
+	put(math.Float64bits(cfg.minProb))
+	put(math.Float64bits(cfg.maxProb))
+	put(uint64(len(cfg.name)))
+	h.Write(buf)
+	h.Write([]byte(cfg.name))
+	buf = buf[:0]
+	put(uint64(len(probs)))
+	for _, p := range probs {
+		put(math.Float64bits(p))
+	}
+	h.Write(buf)
+	var key cacheKey
+	h.Sum(key[:0])
+	return key
+}
@@ -0,0 +1,147 @@
+package alias_sample
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCache(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		maxEntries := rapid.IntRange(1, 5).Draw(t, "max entries")
+		c := NewCache(maxEntries, 0)
+		sets := rapid.SliceOfN(rapid.SliceOfN(rapid.Float64Range(0, 3), 1, 4), 1, 8).Draw(t, "sets")
+		sets[0][0] = 1
+
+		/* Replay a sequence of requests against a model LRU. */
+		var model []int
+		for _, k := range rapid.SliceOfN(rapid.IntRange(0, len(sets)-1), 1, 50).Draw(t, "requests") {
+			probs := append([]float64{1}, sets[k]...)
+			s, err := c.Get(probs)
+			if err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+			if s.Len() != len(probs) {
+				t.Fatalf("got a sampler of %d for %d weights\n", s.Len(), len(probs))
+			}
+			again, _ := c.Get(probs)
+			if again != s {
+				t.Fatalf("second get built a new sampler\n")
+			}
+
+			/* Equal weight sets share an entry, so key the model on
+			 * the first set equal to this one.
+			 */
+			for j := range sets {
+				if slices.Equal(sets[j], sets[k]) {
+					k = j
+					break
+				}
+			}
+			for i, m := range model {
+				if m == k {
+					model = append(model[:i], model[i+1:]...)
+					break
+				}
+			}
+			model = append([]int{k}, model...)
+			if len(model) > maxEntries {
+				model = model[:maxEntries]
+			}
+			if c.Len() != len(model) {
+				t.Fatalf("cache holds %d, want %d\n", c.Len(), len(model))
+			}
+		}
+	})
+}
+
+func TestCacheKeys(t *testing.T) {
+	c := NewCache(0, 0)
+	probs := []float64{1, 2, 3}
+	a, _ := c.Get(probs, WithSeed(1))
+	b, _ := c.Get([]float64{1, 2, 3}, WithSeed(2))
+	if a != b {
+		t.Fatalf("seed changed the cache key\n")
+	}
+	if f, _ := c.Get(probs, WithFloat32()); f == a {
+		t.Fatalf("float32 storage shared a float64 table\n")
+	}
+	if f, _ := c.Get(probs, WithName("x")); f == a {
+		t.Fatalf("named sampler shared an unnamed table\n")
+	}
+	if hits, misses := c.Hits(); hits != 1 || misses != 3 {
+		t.Fatalf("got %d hits and %d misses, want 1 and 3\n", hits, misses)
+	}
+
+	if _, err := c.Get(nil); err == nil {
+		t.Fatalf("expected error for no weights\n")
+	}
+	if c.Len() != 3 {
+		t.Fatalf("failed build was cached\n")
+	}
+	c.Purge()
+	if c.Len() != 0 || c.Bytes() != 0 {
+		t.Fatalf("purge left %d entries of %d bytes\n", c.Len(), c.Bytes())
+	}
+}
+
+func TestCacheBytes(t *testing.T) {
+	weights := func(i int) []float64 {
+		probs := make([]float64, 100)
+		for j := range probs {
+			probs[j] = float64(j%7 + 1)
+		}
+		probs[i] = 100
+		return probs
+	}
+	s, _ := Init(weights(0))
+	size := s.Stats().TableBytes
+	if size == 0 {
+		t.Fatalf("table has no size\n")
+	}
+	c := NewCache(0, 2*size)
+	for i := range 5 {
+		c.Get(weights(i))
+		if c.Bytes() > 2*size {
+			t.Fatalf("cache holds %d bytes, limit %d\n", c.Bytes(), 2*size)
+		}
+	}
+	if c.Len() != 2 {
+		t.Fatalf("cache holds %d tables, want 2\n", c.Len())
+	}
+
+	/* One table over the limit is still kept. */
+	small := NewCache(0, 1)
+	small.Get([]float64{1, 2})
+	if small.Len() != 1 {
+		t.Fatalf("cache dropped its only entry\n")
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := NewCache(0, 0)
+	probs := make([]float64, 10_000)
+	for i := range probs {
+		probs[i] = float64(i + 1)
+	}
+	var wg sync.WaitGroup
+	res := make([]*AliasSampler, 8)
+	for i := range res {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i], _ = c.Get(probs)
+		}()
+	}
+	wg.Wait()
+	for _, s := range res {
+		if s != res[0] {
+			t.Fatalf("concurrent gets built different samplers\n")
+		}
+	}
+	if _, misses := c.Hits(); misses != 1 {
+		t.Fatalf("concurrent gets built %d times\n", misses)
+	}
+}
@@ -0,0 +1,227 @@
+// Package canary splits traffic between a stable release and a canary,
+// ramping the canary's share up on a schedule and rolling it back on
+// demand.
+package canary
+
+import (
+	"fmt"
+	r "math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Target is where Route sends a request.
+type Target int
+
+const (
+	Stable Target = iota
+	Canary
+)
+
+func (t Target) String() string {
+	if t == Canary {
+		return "canary"
+	}
+	return "stable"
+}
+
+// Step is one phase of a ramp: move the canary's share of traffic to
+// Canary, between 0 and 1, and hold it there for Hold before the next
+// step.  The last step's Hold is ignored, since its share stays in effect
+// once the ramp is done.
+type Step struct {
+	Canary float64
+	Hold   time.Duration
+}
+
+// Status describes what a Splitter is doing.
+type Status struct {
+	Canary   float64   // the canary's current share of traffic
+	Ramping  bool      // whether a ramp is in progress
+	Step     int       // the ramp step in effect, if Ramping
+	NextStep time.Time // when the next step starts, if Ramping
+	Previous float64   // the share Rollback would restore
+}
+
+// Splitter routes each request to Stable or Canary.  Route never takes a
+// lock: the split in effect is an immutable snapshot, replaced atomically
+// by Set, Ramp and Rollback, or when a ramp reaches its next step, which
+// the first Route after the step boundary notices.  All methods are safe
+// for concurrent use.
+type Splitter struct {
+	current atomic.Pointer[split]
+	rands   sync.Pool
+	now     func() time.Time
+
+	mu       sync.Mutex // serializes changes
+	steps    []Step
+	started  time.Time
+	previous float64
+}
+
+/* split is the snapshot Route reads.  until is when it stops being
+ * current: the start of the next ramp step, or the zero time if it holds
+ * indefinitely.
+ */
+type split struct {
+	canary float64
+	step   int
+	until  time.Time
+	s      *alias_sample.AliasSampler
+}
+
+// New returns a Splitter sending the given share of traffic to the canary.
+func New(canary float64) (*Splitter, error) {
+	if err := checkShare(canary); err != nil {
+		return nil, err
+	}
+	sp := &Splitter{now: time.Now, previous: canary}
+	sp.rands.New = func() any {
+		return r.New(r.NewSource(r.Int63()))
+	}
+	sp.current.Store(newSplit(canary, -1, time.Time{}))
+	return sp, nil
+}
+
+// Route picks a target for one request.
+func (sp *Splitter) Route() Target {
+	rng := sp.rands.Get().(*r.Rand)
+	t := sp.RouteFrom(rng)
+	sp.rands.Put(rng)
+	return t
+}
+
+// RouteFrom is Route using rng, which must not be used concurrently.
+func (sp *Splitter) RouteFrom(rng *r.Rand) Target {
+	return Target(sp.live().s.NextFrom(rng))
+}
+
+// Set stops any ramp and sends the given share of traffic to the canary.
+func (sp *Splitter) Set(canary float64) error {
+	if err := checkShare(canary); err != nil {
+		return err
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.previous = sp.liveLocked().canary
+	sp.steps = nil
+	sp.current.Store(newSplit(canary, -1, time.Time{}))
+	return nil
+}
+
+// Ramp starts moving traffic through steps, beginning with the first one
+// now, and replacing any ramp already in progress.
+func (sp *Splitter) Ramp(steps []Step) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("canary: ramp has no steps")
+	}
+	for i, st := range steps {
+		if err := checkShare(st.Canary); err != nil {
+			return fmt.Errorf("canary: step %d: %v", i, err)
+		}
+		if st.Hold <= 0 && i < len(steps)-1 {
+			return fmt.Errorf("canary: step %d has no hold time", i)
+		}
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.previous = sp.liveLocked().canary
+	sp.steps = append([]Step(nil), steps...)
+	sp.started = sp.now()
+	sp.advance(sp.started)
+	return nil
+}
+
+// Rollback stops any ramp and restores the canary share in effect before
+// the last Set or Ramp, returning it.  Rolling back twice restores the
+// share rolled back from.
+func (sp *Splitter) Rollback() float64 {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	restore := sp.previous
+	sp.previous = sp.liveLocked().canary
+	sp.steps = nil
+	sp.current.Store(newSplit(restore, -1, time.Time{}))
+	return restore
+}
+
+// Canary returns the canary's current share of traffic.
+func (sp *Splitter) Canary() float64 {
+	return sp.live().canary
+}
+
+// Status reports the live split and the progress of any ramp.
+func (sp *Splitter) Status() Status {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	cur := sp.liveLocked()
+	return Status{
+		Canary:   cur.canary,
+		Ramping:  cur.step >= 0,
+		Step:     max(cur.step, 0),
+		NextStep: cur.until,
+		Previous: sp.previous,
+	}
+}
+
+/* live returns the current split, first moving a ramp on to the step it
+ * should be at by now.  If another goroutine is changing the split, it
+ * returns the old one rather than wait, since the change is about to
+ * replace it anyway.
+ */
+func (sp *Splitter) live() *split {
+	cur := sp.current.Load()
+	if cur.until.IsZero() || sp.now().Before(cur.until) {
+		return cur
+	}
+	if !sp.mu.TryLock() {
+		return cur
+	}
+	defer sp.mu.Unlock()
+	return sp.liveLocked()
+}
+
+/* liveLocked is live for callers holding sp.mu. */
+func (sp *Splitter) liveLocked() *split {
+	cur := sp.current.Load()
+	if now := sp.now(); !cur.until.IsZero() && !now.Before(cur.until) {
+		sp.advance(now)
+	}
+	return sp.current.Load()
+}
+
+/* advance makes the ramp step in effect at now current.  sp.mu must be
+ * held.
+ */
+func (sp *Splitter) advance(now time.Time) {
+	end := sp.started
+	for i, st := range sp.steps {
+		if i == len(sp.steps)-1 {
+			/* The ramp is over; its last share holds from here on. */
+			sp.steps = nil
+			sp.current.Store(newSplit(st.Canary, -1, time.Time{}))
+			return
+		}
+		end = end.Add(st.Hold)
+		if now.Before(end) {
+			sp.current.Store(newSplit(st.Canary, i, end))
+			return
+		}
+	}
+}
+
+func newSplit(canary float64, step int, until time.Time) *split {
+	/* Two valid shares always build. */
+	s, _ := alias_sample.Init([]float64{1 - canary, canary})
+	return &split{canary: canary, step: step, until: until, s: s}
+}
+
+func checkShare(canary float64) error {
+	if !(canary >= 0 && canary <= 1) {
+		return fmt.Errorf("canary: share %v is not between 0 and 1", canary)
+	}
+	return nil
+}
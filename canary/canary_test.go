@@ -0,0 +1,140 @@
+package canary
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+)
+
+/* clock is a settable time source for driving ramps in tests. */
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRoute(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		share := rapid.Float64Range(0, 1).Draw(t, "share")
+		sp, err := New(share)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		rng := r.New(r.NewSource(rapid.Int64().Draw(t, "seed")))
+		sz := 100_000
+		canaries := 0
+		for range sz {
+			if sp.RouteFrom(rng) == Canary {
+				canaries++
+			}
+		}
+		if got := float64(canaries) / float64(sz); math.Abs(got-share) > 0.01 {
+			t.Fatalf("sent %v to the canary, want %v\n", got, share)
+		}
+	})
+}
+
+func TestRamp(t *testing.T) {
+	c := &clock{now: time.Unix(1000, 0)}
+	sp, _ := New(0)
+	sp.now = c.Now
+	if err := sp.Ramp([]Step{{0.01, time.Minute}, {0.1, 10 * time.Minute}, {0.5, time.Hour}, {1, 0}}); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	for _, want := range []struct {
+		after  time.Duration
+		canary float64
+		step   int
+	}{
+		{0, 0.01, 0},
+		{59 * time.Second, 0.01, 0},
+		{time.Second, 0.1, 1},
+		{10 * time.Minute, 0.5, 2},
+		{2 * time.Hour, 1, -1},
+	} {
+		c.Advance(want.after)
+		if got := sp.Canary(); got != want.canary {
+			t.Fatalf("after %v more: canary share %v, want %v\n", want.after, got, want.canary)
+		}
+		st := sp.Status()
+		if st.Ramping != (want.step >= 0) || (st.Ramping && st.Step != want.step) || st.Previous != 0 {
+			t.Fatalf("after %v more: got status %+v\n", want.after, st)
+		}
+	}
+	if st := sp.Status(); !st.NextStep.IsZero() {
+		t.Fatalf("finished ramp has a next step: %+v\n", st)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	c := &clock{now: time.Unix(1000, 0)}
+	sp, _ := New(0.05)
+	sp.now = c.Now
+	sp.Ramp([]Step{{0.2, time.Minute}, {0.6, time.Minute}})
+	c.Advance(90 * time.Second)
+
+	/* Rollback goes straight back to the share before the ramp, and
+	 * the ramp doesn't resume.
+	 */
+	if got := sp.Rollback(); got != 0.05 {
+		t.Fatalf("rolled back to %v\n", got)
+	}
+	c.Advance(time.Hour)
+	if st := sp.Status(); st.Canary != 0.05 || st.Ramping || st.Previous != 0.6 {
+		t.Fatalf("after rollback: got status %+v\n", st)
+	}
+	sp.Rollback()
+	if got := sp.Canary(); got != 0.6 {
+		t.Fatalf("second rollback restored %v\n", got)
+	}
+
+	sp.Set(0)
+	if got := sp.Rollback(); got != 0.6 {
+		t.Fatalf("rollback after Set restored %v\n", got)
+	}
+
+	for _, bad := range [][]Step{nil, {{1.5, time.Minute}}, {{0.1, 0}, {0.2, 0}}} {
+		if err := sp.Ramp(bad); err == nil {
+			t.Errorf("accepted ramp %v\n", bad)
+		}
+	}
+	if _, err := New(-0.1); err == nil {
+		t.Fatalf("negative share was accepted\n")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	sp, _ := New(0)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10_000 {
+				sp.Route()
+			}
+		}()
+	}
+	for range 100 {
+		sp.Ramp([]Step{{0.1, time.Microsecond}, {0.2, time.Microsecond}, {0.3, 0}})
+		sp.Status()
+		sp.Rollback()
+	}
+	wg.Wait()
+}
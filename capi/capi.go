@@ -0,0 +1,132 @@
+//go:build cgo
+
+// Command capi builds alias_sample as a C shared library, so that programs
+// in other languages can use exactly this implementation, with the same
+// draws for the same seed:
+//
+//	go build -buildmode=c-shared -o libaliassample.so ./capi
+//
+// which also writes libaliassample.h.  The API is:
+//
+//	uintptr_t alias_create(const double *weights, size_t n, int64_t seed);
+//	int64_t   alias_next(uintptr_t sampler);
+//	void      alias_next_n(uintptr_t sampler, int64_t *dst, size_t n);
+//	void      alias_destroy(uintptr_t sampler);
+//
+// alias_create copies the weights and returns 0 if they are invalid.  A
+// sampler is not safe for concurrent use; give each thread its own.  Every
+// sampler must be passed to alias_destroy once it is no longer needed.
+// Given 0 or a destroyed sampler, alias_next returns -1, alias_next_n fills
+// dst with -1, and alias_destroy does nothing.
+package main
+
+/*
+#include <stddef.h>
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"runtime/cgo"
+	"unsafe"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func main() {}
+
+//export alias_create
+func alias_create(weights *C.double, n C.size_t, seed C.int64_t) C.uintptr_t {
+	if weights == nil || n == 0 {
+		return 0
+	}
+	probs := unsafe.Slice((*float64)(unsafe.Pointer(weights)), int(n))
+	h, err := create(probs, int64(seed))
+	if err != nil {
+		return 0
+	}
+	return C.uintptr_t(h)
+}
+
+//export alias_next
+func alias_next(sampler C.uintptr_t) C.int64_t {
+	return C.int64_t(next(cgo.Handle(sampler)))
+}
+
+//export alias_next_n
+func alias_next_n(sampler C.uintptr_t, dst *C.int64_t, n C.size_t) {
+	if dst == nil || n == 0 {
+		return
+	}
+	nextN(cgo.Handle(sampler), unsafe.Slice((*int64)(unsafe.Pointer(dst)), int(n)))
+}
+
+//export alias_destroy
+func alias_destroy(sampler C.uintptr_t) {
+	destroy(cgo.Handle(sampler))
+}
+
+/* The exported functions are thin shims over these, which the tests can
+ * call without cgo.
+ */
+
+func create(probs []float64, seed int64) (cgo.Handle, error) {
+	if err := alias_sample.CheckWeights(probs); err != nil {
+		return 0, err
+	}
+	/* Init copies probs, so nothing keeps pointing into C memory. */
+	s, err := alias_sample.InitWithSeed(probs, seed)
+	if err != nil {
+		return 0, err
+	}
+	return cgo.NewHandle(s), nil
+}
+
+/* lookup returns the sampler behind h, or nil if h is 0 or has been
+ * deleted, for which Value panics.
+ */
+func lookup(h cgo.Handle) (s *alias_sample.AliasSampler) {
+	if h == 0 {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			s = nil
+		}
+	}()
+	s, _ = h.Value().(*alias_sample.AliasSampler)
+	return s
+}
+
+func next(h cgo.Handle) int64 {
+	s := lookup(h)
+	if s == nil {
+		return -1
+	}
+	return int64(s.Next())
+}
+
+func nextN(h cgo.Handle, dst []int64) {
+	s := lookup(h)
+	if s == nil {
+		for i := range dst {
+			dst[i] = -1
+		}
+		return
+	}
+	buf := make([]int, min(len(dst), 4096))
+	for len(dst) > 0 {
+		chunk := buf[:min(len(buf), len(dst))]
+		s.NextN(chunk)
+		for i, v := range chunk {
+			dst[i] = int64(v)
+		}
+		dst = dst[len(chunk):]
+	}
+}
+
+func destroy(h cgo.Handle) {
+	if lookup(h) != nil {
+		h.Delete()
+	}
+}
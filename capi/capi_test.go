@@ -0,0 +1,70 @@
+//go:build cgo
+
+package main
+
+import (
+	"math"
+	"runtime/cgo"
+	"slices"
+	"testing"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func TestCAPI(t *testing.T) {
+	weights := []float64{1, 0, 3}
+	h, err := create(weights, 7)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	defer destroy(h)
+
+	/* Draws match a sampler built in Go with the same seed. */
+	want, _ := alias_sample.InitWithSeed(weights, 7)
+	for range 100 {
+		if got, w := next(h), int64(want.Next()); got != w {
+			t.Fatalf("drew %d, Go drew %d\n", got, w)
+		}
+	}
+	got := make([]int64, 10_000)
+	nextN(h, got)
+	wantN := make([]int, 10_000)
+	want.NextN(wantN)
+	for i := range got {
+		if got[i] != int64(wantN[i]) {
+			t.Fatalf("batch draw %d: got %d, Go drew %d\n", i, got[i], wantN[i])
+		}
+	}
+	if slices.Contains(got, 1) {
+		t.Fatalf("drew an index with zero weight\n")
+	}
+
+	if _, err := create(nil, 1); err == nil {
+		t.Fatalf("empty weights were accepted\n")
+	}
+	for _, bad := range [][]float64{{0, 0}, {1, math.NaN()}, {1, math.Inf(1)}, {-1, 2}} {
+		if _, err := create(bad, 1); err == nil {
+			t.Fatalf("weights %v were accepted\n", bad)
+		}
+	}
+}
+
+func TestCAPIBadHandle(t *testing.T) {
+	h, err := create([]float64{1, 2}, 7)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	destroy(h)
+	destroy(h)
+
+	for _, bad := range []cgo.Handle{0, h} {
+		if got := next(bad); got != -1 {
+			t.Fatalf("handle %d: drew %d, want -1\n", bad, got)
+		}
+		dst := []int64{3, 3, 3}
+		nextN(bad, dst)
+		if !slices.Equal(dst, []int64{-1, -1, -1}) {
+			t.Fatalf("handle %d: batch drew %v, want all -1\n", bad, dst)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// CDFSampler draws by binary search over the running sum of the weights.
+// Draws take O(log n) time, it needs only one float64 per entry, and as a
+// side effect it can answer quantile queries.  Changing a weight costs
+// O(n) with no allocation, against a full rebuild for AliasSampler, which
+// makes it the better fit for small-to-medium n with frequent updates.
+type CDFSampler struct {
+	seed int64
+	rand *r.Rand
+
+	cumulative []float64
+}
+
+// InitCDF builds a CDFSampler over probs, which need not be normalized
+// but must be finite and non-negative, and not all zero.  Of the options
+// only WithSeed applies.
+func InitCDF(probs []float64, opts ...Option) (*CDFSampler, error) {
+	cfg := newConfig(opts)
+	if err := checkWeights(probs); err != nil {
+		return nil, err
+	}
+
+	cumulative := make([]float64, len(probs))
+	var tot float64
+	for i, p := range probs {
+		tot += p
+		cumulative[i] = tot
+	}
+
+	return &CDFSampler{
+		seed:       cfg.seed,
+		rand:       cfg.newRand(),
+		cumulative: cumulative,
+	}, nil
+}
+
+// Next draws an index using the sampler's own random source.
+func (s *CDFSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+// NextFrom draws an index using rng.  It may be called concurrently as
+// long as each goroutine uses its own rng and nothing calls Update.
+func (s *CDFSampler) NextFrom(rng *r.Rand) int {
+	return s.search(rng.Float64() * s.total())
+}
+
+// Len returns the number of indices the sampler draws from.
+func (s *CDFSampler) Len() int {
+	return len(s.cumulative)
+}
+
+// Quantile returns the smallest index i such that CDF(i) >= q.  q is
+// clamped to [0, 1].
+func (s *CDFSampler) Quantile(q float64) int {
+	q = min(max(q, 0), 1)
+	target := q * s.total()
+
+	/* Find the first entry that reaches the target, skipping over any
+	 * leading zero weights when q is 0.
+	 */
+	lo, hi := 0, len(s.cumulative)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if s.cumulative[mid] < target || s.cumulative[mid] == 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return min(lo, s.lastDrawable())
+}
+
+// CDF returns the probability of drawing an index <= i.
+func (s *CDFSampler) CDF(i int) float64 {
+	if i < 0 {
+		return 0
+	}
+	if i >= len(s.cumulative) {
+		return 1
+	}
+	return s.cumulative[i] / s.total()
+}
+
+// Update sets the weight of index i to w.  It takes time proportional to
+// the number of indices after i.
+func (s *CDFSampler) Update(i int, w float64) error {
+	if i < 0 || i >= len(s.cumulative) {
+		return &SampleError{"index out of range"}
+	}
+	if !ValidWeight(w) {
+		return &SampleError{"weights must be finite and non-negative"}
+	}
+
+	delta := w - s.weight(i)
+	for j := i; j < len(s.cumulative); j++ {
+		s.cumulative[j] += delta
+	}
+	return nil
+}
+
+func (s *CDFSampler) total() float64 {
+	return s.cumulative[len(s.cumulative)-1]
+}
+
+func (s *CDFSampler) weight(i int) float64 {
+	if i == 0 {
+		return s.cumulative[0]
+	}
+	return s.cumulative[i] - s.cumulative[i-1]
+}
+
+/* search returns the first index whose running sum exceeds u. */
+func (s *CDFSampler) search(u float64) int {
+	lo, hi := 0, len(s.cumulative)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if s.cumulative[mid] <= u {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	/* Rounding can put u at or past the total; it belongs to the last
+	 * index that can be drawn at all.
+	 */
+	if lo == len(s.cumulative) {
+		return s.lastDrawable()
+	}
+	return lo
+}
+
+/* lastDrawable returns the last index with nonzero weight. */
+func (s *CDFSampler) lastDrawable() int {
+	tot := s.total()
+	lo, hi := 0, len(s.cumulative)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if s.cumulative[mid] < tot {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return min(lo, len(s.cumulative)-1)
+}
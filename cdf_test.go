@@ -0,0 +1,69 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCDFQuantile(t *testing.T) {
+	s, err := InitCDF([]float64{0, 1, 0, 2, 1, 0})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	cases := []struct {
+		q    float64
+		want int
+	}{
+		{-1, 1}, {0, 1}, {0.25, 1}, {0.26, 3}, {0.75, 3}, {0.76, 4}, {1, 4}, {2, 4},
+	}
+	for _, c := range cases {
+		if got := s.Quantile(c.q); got != c.want {
+			t.Errorf("Quantile(%g) = %d, want %d\n", c.q, got, c.want)
+		}
+	}
+	if got := s.CDF(3); got != 0.75 {
+		t.Errorf("CDF(3) = %g, want 0.75\n", got)
+	}
+}
+
+func TestCDFUpdate(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 50).Draw(t, "probs")
+		s, err := InitCDF(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		i := rapid.IntRange(0, len(probs)-1).Draw(t, "i")
+		w := rapid.Float64Range(0.001, 5.0).Draw(t, "w")
+		if err := s.Update(i, w); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		probs[i] = w
+
+		fresh, _ := InitCDF(probs)
+		for j := range probs {
+			if math.Abs(s.CDF(j)-fresh.CDF(j)) > 1e-9 {
+				t.Fatalf("CDF(%d) = %g after update, want %g\n", j, s.CDF(j), fresh.CDF(j))
+			}
+		}
+		for _, w := range []float64{-1, math.NaN(), math.Inf(1)} {
+			if s.Update(0, w) == nil {
+				t.Fatalf("update to %g was accepted", w)
+			}
+		}
+		if s.Update(len(probs), 1) == nil {
+			t.Fatalf("update out of range was accepted")
+		}
+	})
+}
+
+func TestInitCDFInvalid(t *testing.T) {
+	for _, probs := range [][]float64{nil, {0, 0}, {1, -5, 10}, {1, math.NaN()}, {1, math.Inf(1)}} {
+		if _, err := InitCDF(probs); err == nil {
+			t.Errorf("%v was accepted\n", probs)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// WithMinProb guarantees every index a probability of at least p, for
+// example as an exploration floor.  Indices whose normalized probability
+// falls below p are raised to it and the rest are scaled down to make up
+// the difference, keeping their proportions.  This includes indices with
+// zero weight.  p must be in [0, 1/n].
+func WithMinProb(p float64) Option {
+	return func(c *config) {
+		c.minProb = p
+	}
+}
+
+// WithMaxProb caps every index's probability at p.  Indices above p are
+// lowered to it and the rest are scaled up to absorb the excess, keeping
+// their proportions; indices with zero weight stay at zero (or at the
+// WithMinProb floor).  p must be in [1/n, 1].
+func WithMaxProb(p float64) Option {
+	return func(c *config) {
+		c.maxProb = p
+	}
+}
+
+/* clampProbs rewrites probs as clamp(probs[i]*s, lo, hi) for the scale s
+ * that makes the result sum to 1.  The sum is nondecreasing in s, so s is
+ * found by bisection.
+ */
+func clampProbs(probs []float64, lo, hi float64) error {
+	n := float64(len(probs))
+	if !(lo >= 0 && lo <= hi && hi <= 1) {
+		return &SampleError{"probability bounds must satisfy 0 <= min <= max <= 1"}
+	}
+	if lo*n > 1 || hi*n < 1 {
+		return &SampleError{"probability bounds are infeasible for this many indices"}
+	}
+
+	var tot float64
+	zeros := 0
+	for _, p := range probs {
+		tot += p
+		if p == 0 {
+			zeros++
+		}
+	}
+	if float64(len(probs)-zeros)*hi+float64(zeros)*lo < 1 {
+		return &SampleError{"too few nonzero weights to reach a total of 1 under the maximum"}
+	}
+
+	sum := func(s float64) float64 {
+		var res float64
+		for _, p := range probs {
+			res += min(max(p/tot*s, lo), hi)
+		}
+		return res
+	}
+
+	/* At s = 0 everything sits on the floor, which sums to at most 1,
+	 * and once every nonzero weight is pushed to the cap the sum is (as
+	 * checked above) at least 1.  Bracket the crossing to within a factor
+	 * of two, starting from no scaling at all, then bisect.
+	 */
+	sLo, sHi := 0.5, 1.0
+	if sum(1) < 1 {
+		sLo, sHi = 1, 2
+		for sum(sHi) < 1 {
+			if sHi == math.MaxFloat64 {
+				break
+			}
+			sLo, sHi = sHi, min(sHi*2, math.MaxFloat64)
+		}
+	} else {
+		for sLo > 0 && sum(sLo) >= 1 {
+			sLo, sHi = sLo/2, sLo
+		}
+	}
+	for range 200 {
+		mid := sLo + (sHi-sLo)/2
+		if mid <= sLo || mid >= sHi {
+			break
+		}
+		if sum(mid) < 1 {
+			sLo = mid
+		} else {
+			sHi = mid
+		}
+	}
+
+	/* The bisection settles which entries sit on the floor and which on
+	 * the cap.  Scale the rest by the mass they have left rather than by
+	 * s, which may have been too large to represent for tiny weights.
+	 */
+	rest, restWeight := 1.0, 0.0
+	for _, p := range probs {
+		switch q := p / tot * sHi; {
+		case q <= lo:
+			rest -= lo
+		case q >= hi:
+			rest -= hi
+		default:
+			restWeight += p
+		}
+	}
+	var res float64
+	for i, p := range probs {
+		switch q := p / tot * sHi; {
+		case q <= lo:
+			probs[i] = lo
+		case q >= hi:
+			probs[i] = hi
+		default:
+			probs[i] = min(max(rest*(p/restWeight), lo), hi)
+		}
+		res += probs[i]
+	}
+
+	/* Absorb what little rounding error is left by renormalizing. */
+	for i := range probs {
+		probs[i] /= res
+	}
+	return nil
+}
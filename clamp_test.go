@@ -0,0 +1,60 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestClampProbs(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 2, 50).Draw(t, "probs")
+		probs[0] += 0.001
+		n := float64(len(probs))
+		lo := rapid.Float64Range(0, 1/n).Draw(t, "lo")
+		hi := rapid.Float64Range(1/n, 1).Draw(t, "hi")
+
+		as, err := Init(probs, WithMinProb(lo), WithMaxProb(hi))
+		if err != nil {
+			/* The only legitimate failure is too few nonzero weights to
+			 * fill up to the cap.
+			 */
+			nonzero := 0
+			for _, p := range probs {
+				if p > 0 {
+					nonzero++
+				}
+			}
+			if float64(nonzero)*hi+(n-float64(nonzero))*lo >= 1 {
+				t.Fatalf("got err %v\n", err)
+			}
+			return
+		}
+
+		/* Every probability respects the bounds, and the unclamped ones
+		 * keep their proportions to each other.
+		 */
+		got := tableProbs(as)
+		ratio := math.NaN()
+		for i, p := range got {
+			if p < lo-1e-9 || p > hi+1e-9 {
+				t.Fatalf("index %d: %g outside [%g, %g]\n", i, p, lo, hi)
+			}
+			if p > lo+1e-9 && p < hi-1e-9 {
+				if math.IsNaN(ratio) {
+					ratio = p / probs[i]
+				} else if math.Abs(p/probs[i]-ratio) > 1e-6*ratio {
+					t.Fatalf("index %d: scaled by %g, others by %g\n", i, p/probs[i], ratio)
+				}
+			}
+		}
+	})
+
+	if _, err := Init([]float64{1, 2, 3}, WithMinProb(0.5)); err == nil {
+		t.Errorf("infeasible floor was accepted\n")
+	}
+	if _, err := Init([]float64{1, 0, 0}, WithMaxProb(0.5)); err == nil {
+		t.Errorf("unreachable cap was accepted\n")
+	}
+}
@@ -0,0 +1,59 @@
+package alias_sample
+
+import (
+	"sync/atomic"
+)
+
+// Clone returns an independent copy of the sampler, for letting a sampler
+// captured at one point go on in two directions without the weights it was
+// built from.  math/rand can't copy a source's state, so Clone forks it
+// instead: the clone is seeded from the next value of s's source, which
+// makes its stream differ from s's but depend only on s's seed and the
+// draws made before the clone.  That one value advances s's stream too.
+//
+// The clone starts with s's draw counts, under WithTracking, and keeps its
+// own from then on.  The table itself never changes, so the two share it.
+// Clone must not race with other calls on s.
+func (s *AliasSampler) Clone() *AliasSampler {
+	return s.CloneWithSeed(s.source().Int63())
+}
+
+// CloneWithSeed is like Clone, but seeds the copy's source with seed
+// rather than forking it from s's, whose stream it leaves alone.
+func (s *AliasSampler) CloneWithSeed(seed int64) *AliasSampler {
+	c := s.copyTable()
+	c.seed = seed
+	c.cache = s.cache
+	c.metrics = s.metrics
+	if s.counts != nil {
+		c.counts = make([]atomic.Uint64, len(s.counts))
+		for i := range s.counts {
+			c.counts[i].Store(s.counts[i].Load())
+		}
+	}
+	return c
+}
+
+/* copyTable returns a sampler with s's table and nothing else of its state:
+ * no random source, caches or draw counts.  The columns are shared, apart
+ * from tiny tables, which live inside s and so are copied.
+ */
+func (s *AliasSampler) copyTable() *AliasSampler {
+	t := &AliasSampler{
+		seed:          s.seed,
+		name:          s.name,
+		n:             s.n,
+		mode:          s.mode,
+		only:          s.only,
+		probability:   s.probability,
+		probability32: s.probability32,
+		probability16: s.probability16,
+		alias:         s.alias,
+		alias32:       s.alias32,
+		parent:        s.parent,
+		stats:         s.stats,
+		cache:         &tableCache{},
+	}
+	t.inline()
+	return t
+}
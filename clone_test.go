@@ -0,0 +1,57 @@
+package alias_sample
+
+import (
+	"testing"
+
+	"github.com/evanmcc/alias_sample/testutil"
+	"pgregory.net/rapid"
+)
+
+func TestClone(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := testutil.Weights().Draw(t, "probs")
+		seed := rapid.Int64().Draw(t, "seed")
+		a := MustInitWithSeed(probs, seed, WithTracking())
+		b := MustInitWithSeed(probs, seed, WithTracking())
+		before := rapid.IntRange(0, 20).Draw(t, "before")
+		for range before {
+			a.Next()
+			b.Next()
+		}
+
+		/* Forking is reproducible, for the clones and the originals. */
+		ca, cb := a.Clone(), b.Clone()
+		for range 50 {
+			if x, y := ca.Next(), cb.Next(); x != y {
+				t.Fatalf("clones drew %d and %d\n", x, y)
+			}
+			if x, y := a.Next(), b.Next(); x != y {
+				t.Fatalf("originals drew %d and %d after cloning\n", x, y)
+			}
+		}
+		if ca.Draws() != uint64(before+50) || a.Draws() != uint64(before+50) {
+			t.Fatalf("clone counted %d draws and original %d, want %d\n",
+				ca.Draws(), a.Draws(), before+50)
+		}
+
+		/* Re-seeded, a clone draws exactly what a fresh sampler does. */
+		fresh := MustInitWithSeed(probs, seed+1)
+		c := a.CloneWithSeed(seed + 1)
+		for range 50 {
+			if x, y := c.Next(), fresh.Next(); x != y {
+				t.Fatalf("re-seeded clone drew %d, want %d\n", x, y)
+			}
+		}
+		for i := range probs {
+			if c.Prob(i) != a.Prob(i) {
+				t.Fatalf("clone prob %d is %v, want %v\n", i, c.Prob(i), a.Prob(i))
+			}
+		}
+		if c.tiny && &c.tinyProb[0] == &a.tinyProb[0] {
+			t.Fatalf("tiny clone shares its table with the original\n")
+		}
+		if c.tiny && &c.probability[0] != &c.tinyProb[0] {
+			t.Fatalf("tiny clone does not point at its own table\n")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+/* backends lists what bench can measure, in the order it reports them. */
+var backends = []struct {
+	name  string
+	build func([]float64) (alias_sample.Sampler, error)
+}{
+	{"alias", func(w []float64) (alias_sample.Sampler, error) { return alias_sample.Init(w) }},
+	{"cdf", func(w []float64) (alias_sample.Sampler, error) { return alias_sample.InitCDF(w) }},
+	{"guide", func(w []float64) (alias_sample.Sampler, error) { return alias_sample.InitGuide(w) }},
+	{"interpolation", func(w []float64) (alias_sample.Sampler, error) { return alias_sample.InitInterpolation(w) }},
+	{"rejection", func(w []float64) (alias_sample.Sampler, error) { return alias_sample.InitRejection(w) }},
+	{"linear", func(w []float64) (alias_sample.Sampler, error) { return alias_sample.InitLinear(w) }},
+}
+
+/* benchDraws is how many draws bench makes between looks at the clock. */
+const benchDraws = 1024
+
+func bench(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("alias-sample bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	duration := fs.Duration("time", time.Second, "how long to spend drawing from each backend")
+	only := fs.String("backends", "", "comma-separated backends to measure (default: all)")
+	format := fs.String("format", "auto", "input format: auto, lines, csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	weights, _, err := load(fs, stdin, *format)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, b := range backends {
+		known[b.name] = true
+	}
+	selected := map[string]bool{}
+	for _, name := range strings.Split(*only, ",") {
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return fmt.Errorf("unknown backend %q", name)
+		}
+		selected[name] = true
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "backend\tbuild\tallocated\tdraws/sec\t\n")
+	for _, b := range backends {
+		if len(selected) > 0 && !selected[b.name] {
+			continue
+		}
+
+		/* Allocation is measured across the build alone, after a GC so
+		 * that earlier backends' garbage doesn't count.
+		 */
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		s, err := b.build(weights)
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		if err != nil {
+			return fmt.Errorf("%s: %v", b.name, err)
+		}
+
+		draws := 0
+		start = time.Now()
+		for time.Since(start) < *duration {
+			for range benchDraws {
+				s.Next()
+			}
+			draws += benchDraws
+		}
+		rate := float64(draws) / time.Since(start).Seconds()
+
+		fmt.Fprintf(tw, "%s\t%v\t%s\t%.3g\t\n", b.name, elapsed, bytesString(after.TotalAlloc-before.TotalAlloc), rate)
+	}
+	return tw.Flush()
+}
+
+func bytesString(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
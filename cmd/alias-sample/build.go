@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func build(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("alias-sample build", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	seed := fs.Int64("seed", 0, "seed stored with the table (default: a random one)")
+	column := fs.String("column", "float64", "probability column storage: float64, float32 or fixed16")
+	output := fs.String("o", "", "file to write the table to (default: standard output)")
+	format := fs.String("format", "auto", "input format: auto, lines, csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	weights, _, err := load(fs, stdin, *format)
+	if err != nil {
+		return err
+	}
+
+	var opts []alias_sample.Option
+	if flagSet(fs, "seed") {
+		opts = append(opts, alias_sample.WithSeed(*seed))
+	}
+	switch *column {
+	case "float64":
+	case "float32":
+		opts = append(opts, alias_sample.WithFloat32())
+	case "fixed16":
+		opts = append(opts, alias_sample.WithFixed16())
+	default:
+		return fmt.Errorf("unknown column storage %q", *column)
+	}
+
+	s, err := alias_sample.Init(weights, opts...)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = s.WriteTo(stdout)
+		return err
+	}
+	f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := s.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
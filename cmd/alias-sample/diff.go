@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+/* tableMagic starts every table the build subcommand writes. */
+const tableMagic = "ALIAS"
+
+func diff(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("alias-sample diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	threshold := fs.Float64("min", 0, "smallest probability change to list")
+	format := fs.String("format", "auto", "input format of weights files: auto, lines, csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("diff takes two files: the old and new distributions")
+	}
+	before, beforeLabels, err := loadTable(fs.Arg(0), *format)
+	if err != nil {
+		return err
+	}
+	after, afterLabels, err := loadTable(fs.Arg(1), *format)
+	if err != nil {
+		return err
+	}
+	d, err := alias_sample.Compare(before, after, beforeLabels, afterLabels)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "label\tbefore\tafter\tdelta\n")
+	for _, c := range d.Changes {
+		if math.Abs(c.Delta()) >= *threshold {
+			fmt.Fprintf(tw, "%s\t%.6g\t%.6g\t%+.6g\n", c.Label, c.Before, c.After, c.Delta())
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "total variation: %.6g\n", d.TotalVariation)
+	if len(d.Added) > 0 {
+		fmt.Fprintf(stdout, "added: %s\n", strings.Join(d.Added, ", "))
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(stdout, "removed: %s\n", strings.Join(d.Removed, ", "))
+	}
+	return nil
+}
+
+/* loadTable reads either a table written by build or a weights file, with
+ * its labels if it has any.
+ */
+func loadTable(name, format string) (*alias_sample.AliasSampler, []string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.HasPrefix(data, []byte(tableMagic)) {
+		s, err := alias_sample.Load(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return s, nil, nil
+	}
+	weights, labels, err := readWeights(bytes.NewReader(data), format, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := alias_sample.Init(weights)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return s, labels, nil
+}
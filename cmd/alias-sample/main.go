@@ -0,0 +1,151 @@
+// Command alias-sample draws weighted samples from the command line.
+//
+// Usage:
+//
+//	alias-sample [-n draws] [-seed seed] [-labels] [-counts] [-format f] [file]
+//	alias-sample bench [-time d] [-backends list] [-format f] [file]
+//	alias-sample build [-seed seed] [-column c] [-o output] [-format f] [file]
+//	alias-sample diff [-min delta] [-format f] old new
+//
+// It reads weights from file, or from standard input, as whitespace
+// separated numbers, CSV (weight or label,weight rows), or JSON (an array
+// of weights or an object of label: weight), and prints one draw per line:
+// the index, or with -labels, the label.  With -counts it prints each
+// index or label with the number of times it was drawn instead.  Weights
+// must be finite and non-negative, and not all zero.
+//
+// The bench subcommand builds each backend from the weights and reports
+// how long the build took, how much it allocated, and how fast it draws,
+// to help pick one for a particular distribution.
+//
+// The build subcommand builds the table and writes it in the binary form
+// alias_sample.Load reads, so that a service can embed the table with
+// go:embed instead of building it at startup:
+//
+//	//go:embed weights.alias
+//	var table []byte
+//
+//	s, err := alias_sample.Load(table)
+//
+// The diff subcommand compares two distributions, each either a weights
+// file or a table from build, for reviewing a change of weights before it
+// is deployed.  It lists each category whose probability changed, the
+// total variation distance between the two, and any categories added or
+// removed.  Categories are matched by label when both files have labels,
+// and by index otherwise.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "alias-sample:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "bench":
+			return bench(args[1:], stdin, stdout, stderr)
+		case "build":
+			return build(args[1:], stdin, stdout, stderr)
+		case "diff":
+			return diff(args[1:], stdin, stdout, stderr)
+		}
+	}
+	return sample(args, stdin, stdout, stderr)
+}
+
+func sample(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("alias-sample", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	n := fs.Int("n", 1, "number of draws")
+	seed := fs.Int64("seed", 0, "random seed (default: a random one)")
+	labels := fs.Bool("labels", false, "print labels instead of indices")
+	counts := fs.Bool("counts", false, "print how often each index was drawn instead of the draws")
+	format := fs.String("format", "auto", "input format: auto, lines, csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *n < 0 {
+		return fmt.Errorf("-n must not be negative")
+	}
+
+	weights, names, err := load(fs, stdin, *format)
+	if err != nil {
+		return err
+	}
+	if *labels && names == nil {
+		return fmt.Errorf("-labels needs labelled input (CSV label,weight rows or a JSON object)")
+	}
+
+	var opts []alias_sample.Option
+	if flagSet(fs, "seed") {
+		opts = append(opts, alias_sample.WithSeed(*seed))
+	}
+	s, err := alias_sample.Init(weights, opts...)
+	if err != nil {
+		return err
+	}
+
+	name := func(i int) string {
+		if *labels {
+			return names[i]
+		}
+		return fmt.Sprint(i)
+	}
+
+	out := bufio.NewWriter(stdout)
+	if *counts {
+		tally := make([]int, len(weights))
+		for range *n {
+			tally[s.Next()]++
+		}
+		for i, c := range tally {
+			if weights[i] > 0 {
+				fmt.Fprintf(out, "%s\t%d\n", name(i), c)
+			}
+		}
+	} else {
+		for range *n {
+			fmt.Fprintln(out, name(s.Next()))
+		}
+	}
+	return out.Flush()
+}
+
+/* load reads the weights from the file named by the only argument, or
+ * from stdin if there isn't one.
+ */
+func load(fs *flag.FlagSet, stdin io.Reader, format string) ([]float64, []string, error) {
+	switch fs.NArg() {
+	case 0:
+		return readWeights(stdin, format, "")
+	case 1:
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		return readWeights(f, format, fs.Arg(0))
+	}
+	return nil, nil, fmt.Errorf("too many arguments")
+}
+
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func runCmd(t *testing.T, stdin string, args ...string) (string, error) {
+	var out strings.Builder
+	err := run(args, strings.NewReader(stdin), &out, io.Discard)
+	return out.String(), err
+}
+
+func TestSample(t *testing.T) {
+	for _, input := range []string{"1 0 3", "1\n0\n3\n", "[1, 0, 3]", "a,1\nb,0\nc,3\n", `{"a": 1, "b": 0, "c": 3}`} {
+		out, err := runCmd(t, input, "-n", "1000", "-seed", "1", "-counts")
+		if err != nil {
+			t.Fatalf("%q: got err %v\n", input, err)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[0], "0\t") || !strings.HasPrefix(lines[1], "2\t") {
+			t.Fatalf("%q: got counts %q\n", input, out)
+		}
+	}
+
+	/* The same seed gives the same draws. */
+	a, _ := runCmd(t, "1 2 3", "-n", "20", "-seed", "5")
+	b, _ := runCmd(t, "1 2 3", "-n", "20", "-seed", "5")
+	if a != b || strings.Count(a, "\n") != 20 {
+		t.Fatalf("got %q and %q\n", a, b)
+	}
+
+	out, err := runCmd(t, "label,weight\nred,1\nblue,0\n", "-n", "3", "-labels")
+	if err != nil || out != "red\nred\nred\n" {
+		t.Fatalf("labels: got %q, err %v\n", out, err)
+	}
+	if _, err := runCmd(t, "1 2", "-labels"); err == nil {
+		t.Fatalf("-labels without labels was accepted\n")
+	}
+	for _, input := range []string{"1 x", "0 0 0", "1 NaN 1", "1 Inf 1", "-1 2 1"} {
+		if _, err := runCmd(t, input); err == nil {
+			t.Fatalf("%q: bad weights were accepted\n", input)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "w.json")
+	os.WriteFile(path, []byte(`{"only": 2}`), 0o644)
+	if out, err := runCmd(t, "", "-labels", path); err != nil || out != "only\n" {
+		t.Fatalf("file input: got %q, err %v\n", out, err)
+	}
+}
+
+func TestBench(t *testing.T) {
+	out, err := runCmd(t, "1 2 3 4 5", "bench", "-time", "1ms")
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(backends)+1 {
+		t.Fatalf("got report %q\n", out)
+	}
+	for i, b := range backends {
+		if fields := strings.Fields(lines[i+1]); len(fields) != 4 || fields[0] != b.name {
+			t.Fatalf("line %q: want 4 columns for %s\n", lines[i+1], b.name)
+		}
+	}
+
+	out, _ = runCmd(t, "1 2", "bench", "-time", "1ms", "-backends", "alias,cdf")
+	if strings.Count(out, "\n") != 3 {
+		t.Fatalf("got report %q for two backends\n", out)
+	}
+	if _, err := runCmd(t, "1 2", "bench", "-time", "1ms", "-backends", "nope"); err == nil {
+		t.Fatalf("unknown backend was accepted\n")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	out, err := runCmd(t, "1 0 3", "build", "-seed", "9", "-column", "fixed16")
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	s, err := alias_sample.Load([]byte(out))
+	if err != nil {
+		t.Fatalf("loading: got err %v\n", err)
+	}
+	want, _ := alias_sample.InitWithSeed([]float64{1, 0, 3}, 9, alias_sample.WithFixed16())
+	for range 100 {
+		if a, b := s.Next(), want.Next(); a != b {
+			t.Fatalf("loaded table drew %d, built one %d\n", a, b)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "t.alias")
+	if _, err := runCmd(t, "1 2", "build", "-o", path); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	data, _ := os.ReadFile(path)
+	if s, err := alias_sample.Load(data); err != nil || s.Len() != 2 {
+		t.Fatalf("loading %s: got err %v\n", path, err)
+	}
+	if _, err := runCmd(t, "1 2", "build", "-column", "int8"); err == nil {
+		t.Fatalf("unknown column storage was accepted\n")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.csv")
+	after := filepath.Join(dir, "after.json")
+	os.WriteFile(before, []byte("a,1\nb,1\nc,2\n"), 0o644)
+	os.WriteFile(after, []byte(`{"b": 1, "c": 1, "d": 2}`), 0o644)
+
+	out, err := runCmd(t, "", "diff", before, after)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for _, want := range []string{"total variation: 0.5\n", "added: d\n", "removed: a\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report %q lacks %q\n", out, want)
+		}
+	}
+	if lines := strings.Split(out, "\n"); len(lines) != 8 || !strings.HasPrefix(lines[3], "d ") {
+		t.Fatalf("got report %q\n", out)
+	}
+
+	/* Built tables have no labels, so they compare by index. */
+	table := filepath.Join(dir, "t.alias")
+	if _, err := runCmd(t, "1 1 2", "build", "-o", table); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	out, err = runCmd(t, "", "diff", table, before)
+	if err != nil || out != "label  before  after  delta\ntotal variation: 0\n" {
+		t.Fatalf("got %q, err %v\n", out, err)
+	}
+	if _, err := runCmd(t, "", "diff", table); err == nil {
+		t.Fatalf("diff of one file was accepted\n")
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+/* readWeights parses weights, and labels if the input has them, in the
+ * given format.  "auto" picks by file extension, and then by sniffing the
+ * first byte.
+ */
+func readWeights(in io.Reader, format, name string) (weights []float64, labels []string, err error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format == "auto" {
+		format = sniffFormat(data, name)
+	}
+
+	switch format {
+	case "json":
+		weights, labels, err = readJSON(data)
+	case "csv":
+		weights, labels, err = readCSV(data)
+	case "lines":
+		weights, err = readLines(data)
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err == nil && len(weights) == 0 {
+		err = fmt.Errorf("no weights in input")
+	}
+	if err == nil {
+		err = alias_sample.CheckWeights(weights)
+	}
+	return weights, labels, err
+}
+
+func sniffFormat(data []byte, name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".csv":
+		return "csv"
+	}
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{'):
+		return "json"
+	case bytes.ContainsRune(trimmed, ','):
+		return "csv"
+	}
+	return "lines"
+}
+
+/* readJSON takes an array of weights, or an object mapping labels to
+ * weights, which keeps the order the labels appear in.
+ */
+func readJSON(data []byte) ([]float64, []string, error) {
+	var arr []float64
+	if err := json.Unmarshal(data, &arr); err == nil {
+		return arr, nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, fmt.Errorf("JSON input must be an array of weights or an object of label: weight")
+	}
+	var weights []float64
+	var labels []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var w float64
+		if err := dec.Decode(&w); err != nil {
+			return nil, nil, fmt.Errorf("weight for %q: %v", tok, err)
+		}
+		labels = append(labels, tok.(string))
+		weights = append(weights, w)
+	}
+	return weights, labels, nil
+}
+
+/* readCSV takes rows of either weight or label,weight.  A first row
+ * whose weight doesn't parse is taken to be a header.
+ */
+func readCSV(data []byte) ([]float64, []string, error) {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	var weights []float64
+	var labels []string
+	for i, row := range rows {
+		var label, field string
+		switch len(row) {
+		case 1:
+			field = row[0]
+		case 2:
+			label, field = row[0], row[1]
+		default:
+			return nil, nil, fmt.Errorf("row %d: want weight or label,weight", i+1)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, nil, fmt.Errorf("row %d: %v", i+1, err)
+		}
+		if len(row) == 2 {
+			labels = append(labels, label)
+		}
+		weights = append(weights, w)
+	}
+	if labels != nil && len(labels) != len(weights) {
+		return nil, nil, fmt.Errorf("some rows have labels and some don't")
+	}
+	return weights, labels, nil
+}
+
+/* readLines takes weights separated by any whitespace. */
+func readLines(data []byte) ([]float64, error) {
+	var weights []float64
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Split(bufio.ScanWords)
+	for sc.Scan() {
+		w, err := strconv.ParseFloat(sc.Text(), 64)
+		if err != nil {
+			return nil, err
+		}
+		weights = append(weights, w)
+	}
+	return weights, sc.Err()
+}
@@ -0,0 +1,66 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// CoinSampler flips a biased coin: each flip comes up true with probability
+// p.  It needs no table, just one uniform variate per flip, drawn from the
+// same seeded stream as the rest of the package.  As a Sampler it has two
+// indices, 1 for true and 0 for false.
+type CoinSampler struct {
+	seed int64
+	rand *r.Rand
+
+	p float64
+}
+
+// Coin returns a coin that comes up true with probability pTrue, which must
+// be in [0, 1].  Coins of probability 0 and 1 are exact: they never come
+// up true and never come up false, respectively.
+func Coin(pTrue float64, seed int64) (*CoinSampler, error) {
+	if !(pTrue >= 0 && pTrue <= 1) {
+		return nil, &SampleError{"coin: p must be in [0, 1]"}
+	}
+	return &CoinSampler{seed: seed, rand: r.New(r.NewSource(seed)), p: pTrue}, nil
+}
+
+// Coin returns a coin that comes up true as often as s draws index 1.  s
+// must have two indices.  The coin shares s's random source, so like s it
+// must not be used from several goroutines at once.
+func (s *AliasSampler) Coin() (*CoinSampler, error) {
+	if s.n != 2 {
+		return nil, &SampleError{"coin: sampler must have two indices"}
+	}
+	return &CoinSampler{seed: s.seed, rand: s.source(), p: s.Prob(1)}, nil
+}
+
+// Flip flips the coin using its own random source.
+func (c *CoinSampler) Flip() bool {
+	return c.FlipFrom(c.rand)
+}
+
+// FlipFrom flips the coin using rng.
+func (c *CoinSampler) FlipFrom(rng *r.Rand) bool {
+	return rng.Float64() < c.p
+}
+
+func (c *CoinSampler) Next() int {
+	return c.NextFrom(c.rand)
+}
+
+func (c *CoinSampler) NextFrom(rng *r.Rand) int {
+	if c.FlipFrom(rng) {
+		return 1
+	}
+	return 0
+}
+
+func (c *CoinSampler) Len() int {
+	return 2
+}
+
+// P returns the probability that a flip comes up true.
+func (c *CoinSampler) P() float64 {
+	return c.p
+}
@@ -0,0 +1,69 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCoin(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		p := rapid.Float64Range(0, 1).Draw(t, "p")
+		seed := rapid.Int64().Draw(t, "seed")
+		c, err := Coin(p, seed)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if c.P() != p || c.Len() != 2 {
+			t.Fatalf("coin has p %v and %d indices\n", c.P(), c.Len())
+		}
+
+		/* Flips take one variate each from the seeded stream. */
+		rng := r.New(r.NewSource(seed))
+		sz := 20_000
+		var heads float64
+		for range sz {
+			flip := c.Flip()
+			if flip != (rng.Float64() < p) {
+				t.Fatalf("flip does not follow the seeded stream\n")
+			}
+			if flip {
+				heads++
+			}
+		}
+		if math.Abs(heads-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1e-9 {
+			t.Fatalf("got %v heads of %d, want p %v\n", heads, sz, p)
+		}
+	})
+
+	for _, p := range []float64{-0.1, 1.1, math.NaN()} {
+		if _, err := Coin(p, 1); err == nil {
+			t.Fatalf("coin of p %v was accepted\n", p)
+		}
+	}
+}
+
+func TestSamplerCoin(t *testing.T) {
+	s := MustInitWithSeed([]float64{1, 3}, 5)
+	c, err := s.Coin()
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if math.Abs(c.P()-0.75) > 1e-12 {
+		t.Fatalf("coin has p %v, want 0.75\n", c.P())
+	}
+	sz := 20_000
+	var ones float64
+	for range sz {
+		ones += float64(c.Next())
+	}
+	if math.Abs(ones-0.75*float64(sz)) > 6*math.Sqrt(float64(sz)*0.75*0.25) {
+		t.Fatalf("drew index 1 %v times of %d, want p 0.75\n", ones, sz)
+	}
+
+	if _, err := MustInit([]float64{1, 2, 3}).Coin(); err == nil {
+		t.Fatalf("three-index sampler made a coin\n")
+	}
+}
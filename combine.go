@@ -0,0 +1,111 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// Combine returns the element-wise product of two weight vectors over the
+// same index space, such as a base popularity and a contextual boost.
+func Combine(base, boost []float64) ([]float64, error) {
+	c, err := NewCombiner(base)
+	if err != nil {
+		return nil, err
+	}
+	return c.Weights(boost)
+}
+
+// A Combiner holds a validated base weight vector so that it can be
+// combined with a stream of boost vectors, checking and copying only the
+// boost each time.  It is not safe for concurrent use.
+type Combiner struct {
+	base    []float64
+	scratch []float64
+}
+
+func NewCombiner(base []float64) (*Combiner, error) {
+	if err := checkWeights(base); err != nil {
+		return nil, err
+	}
+	c := &Combiner{
+		base:    make([]float64, len(base)),
+		scratch: make([]float64, len(base)),
+	}
+	copy(c.base, base)
+	return c, nil
+}
+
+// Weights returns a new slice holding base[i] * boost[i].
+func (c *Combiner) Weights(boost []float64) ([]float64, error) {
+	if err := c.apply(boost); err != nil {
+		return nil, err
+	}
+	res := make([]float64, len(c.scratch))
+	copy(res, c.scratch)
+	return res, nil
+}
+
+// Sampler builds a sampler over base[i] * boost[i].  The product is formed
+// in scratch space owned by the Combiner, so apart from the table itself
+// this allocates nothing.  Options are applied as for Init.
+func (c *Combiner) Sampler(boost []float64, opts ...Option) (*AliasSampler, error) {
+	if err := c.apply(boost); err != nil {
+		return nil, err
+	}
+	return InitInPlace(c.scratch, opts...)
+}
+
+func (c *Combiner) apply(boost []float64) error {
+	if len(boost) != len(c.base) {
+		return &SampleError{"boost has a different length than the base"}
+	}
+	nonzero := false
+	for i, b := range boost {
+		if !ValidWeight(b) {
+			return &SampleError{"weights must be finite and non-negative"}
+		}
+		c.scratch[i] = c.base[i] * b
+		nonzero = nonzero || c.scratch[i] > 0
+	}
+	if !nonzero {
+		return &SampleError{"combined weights are all zero"}
+	}
+	return nil
+}
+
+// CheckWeights returns an error unless weights can define a distribution:
+// there must be at least one, each must pass ValidWeight, and at least one
+// must be positive.  Init doesn't check its input, so callers passing
+// weights they didn't compute themselves, such as ones read from a file or
+// passed across an FFI boundary, should check them first.  The error is a
+// *SampleError.
+func CheckWeights(weights []float64) error {
+	return checkWeights(weights)
+}
+
+// ValidWeight reports whether w can be a single weight: finite and
+// non-negative, so not NaN.  It is the per-weight half of CheckWeights, for
+// callers that check weights one at a time to say which one is bad.
+func ValidWeight(w float64) bool {
+	return w >= 0 && !math.IsInf(w, 1)
+}
+
+/* checkWeights reports whether weights can define a distribution: it must
+ * be non-empty, every entry finite and non-negative, and some entry
+ * positive.
+ */
+func checkWeights(weights []float64) error {
+	if len(weights) == 0 {
+		return &SampleError{"no probabilities provided"}
+	}
+	nonzero := false
+	for _, w := range weights {
+		if !ValidWeight(w) {
+			return &SampleError{"weights must be finite and non-negative"}
+		}
+		nonzero = nonzero || w > 0
+	}
+	if !nonzero {
+		return &SampleError{"weights are all zero"}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package alias_sample
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCombiner(t *testing.T) {
+	base := []float64{1, 2, 3, 0}
+	got, err := Combine(base, []float64{2, 0.5, 1, 9})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if want := []float64{2, 1, 3, 0}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v\n", got, want)
+	}
+
+	c, err := NewCombiner(base)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for _, boost := range [][]float64{{1, 1, 1, 1}, {0, 0, 1, 1}} {
+		s, err := c.Sampler(boost, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		var tot float64
+		for i := range base {
+			tot += base[i] * boost[i]
+		}
+		for i, p := range tableProbs(s) {
+			if want := base[i] * boost[i] / tot; math.Abs(p-want) > 1e-12 {
+				t.Fatalf("boost %v, index %d: got %g, want %g\n", boost, i, p, want)
+			}
+		}
+	}
+
+	for _, bad := range [][]float64{{1, 1}, {1, -1, 1, 1}, {1, math.NaN(), 1, 1}, {0, 0, 0, 1}} {
+		if _, err := c.Weights(bad); err == nil {
+			t.Errorf("boost %v was accepted\n", bad)
+		}
+	}
+	if _, err := NewCombiner([]float64{math.Inf(1)}); err == nil {
+		t.Errorf("infinite base was accepted\n")
+	}
+}
+
+func TestCheckWeights(t *testing.T) {
+	for _, w := range []float64{0, 1e-300, 1, math.MaxFloat64} {
+		if !ValidWeight(w) {
+			t.Fatalf("weight %v is invalid\n", w)
+		}
+	}
+	for _, w := range []float64{-1e-300, -1, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if ValidWeight(w) {
+			t.Fatalf("weight %v is valid\n", w)
+		}
+	}
+
+	if err := CheckWeights([]float64{0, 2, 1}); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for _, ws := range [][]float64{nil, {0, 0}, {1, math.NaN()}, {1, -1}, {math.Inf(1)}} {
+		if err := CheckWeights(ws); err == nil {
+			t.Fatalf("weights %v accepted\n", ws)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+)
+
+// PiecewiseConstantSampler draws real numbers from a histogram: it picks a
+// bucket with probability proportional to its weight, then returns a point
+// drawn uniformly from within that bucket.
+type PiecewiseConstantSampler struct {
+	buckets    *AliasSampler
+	boundaries []float64
+}
+
+// NewPiecewiseConstant returns a sampler over the buckets
+// [boundaries[i], boundaries[i+1]), where bucket i has total probability
+// proportional to weights[i], like the counts of a histogram (not its
+// densities).  boundaries must be finite, strictly increasing, and one
+// longer than weights.  Options are applied to the bucket table as for
+// Init.
+func NewPiecewiseConstant(boundaries, weights []float64, opts ...Option) (*PiecewiseConstantSampler, error) {
+	if err := checkBoundaries(boundaries, weights); err != nil {
+		return nil, err
+	}
+	buckets, err := Init(weights, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]float64, len(boundaries))
+	copy(b, boundaries)
+	return &PiecewiseConstantSampler{buckets: buckets, boundaries: b}, nil
+}
+
+func checkBoundaries(boundaries, weights []float64) error {
+	if len(boundaries) != len(weights)+1 {
+		return &SampleError{"need exactly one more boundary than weights"}
+	}
+	for i, b := range boundaries {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return &SampleError{"boundaries must be finite"}
+		}
+		if i > 0 && !(b > boundaries[i-1]) {
+			return &SampleError{"boundaries must be strictly increasing"}
+		}
+	}
+	return nil
+}
+
+func (s *PiecewiseConstantSampler) Next() float64 {
+	return s.NextFrom(s.buckets.source())
+}
+
+func (s *PiecewiseConstantSampler) NextFrom(rng *r.Rand) float64 {
+	i := s.buckets.NextFrom(rng)
+	lo, hi := s.boundaries[i], s.boundaries[i+1]
+	return lo + rng.Float64()*(hi-lo)
+}
+
+// PiecewiseLinearSampler draws real numbers from a density that is linear
+// within each bucket, so each bucket is a trapezoid rather than a
+// rectangle.  This matters when buckets are wide and the density changes
+// noticeably across them, as with latency distributions.
+type PiecewiseLinearSampler struct {
+	buckets    *AliasSampler
+	boundaries []float64
+	densities  []float64
+}
+
+// NewPiecewiseLinear returns a sampler whose density at boundaries[i] is
+// proportional to densities[i], interpolated linearly in between.
+// boundaries must be finite and strictly increasing, there must be at
+// least two of them, and there must be one (non-negative) density for
+// each.  Options are applied to the bucket table as for Init.
+func NewPiecewiseLinear(boundaries, densities []float64, opts ...Option) (*PiecewiseLinearSampler, error) {
+	if len(densities) != len(boundaries) || len(boundaries) < 2 {
+		return nil, &SampleError{"need at least two boundaries, each with a density"}
+	}
+	if err := checkBoundaries(boundaries, densities[1:]); err != nil {
+		return nil, err
+	}
+
+	/* The mass of each bucket is the area of its trapezoid. */
+	masses := make([]float64, len(boundaries)-1)
+	for i := range masses {
+		d0, d1 := densities[i], densities[i+1]
+		if !(d0 >= 0 && d1 >= 0) || math.IsInf(d0, 1) || math.IsInf(d1, 1) {
+			return nil, &SampleError{"densities must be finite and non-negative"}
+		}
+		masses[i] = (d0 + d1) / 2 * (boundaries[i+1] - boundaries[i])
+	}
+	buckets, err := InitInPlace(masses, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PiecewiseLinearSampler{
+		buckets:    buckets,
+		boundaries: make([]float64, len(boundaries)),
+		densities:  make([]float64, len(densities)),
+	}
+	copy(s.boundaries, boundaries)
+	copy(s.densities, densities)
+	return s, nil
+}
+
+func (s *PiecewiseLinearSampler) Next() float64 {
+	return s.NextFrom(s.buckets.source())
+}
+
+func (s *PiecewiseLinearSampler) NextFrom(rng *r.Rand) float64 {
+	i := s.buckets.NextFrom(rng)
+	lo, hi := s.boundaries[i], s.boundaries[i+1]
+	d0, d1 := s.densities[i], s.densities[i+1]
+	w := hi - lo
+
+	/* Invert the trapezoid's CDF, d0*t + (d1-d0)*t^2/(2w) = u*mass, using
+	 * the form of the quadratic formula that doesn't cancel when the
+	 * slope is small.
+	 */
+	um := rng.Float64() * (d0 + d1) / 2 * w
+	if um == 0 {
+		return lo
+	}
+	t := 2 * um / (d0 + math.Sqrt(d0*d0+2*(d1-d0)*um/w))
+	return min(lo+t, math.Nextafter(hi, lo))
+}
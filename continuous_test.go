@@ -0,0 +1,84 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPiecewiseConstant(t *testing.T) {
+	boundaries := []float64{-1, 0, 0.5, 3}
+	weights := []float64{1, 0, 3}
+	s, err := NewPiecewiseConstant(boundaries, weights, WithSeed(1))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	/* Bucket 0 has density 1/4 over [-1, 0) and bucket 2 has 3/4
+	 * spread evenly over [0.5, 3).
+	 */
+	sz := 200_000
+	var left, lowerHalf int
+	for range sz {
+		x := s.Next()
+		switch {
+		case x >= -1 && x < 0:
+			left++
+		case x >= 0.5 && x < 3:
+			if x < 1.75 {
+				lowerHalf++
+			}
+		default:
+			t.Fatalf("drew %g, outside every nonempty bucket\n", x)
+		}
+	}
+	if got := float64(left) / float64(sz); math.Abs(got-0.25) > 0.01 {
+		t.Errorf("P(bucket 0) = %g, want 0.25\n", got)
+	}
+	if got := float64(lowerHalf) / float64(sz); math.Abs(got-0.375) > 0.01 {
+		t.Errorf("P(lower half of bucket 2) = %g, want 0.375\n", got)
+	}
+
+	for _, bad := range [][]float64{{0, 1}, {0, 0, 1, 2}, {0, 1, math.Inf(1), 4}} {
+		if _, err := NewPiecewiseConstant(bad, weights); err == nil {
+			t.Errorf("boundaries %v were accepted\n", bad)
+		}
+	}
+}
+
+func TestPiecewiseLinear(t *testing.T) {
+	/* A rising ramp over [0, 1) followed by a flat bucket over [1, 2):
+	 * the ramp holds 1/3 of the mass, and within it P(x < 1/2) = 1/4.
+	 */
+	s, err := NewPiecewiseLinear([]float64{0, 1, 2}, []float64{0, 1, 1}, WithSeed(1))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	sz := 300_000
+	var ramp, lowRamp int
+	for range sz {
+		x := s.Next()
+		if x < 0 || x >= 2 {
+			t.Fatalf("drew %g, outside [0, 2)\n", x)
+		}
+		if x < 1 {
+			ramp++
+			if x < 0.5 {
+				lowRamp++
+			}
+		}
+	}
+	if got := float64(ramp) / float64(sz); math.Abs(got-1.0/3) > 0.01 {
+		t.Errorf("P(ramp) = %g, want 1/3\n", got)
+	}
+	if got := float64(lowRamp) / float64(ramp); math.Abs(got-0.25) > 0.01 {
+		t.Errorf("P(x < 1/2 | ramp) = %g, want 1/4\n", got)
+	}
+
+	if _, err := NewPiecewiseLinear([]float64{0, 1}, []float64{1}); err == nil {
+		t.Errorf("mismatched densities were accepted\n")
+	}
+	if _, err := NewPiecewiseLinear([]float64{0, 1}, []float64{1, -1}); err == nil {
+		t.Errorf("negative density was accepted\n")
+	}
+}
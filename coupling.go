@@ -0,0 +1,87 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// Coupling pairs draws from two samplers over the same indices so that
+// they agree as often as possible: the maximal coupling, under which the
+// two draws differ with probability equal to the total variation distance
+// between the distributions.  This is what minimizes churn when weights
+// change, for instance when moving traffic between backends: Transition
+// keeps an index assigned under the old weights wherever the new weights
+// allow it, and moves only the excess.  It draws from the old sampler's
+// random source.
+type Coupling struct {
+	old      *AliasSampler
+	stay     []float64     // chance an index drawn from old is kept
+	residual *AliasSampler // where moved draws go; nil if none ever move
+	overlap  float64
+}
+
+func NewCoupling(old, updated *AliasSampler) (*Coupling, error) {
+	if old.n != updated.n {
+		return nil, &SampleError{"samplers have different lengths"}
+	}
+	p, q := old.distribution(), updated.distribution()
+
+	/* An index keeps min(p, q) of its old mass.  What the new
+	 * distribution still has to hand out, q - p wherever that is
+	 * positive, is the residual that displaced draws are spread over.
+	 */
+	stay := make([]float64, old.n)
+	excess := make([]float64, old.n)
+	var overlap, moved float64
+	for i := range p {
+		switch {
+		case q[i] >= p[i]:
+			stay[i] = 1
+			excess[i] = q[i] - p[i]
+			moved += excess[i]
+		default:
+			stay[i] = q[i] / p[i]
+		}
+		overlap += min(p[i], q[i])
+	}
+
+	c := &Coupling{old: old, stay: stay, overlap: min(overlap, 1)}
+	if moved > 0 {
+		residual, err := InitInPlace(excess, WithSeed(old.seed))
+		if err != nil {
+			return nil, err
+		}
+		c.residual = residual
+	}
+	return c, nil
+}
+
+// Next draws an index from the old distribution and its partner under the
+// new one.
+func (c *Coupling) Next() (before, after int) {
+	return c.NextFrom(c.old.source())
+}
+
+func (c *Coupling) NextFrom(rng *r.Rand) (before, after int) {
+	before = c.old.NextFrom(rng)
+	return before, c.TransitionFrom(rng, before)
+}
+
+// Transition maps an index drawn under the old weights to one under the
+// new weights.  If old was drawn from the old distribution, the result
+// follows the new one exactly, and equals old as often as possible.
+func (c *Coupling) Transition(old int) int {
+	return c.TransitionFrom(c.old.source(), old)
+}
+
+func (c *Coupling) TransitionFrom(rng *r.Rand, old int) int {
+	if c.stay[old] == 1 || c.residual == nil || rng.Float64() < c.stay[old] {
+		return old
+	}
+	return c.residual.NextFrom(rng)
+}
+
+// Overlap returns the probability that the two draws agree, one minus the
+// total variation distance between the distributions.
+func (c *Coupling) Overlap() float64 {
+	return c.overlap
+}
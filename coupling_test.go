@@ -0,0 +1,63 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCoupling(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 20).Draw(t, "n")
+		ps := rapid.SliceOfN(rapid.Float64Range(0, 5.0), n, n).Draw(t, "old")
+		qs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), n, n).Draw(t, "new")
+		ps[0] += 0.001
+		qs[n-1] += 0.001
+
+		old, _ := Init(ps)
+		updated, _ := Init(qs)
+		c, err := NewCoupling(old, updated)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		p, q := tableProbs(old), tableProbs(updated)
+		var overlap float64
+		for i := range p {
+			overlap += min(p[i], q[i])
+		}
+		if math.Abs(c.Overlap()-overlap) > 1e-9 {
+			t.Fatalf("got overlap %g, want %g\n", c.Overlap(), overlap)
+		}
+
+		sz := 50_000
+		oldCounts := make([]int, n)
+		newCounts := make([]int, n)
+		same := 0
+		for range sz {
+			a, b := c.Next()
+			oldCounts[a]++
+			newCounts[b]++
+			if a == b {
+				same++
+			}
+		}
+		for i := range n {
+			if got := float64(oldCounts[i]) / float64(sz); math.Abs(got-p[i]) > 0.02 {
+				t.Fatalf("old index %d: got %g, want %g\n", i, got, p[i])
+			}
+			if got := float64(newCounts[i]) / float64(sz); math.Abs(got-q[i]) > 0.02 {
+				t.Fatalf("new index %d: got %g, want %g\n", i, got, q[i])
+			}
+		}
+		if got := float64(same) / float64(sz); math.Abs(got-overlap) > 0.02 {
+			t.Fatalf("draws agreed %g of the time, want %g\n", got, overlap)
+		}
+	})
+
+	a, _ := Init([]float64{1, 2})
+	b, _ := Init([]float64{1, 2, 3})
+	if _, err := NewCoupling(a, b); err == nil {
+		t.Errorf("samplers of different lengths were accepted\n")
+	}
+}
@@ -0,0 +1,59 @@
+package alias_sample
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger logs the details of each table build to logger at debug
+// level: the total weight the input was normalized by, how many entries
+// started out below (small) and above (large) the average, how many
+// columns were paired and how far the leftovers had to be rounded, and
+// whether WithMinProb or WithMaxProb rescaled the weights.  Failed builds
+// are logged with their error.  This is meant for working out why a
+// particular weight vector gives a skewed table.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+/* buildDiag collects what a build found out about its input, for
+ * WithLogger.
+ */
+type buildDiag struct {
+	total        float64 // the sum the weights were normalized by
+	small, large int     // how the entries were first classified
+}
+
+func (s *AliasSampler) logBuild(cfg *config, diag buildDiag, err error) {
+	ctx := context.Background()
+	if !cfg.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{slog.Int("n", s.n)}
+	if s.name != "" {
+		attrs = append(attrs, slog.String("name", s.name))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		cfg.logger.LogAttrs(ctx, slog.LevelDebug, "alias table build failed", attrs...)
+		return
+	}
+
+	attrs = append(attrs,
+		slog.String("table", s.describe()),
+		slog.Bool("clamped", cfg.minProb != 0 || cfg.maxProb != 1),
+	)
+	if s.mode == modeTable {
+		attrs = append(attrs,
+			slog.Float64("total", diag.total),
+			slog.Int("small", diag.small),
+			slog.Int("large", diag.large),
+			slog.Int("pairings", s.stats.Pairings),
+			slog.Float64("residual", s.stats.Residual),
+		)
+	}
+	attrs = append(attrs, slog.Duration("elapsed", s.stats.BuildTime))
+	cfg.logger.LogAttrs(ctx, slog.LevelDebug, "built alias table", attrs...)
+}
@@ -0,0 +1,40 @@
+package alias_sample
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	for _, opts := range [][]Option{nil, {WithSquaredHistogram()}} {
+		b.Reset()
+		if _, err := Init([]float64{1, 2, 5}, append(opts, WithLogger(logger))...); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		got := b.String()
+		for _, want := range []string{`msg="built alias table"`, "n=3", "total=8", "small=2", "large=1", "clamped=false"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("log %q is missing %q\n", got, want)
+			}
+		}
+	}
+
+	b.Reset()
+	Init([]float64{1, 1}, WithMinProb(0.9), WithLogger(logger))
+	if got := b.String(); !strings.Contains(got, "build failed") {
+		t.Errorf("failed build logged %q\n", got)
+	}
+
+	/* Nothing is logged above debug level. */
+	b.Reset()
+	quiet := slog.New(slog.NewTextHandler(&b, nil))
+	Init([]float64{1, 2, 5}, WithLogger(quiet))
+	if b.Len() != 0 {
+		t.Errorf("logged %q at info level\n", b.String())
+	}
+}
@@ -0,0 +1,109 @@
+package alias_sample
+
+import (
+	"math"
+	"strconv"
+)
+
+/* diffEpsilon is the smallest change Compare reports.  Probabilities are
+ * recovered from the table's columns, so identical weights can come back a
+ * rounding error apart; that is not a change worth reviewing.
+ */
+const diffEpsilon = 1e-12
+
+// Change is how one category's probability moved between two
+// distributions.
+type Change struct {
+	Label         string  // the category's label, or its index if unlabelled
+	Before, After float64 // its probability in each; 0 where it is absent
+}
+
+// Delta is After - Before.
+func (c Change) Delta() float64 {
+	return c.After - c.Before
+}
+
+// TableDiff summarizes the difference between two distributions, for
+// reviewing a change of weights before it is deployed.
+type TableDiff struct {
+	// Changes lists every category whose probability differs by more
+	// than rounding error, those of the old distribution first, in its
+	// order, then the added ones.
+	Changes []Change
+	// TotalVariation is the total variation distance between the two:
+	// the fraction of draws that would have to change category.
+	TotalVariation float64
+	// Added and Removed list the categories present in only the new or
+	// only the old distribution.
+	Added, Removed []string
+}
+
+// Compare reports how the distribution of after differs from that of
+// before.  Categories are matched by label, where beforeLabels and
+// afterLabels give one per index; if either is nil, categories are
+// matched by index and labelled with it, so a longer table adds indices and
+// a shorter one removes them.  Labels must be unique within a table.
+func Compare(before, after *AliasSampler, beforeLabels, afterLabels []string) (*TableDiff, error) {
+	if beforeLabels == nil || afterLabels == nil {
+		beforeLabels, afterLabels = indexLabels(before.n), indexLabels(after.n)
+	}
+	if len(beforeLabels) != before.n || len(afterLabels) != after.n {
+		return nil, &SampleError{"labels and sampler have different lengths"}
+	}
+	p, q := before.probabilities(), after.probabilities()
+
+	afterIndex := make(map[string]int, after.n)
+	for j, label := range afterLabels {
+		if _, dup := afterIndex[label]; dup {
+			return nil, &SampleError{"duplicate label " + strconv.Quote(label)}
+		}
+		afterIndex[label] = j
+	}
+
+	d := &TableDiff{}
+	seen := make(map[string]bool, before.n)
+	for i, label := range beforeLabels {
+		if seen[label] {
+			return nil, &SampleError{"duplicate label " + strconv.Quote(label)}
+		}
+		seen[label] = true
+		c := Change{Label: label, Before: p[i]}
+		if j, ok := afterIndex[label]; ok {
+			c.After = q[j]
+		} else {
+			d.Removed = append(d.Removed, label)
+		}
+		d.add(c)
+	}
+	for j, label := range afterLabels {
+		if !seen[label] {
+			d.Added = append(d.Added, label)
+			d.add(Change{Label: label, After: q[j]})
+		}
+	}
+
+	/* Each unit of mass that moves is both lost by one category and
+	 * gained by another, so the distance is half the summed deltas.
+	 */
+	d.TotalVariation = min(d.TotalVariation/2, 1)
+	return d, nil
+}
+
+/* add records c if it is a change, accumulating |delta| in TotalVariation
+ * until Compare halves it.
+ */
+func (d *TableDiff) add(c Change) {
+	if math.Abs(c.Delta()) <= diffEpsilon {
+		return
+	}
+	d.Changes = append(d.Changes, c)
+	d.TotalVariation += math.Abs(c.Delta())
+}
+
+func indexLabels(n int) []string {
+	labels := make([]string, n)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i)
+	}
+	return labels
+}
@@ -0,0 +1,72 @@
+package alias_sample
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCompare(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
+		before, _ := Init(probs)
+		same, _ := Init(probs)
+		d, err := Compare(before, same, nil, nil)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if len(d.Changes) != 0 || d.TotalVariation != 0 {
+			t.Fatalf("identical tables differ: %+v\n", d)
+		}
+
+		/* Against any other distribution, the total variation agrees
+		 * with the coupling's overlap.
+		 */
+		updated := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), len(probs), len(probs)).Draw(t, "updated")
+		after, _ := Init(updated)
+		d, _ = Compare(before, after, nil, nil)
+		c, _ := NewCoupling(before, after)
+		if math.Abs(d.TotalVariation-(1-c.Overlap())) > 1e-9 {
+			t.Fatalf("total variation %v, coupling overlap %v\n", d.TotalVariation, c.Overlap())
+		}
+		var sum float64
+		for _, ch := range d.Changes {
+			sum += ch.Delta()
+		}
+		if math.Abs(sum) > 1e-9 {
+			t.Fatalf("deltas sum to %v\n", sum)
+		}
+	})
+}
+
+func TestCompareLabels(t *testing.T) {
+	before, _ := Init([]float64{1, 1, 2})
+	after, _ := Init([]float64{2, 1, 1})
+	d, err := Compare(before, after, []string{"a", "b", "c"}, []string{"c", "b", "d"})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if !slices.Equal(d.Added, []string{"d"}) || !slices.Equal(d.Removed, []string{"a"}) {
+		t.Fatalf("added %v, removed %v\n", d.Added, d.Removed)
+	}
+	want := []Change{{"a", 0.25, 0}, {"d", 0, 0.25}}
+	if !slices.Equal(d.Changes, want) || d.TotalVariation != 0.25 {
+		t.Fatalf("got %+v\n", d)
+	}
+
+	/* Unlabelled tables of different lengths add or remove indices. */
+	longer, _ := Init([]float64{1, 1, 2, 4})
+	d, _ = Compare(before, longer, nil, nil)
+	if !slices.Equal(d.Added, []string{"3"}) || len(d.Removed) != 0 || d.TotalVariation != 0.5 {
+		t.Fatalf("got %+v\n", d)
+	}
+
+	if _, err := Compare(before, after, []string{"a", "a", "b"}, []string{"a", "b", "c"}); err == nil {
+		t.Fatalf("duplicate labels were accepted\n")
+	}
+	if _, err := Compare(before, after, []string{"a"}, []string{"a", "b", "c"}); err == nil {
+		t.Fatalf("short labels were accepted\n")
+	}
+}
@@ -0,0 +1,97 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+)
+
+// RandomWeights returns a weight vector of length n drawn from a symmetric
+// Dirichlet distribution with concentration alpha, normalized to sum to
+// 1.  Large alpha gives nearly uniform weights; alpha = 1 is uniform over
+// all distributions; small alpha concentrates the mass on a few indices.
+// It is meant for generating test and benchmark distributions with a
+// controllable skew.  RandomWeights panics if n < 0 or alpha is not
+// positive and finite.
+func RandomWeights(n int, alpha float64, rng *r.Rand) []float64 {
+	if n < 0 {
+		panic("alias_sample: RandomWeights with negative n")
+	}
+	if !(alpha > 0) || math.IsInf(alpha, 1) {
+		panic("alias_sample: RandomWeights with invalid alpha")
+	}
+
+	/* Each weight is an independent Gamma(alpha) variate.  For small alpha
+	 * these routinely underflow, so work with their logs and normalize
+	 * with the log-sum-exp trick.
+	 */
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = logGamma(alpha, rng)
+	}
+	return normalizeLogs(weights)
+}
+
+// Dirichlet returns a draw from the Dirichlet distribution with
+// concentrations alpha, a probability vector of the same length.  With two
+// concentrations a and b, the first element is a Beta(a, b) variate.
+// Dirichlet panics if an alpha is not positive and finite.
+func Dirichlet(alpha []float64, rng *r.Rand) []float64 {
+	for _, a := range alpha {
+		if !(a > 0) || math.IsInf(a, 1) {
+			panic("alias_sample: Dirichlet with invalid alpha")
+		}
+	}
+	weights := make([]float64, len(alpha))
+	for i, a := range alpha {
+		weights[i] = logGamma(a, rng)
+	}
+	return normalizeLogs(weights)
+}
+
+/* normalizeLogs turns log weights into a probability vector in place,
+ * subtracting the largest before exponentiating so that nothing
+ * overflows.
+ */
+func normalizeLogs(weights []float64) []float64 {
+	hi := math.Inf(-1)
+	for _, lw := range weights {
+		hi = max(hi, lw)
+	}
+	var tot float64
+	for i, lw := range weights {
+		weights[i] = math.Exp(lw - hi)
+		tot += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= tot
+	}
+	return weights
+}
+
+/* logGamma returns the log of a Gamma(alpha, 1) variate, using the method
+ * of Marsaglia and Tsang, "A Simple Method for Generating Gamma
+ * Variables", ACM TOMS 26(3), 2000.  For alpha < 1 it draws Gamma(alpha+1)
+ * and scales by U^(1/alpha), which is why it works in logs.
+ */
+func logGamma(alpha float64, rng *r.Rand) float64 {
+	boost := 0.0
+	if alpha < 1 {
+		boost = math.Log(1-rng.Float64()) / alpha
+		alpha++
+	}
+
+	d := alpha - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := 1 - rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return math.Log(d*v) + boost
+		}
+	}
+}
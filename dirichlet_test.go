@@ -0,0 +1,69 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"testing"
+)
+
+func TestRandomWeights(t *testing.T) {
+	rng := r.New(r.NewSource(1))
+	n := 10
+	for _, alpha := range []float64{1e-3, 0.5, 1, 5} {
+		/* Under a symmetric Dirichlet each weight has mean 1/n and variance
+		 * (n-1)/(n^2 (n*alpha+1)).
+		 */
+		trials := 20_000
+		var sum, sumSq float64
+		for range trials {
+			w := RandomWeights(n, alpha, rng)
+			var tot float64
+			for _, x := range w {
+				if !(x >= 0) {
+					t.Fatalf("alpha=%g: weight %g\n", alpha, x)
+				}
+				tot += x
+			}
+			if math.Abs(tot-1) > 1e-12 {
+				t.Fatalf("alpha=%g: weights sum to %g\n", alpha, tot)
+			}
+			sum += w[0]
+			sumSq += w[0] * w[0]
+		}
+
+		mean := sum / float64(trials)
+		variance := sumSq/float64(trials) - mean*mean
+		nf := float64(n)
+		wantVar := (nf - 1) / (nf * nf * (nf*alpha + 1))
+		if math.Abs(mean-1/nf) > 5*math.Sqrt(wantVar/float64(trials)) {
+			t.Errorf("alpha=%g: mean %g, want %g\n", alpha, mean, 1/nf)
+		}
+		if math.Abs(variance-wantVar) > 0.1*wantVar {
+			t.Errorf("alpha=%g: variance %g, want %g\n", alpha, variance, wantVar)
+		}
+	}
+}
+
+func TestDirichlet(t *testing.T) {
+	rng := r.New(r.NewSource(2))
+	for _, alpha := range [][]float64{{2, 5}, {0.1, 1, 10}, {1e-3, 1e-3}} {
+		var a0 float64
+		for _, a := range alpha {
+			a0 += a
+		}
+		trials := 20_000
+		sums := make([]float64, len(alpha))
+		for range trials {
+			for i, x := range Dirichlet(alpha, rng) {
+				sums[i] += x
+			}
+		}
+		for i, a := range alpha {
+			/* Each component has mean a/a0 and variance at most 1/4. */
+			want := a / a0
+			if got := sums[i] / float64(trials); math.Abs(got-want) > 5*0.5/math.Sqrt(float64(trials)) {
+				t.Errorf("alpha=%v: component %d has mean %g, want %g\n", alpha, i, got, want)
+			}
+		}
+	}
+}
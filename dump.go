@@ -0,0 +1,40 @@
+package alias_sample
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Dump writes the table to w in readable columns: for each index, the
+// probability of keeping its own column, the alias the rest of the column
+// goes to, and the probability of drawing the index overall that the table
+// works out to.  It is meant for debugging numerical problems, and writes
+// n+2 lines, so it is best kept to small tables.
+func (s *AliasSampler) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "# %d indices, %s\n", s.n, s.describe())
+	fmt.Fprintf(tw, "index\tprobability\talias\tfinal\t\n")
+
+	dist := s.distribution()
+	for i := range s.n {
+		fmt.Fprintf(tw, "%d\t%.17g\t%d\t%.17g\t\n", i, s.prob(i), s.aliasOf(i), dist[i])
+	}
+	return tw.Flush()
+}
+
+/* describe names how the table is stored. */
+func (s *AliasSampler) describe() string {
+	switch {
+	case s.mode == modeUniform:
+		return "uniform, no table"
+	case s.mode == modeConstant:
+		return fmt.Sprintf("constant %d, no table", s.only)
+	case s.probability32 != nil:
+		return "float32 probabilities"
+	case s.probability16 != nil:
+		return "fixed16 probabilities"
+	default:
+		return "float64 probabilities"
+	}
+}
@@ -0,0 +1,38 @@
+package alias_sample
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestDump(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 20).Draw(t, "probs")
+		probs[0] += 0.001
+		as, _ := Init(probs)
+
+		var b strings.Builder
+		if err := as.Dump(&b); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		if len(lines) != len(probs)+2 {
+			t.Fatalf("got %d lines, want %d\n", len(lines), len(probs)+2)
+		}
+
+		/* Every row must round-trip the table exactly. */
+		for i, line := range lines[2:] {
+			var idx, alias int
+			var prob, final float64
+			if _, err := fmt.Sscan(line, &idx, &prob, &alias, &final); err != nil {
+				t.Fatalf("row %q: %v\n", line, err)
+			}
+			if idx != i || prob != as.prob(i) || alias != as.aliasOf(i) || final != tableProbs(as)[i] {
+				t.Fatalf("row %q doesn't match the table\n", line)
+			}
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// Dynamic is a sampler whose weights can be replaced while it is in use.
+// Update builds a new table off to the side and then swaps it in
+// atomically, so draws never wait for a rebuild and never see a half-built
+// table.  Hooks registered with OnRebuild and OnSwap run on every update,
+// for invalidating anything derived from the old table, audit logging, or
+// refusing a swap.
+//
+// NextFrom and Sampler are safe to call concurrently with each other and
+// with Update; Next, which uses the Dynamic's own random source, is not.
+type Dynamic struct {
+	current atomic.Pointer[AliasSampler]
+	rand    *r.Rand
+	opts    []Option
+
+	mu        sync.Mutex // serializes updates and hook registration
+	onRebuild []func(old, new Stats)
+	onSwap    []func(old, new *AliasSampler) error
+}
+
+// NewDynamic builds a Dynamic with initial weights probs.  The options
+// apply to every table it builds, apart from WithBuffers, since every
+// table needs storage of its own.  Unlike Init, it rejects negative and
+// non-finite weights, here and in Update, since weights that change at
+// run time often come from outside the program.
+func NewDynamic(probs []float64, opts ...Option) (*Dynamic, error) {
+	opts = ownBuffers(opts)
+	cfg := newConfig(opts)
+	if err := checkWeights(probs); err != nil {
+		return nil, err
+	}
+	s, err := Init(probs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dynamic{rand: cfg.newRand(), opts: opts}
+	d.current.Store(s)
+	return d, nil
+}
+
+// OnRebuild registers f to be called after each update builds its new
+// table, with the Stats of the table in use and of the new one.  It is
+// called even if the swap is then vetoed.
+func (d *Dynamic) OnRebuild(f func(old, new Stats)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onRebuild = append(d.onRebuild, f)
+}
+
+// OnSwap registers f to be called just before each new table replaces the
+// one in use.  If f returns an error the swap is abandoned, later hooks
+// are not called, and Update returns the error.
+func (d *Dynamic) OnSwap(f func(old, new *AliasSampler) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onSwap = append(d.onSwap, f)
+}
+
+// Update replaces the weights with probs.  Draws continue from the old
+// table until the new one is swapped in.  Updates are serialized, table
+// build included, so a slow build can't be swapped in over a later
+// update's table.  Hooks run on the calling goroutine, with concurrent
+// updates waiting for them.  If the new weights are invalid, or a hook
+// vetoes the swap, the old table stays in use and the error is returned.
+func (d *Dynamic) Update(probs []float64) error {
+	if err := checkWeights(probs); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	next, err := Init(probs, d.opts...)
+	if err != nil {
+		return err
+	}
+	old := d.current.Load()
+	for _, f := range d.onRebuild {
+		f(old.Stats(), next.Stats())
+	}
+	for _, f := range d.onSwap {
+		if err := f(old, next); err != nil {
+			return err
+		}
+	}
+	d.current.Store(next)
+	return nil
+}
+
+// Sampler returns the table currently in use.  It stays valid, and keeps
+// drawing from the weights it was built with, after later updates.
+func (d *Dynamic) Sampler() *AliasSampler {
+	return d.current.Load()
+}
+
+func (d *Dynamic) Next() int {
+	return d.NextFrom(d.rand)
+}
+
+func (d *Dynamic) NextFrom(rng *r.Rand) int {
+	return d.current.Load().NextFrom(rng)
+}
+
+func (d *Dynamic) Len() int {
+	return d.current.Load().Len()
+}
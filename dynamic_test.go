@@ -0,0 +1,114 @@
+package alias_sample
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestDynamic(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		first := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 20).Draw(t, "first")
+		second := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 20).Draw(t, "second")
+		d, err := NewDynamic(first)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		var rebuilds int
+		d.OnRebuild(func(old, new Stats) {
+			rebuilds++
+		})
+		var swapped *AliasSampler
+		d.OnSwap(func(old, new *AliasSampler) error {
+			if old != d.Sampler() {
+				t.Fatalf("OnSwap got a stale old sampler\n")
+			}
+			swapped = new
+			return nil
+		})
+		if err := d.Update(second); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if rebuilds != 1 || swapped != d.Sampler() || d.Len() != len(second) {
+			t.Fatalf("after update: %d rebuilds, swapped in %p, using %p\n", rebuilds, swapped, d.Sampler())
+		}
+
+		var tot float64
+		for _, p := range second {
+			tot += p
+		}
+		sz := 20_000
+		counts := make([]int, len(second))
+		for range sz {
+			counts[d.Next()]++
+		}
+		for i, c := range counts {
+			if got := float64(c) / float64(sz); math.Abs(got-second[i]/tot) > 0.025 {
+				t.Fatalf("index %d: got %g, want %g\n", i, got, second[i]/tot)
+			}
+		}
+	})
+}
+
+func TestDynamicVeto(t *testing.T) {
+	d, _ := NewDynamic([]float64{1, 2})
+	before := d.Sampler()
+	d.OnSwap(func(old, new *AliasSampler) error {
+		return &SampleError{"no"}
+	})
+	if err := d.Update([]float64{3, 4, 5}); err == nil || d.Sampler() != before {
+		t.Fatalf("vetoed swap went through: err %v\n", err)
+	}
+	if err := d.Update(nil); err == nil {
+		t.Fatalf("empty weights were accepted\n")
+	}
+	if err := d.Update([]float64{-1}); err == nil {
+		t.Fatalf("negative weight was accepted\n")
+	}
+
+	/* Draws run alongside updates. */
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rng := newConfig([]Option{WithSeed(1)}).newRand()
+		for range 10_000 {
+			if i := d.NextFrom(rng); i < 0 || i > 1 {
+				t.Errorf("drew %d\n", i)
+				return
+			}
+		}
+	}()
+	for range 100 {
+		d.Update([]float64{1, 1})
+	}
+	wg.Wait()
+}
+
+func TestDynamicBuffers(t *testing.T) {
+	probability, alias := make([]float64, 16), make([]int, 16)
+	d, err := NewDynamic([]float64{1, 2, 3, 4, 5}, WithBuffers(probability, alias))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	old := d.Sampler()
+	want := old.distribution()
+
+	/* Updates must not write over the table old still draws from. */
+	for _, w := range [][]float64{{5, 4, 3, 2, 1}, {1, 0, 0, 0, 9}} {
+		if err := d.Update(w); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if &d.Sampler().probability[0] == &probability[0] {
+			t.Fatalf("update built into the caller's buffers\n")
+		}
+	}
+	for i, p := range old.distribution() {
+		if p != want[i] {
+			t.Fatalf("old table changed: index %d has %v, want %v\n", i, p, want[i])
+		}
+	}
+}
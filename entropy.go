@@ -0,0 +1,98 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// Entropy returns the Shannon entropy of the distribution in nats.  It
+// ranges from 0, when only one index can be drawn, to ln(n) for uniform
+// weights.
+func (s *AliasSampler) Entropy() float64 {
+	var h float64
+	for _, p := range s.probabilities() {
+		if p > 0 {
+			h -= p * math.Log(p)
+		}
+	}
+	return max(h, 0)
+}
+
+// EntropyBits returns the Shannon entropy of the distribution in bits.
+func (s *AliasSampler) EntropyBits() float64 {
+	return s.Entropy() / math.Ln2
+}
+
+// KLDivergence returns the Kullback-Leibler divergence KL(p‖q), in nats,
+// between the distributions given by two weight vectors, which need not be
+// normalized.  Indices where p is zero contribute nothing, whatever q is;
+// if q is zero anywhere p is not, the divergence is +Inf.  It returns an
+// error if the vectors differ in length or either is not a valid weight
+// vector.
+func KLDivergence(p, q []float64) (float64, error) {
+	return divergence(p, q, func(pi, qi float64) float64 {
+		/* Taking the log of the ratio, rather than the difference of
+		 * cross-entropy and entropy, keeps small divergences accurate.
+		 */
+		return pi * math.Log(pi/qi)
+	})
+}
+
+// CrossEntropy returns the cross-entropy H(p, q), in nats, of the
+// distribution given by q relative to that given by p, with the same
+// normalization and zero handling as KLDivergence.
+func CrossEntropy(p, q []float64) (float64, error) {
+	return divergence(p, q, func(pi, qi float64) float64 {
+		return -pi * math.Log(qi)
+	})
+}
+
+/* divergence normalizes p and q and sums term over the indices where p is
+ * nonzero.
+ */
+func divergence(p, q []float64, term func(pi, qi float64) float64) (float64, error) {
+	if len(p) != len(q) {
+		return 0, &SampleError{"weight vectors have different lengths"}
+	}
+	if err := checkWeights(p); err != nil {
+		return 0, err
+	}
+	if err := checkWeights(q); err != nil {
+		return 0, err
+	}
+
+	pTot, qTot := sum(p), sum(q)
+	var h float64
+	for i := range p {
+		if p[i] == 0 {
+			continue
+		}
+		if q[i] == 0 {
+			return math.Inf(1), nil
+		}
+		h += term(p[i]/pTot, q[i]/qTot)
+	}
+	return max(h, 0), nil
+}
+
+// EmpiricalDivergence returns KL(empirical‖target), in nats, between the
+// frequencies counted under WithTracking and the sampler's distribution: a
+// measure of how far the draws so far have drifted from what was asked for.
+// It returns an error if the sampler isn't tracking draws or hasn't made
+// any.
+func (s *AliasSampler) EmpiricalDivergence() (float64, error) {
+	if s.counts == nil {
+		return 0, &SampleError{"sampler was built without WithTracking"}
+	}
+	if s.Draws() == 0 {
+		return 0, &SampleError{"no draws have been counted"}
+	}
+	return KLDivergence(s.Empirical(), s.probabilities())
+}
+
+func sum(weights []float64) float64 {
+	var tot float64
+	for _, w := range weights {
+		tot += w
+	}
+	return tot
+}
@@ -0,0 +1,103 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestEntropy(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		var want float64
+		for _, p := range probs {
+			if p > 0 {
+				want -= p / tot * math.Log(p/tot)
+			}
+		}
+
+		as, _ := Init(probs)
+		if got := as.Entropy(); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("got entropy %g, want %g\n", got, want)
+		}
+		if got := as.EntropyBits(); math.Abs(got-want/math.Ln2) > 1e-9 {
+			t.Fatalf("got %g bits, want %g\n", got, want/math.Ln2)
+		}
+		if got := as.Entropy(); got > math.Log(float64(len(probs)))+1e-12 {
+			t.Fatalf("entropy %g exceeds ln(n)\n", got)
+		}
+	})
+
+	uniform, _ := Init([]float64{1, 1, 1, 1})
+	if got := uniform.EntropyBits(); math.Abs(got-2) > 1e-12 {
+		t.Errorf("uniform over 4: got %g bits, want 2\n", got)
+	}
+	constant, _ := Init([]float64{0, 3, 0})
+	if got := constant.Entropy(); got != 0 {
+		t.Errorf("constant: got entropy %g, want 0\n", got)
+	}
+}
+
+func TestDivergence(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 20).Draw(t, "n")
+		p := rapid.SliceOfN(rapid.Float64Range(0, 5.0), n, n).Draw(t, "p")
+		q := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), n, n).Draw(t, "q")
+		p[0] += 0.001
+
+		var pTot, qTot float64
+		for i := range p {
+			pTot += p[i]
+			qTot += q[i]
+		}
+		var kl, h float64
+		for i := range p {
+			if p[i] > 0 {
+				kl += p[i] / pTot * math.Log((p[i]/pTot)/(q[i]/qTot))
+				h -= p[i] / pTot * math.Log(q[i]/qTot)
+			}
+		}
+
+		gotKL, err := KLDivergence(p, q)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if math.Abs(gotKL-max(kl, 0)) > 1e-9 {
+			t.Fatalf("got KL %g, want %g\n", gotKL, kl)
+		}
+		if gotH, _ := CrossEntropy(p, q); math.Abs(gotH-h) > 1e-9 {
+			t.Fatalf("got cross-entropy %g, want %g\n", gotH, h)
+		}
+		if self, _ := KLDivergence(p, p); self > 1e-12 {
+			t.Fatalf("KL(p‖p) = %g\n", self)
+		}
+	})
+
+	if kl, _ := KLDivergence([]float64{1, 1}, []float64{1, 0}); !math.IsInf(kl, 1) {
+		t.Errorf("missing support: got KL %g, want +Inf\n", kl)
+	}
+	if kl, _ := KLDivergence([]float64{1, 0}, []float64{1, 1}); math.Abs(kl-math.Ln2) > 1e-12 {
+		t.Errorf("got KL %g, want ln 2\n", kl)
+	}
+	if _, err := KLDivergence([]float64{1}, []float64{1, 1}); err == nil {
+		t.Errorf("mismatched lengths were accepted\n")
+	}
+
+	as, _ := Init([]float64{1, 2, 3}, WithTracking())
+	if _, err := as.EmpiricalDivergence(); err == nil {
+		t.Errorf("divergence with no draws was accepted\n")
+	}
+	for range 100_000 {
+		as.Next()
+	}
+	if kl, err := as.EmpiricalDivergence(); err != nil || kl > 1e-3 {
+		t.Errorf("got empirical divergence %g, err %v\n", kl, err)
+	}
+}
@@ -0,0 +1,139 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"sync/atomic"
+)
+
+// An EpsilonSchedule gives the exploration rate for the draw made after t
+// earlier ones.  Rates outside [0, 1] are clamped.
+type EpsilonSchedule func(t uint64) float64
+
+// ConstantEpsilon explores at the same rate forever.
+func ConstantEpsilon(epsilon float64) EpsilonSchedule {
+	return func(uint64) float64 {
+		return epsilon
+	}
+}
+
+// ExponentialEpsilon starts exploring at rate start and halves the excess
+// over floor every halfLife draws.  halfLife must be positive; with zero
+// the first rate is NaN, which NewEpsilonGreedy rejects.
+func ExponentialEpsilon(start, floor float64, halfLife uint64) EpsilonSchedule {
+	return func(t uint64) float64 {
+		return floor + (start-floor)*math.Exp2(-float64(t)/float64(halfLife))
+	}
+}
+
+// InverseEpsilon explores at rate start / (1 + t/scale), never dropping
+// below floor.  Decaying as 1/t is the classic schedule under which
+// epsilon-greedy keeps exploring often enough to find the best arm.
+func InverseEpsilon(start, floor, scale float64) EpsilonSchedule {
+	return func(t uint64) float64 {
+		return max(start/(1+float64(t)/scale), floor)
+	}
+}
+
+// An EpsilonOption configures NewEpsilonGreedy.  Every Option is also an
+// EpsilonOption, though only WithSeed has any effect.
+type EpsilonOption interface {
+	applyEpsilon(c *epsilonConfig)
+}
+
+type epsilonConfig struct {
+	exploration Sampler // nil for uniform
+	opts        []Option
+}
+
+type epsilonOption func(c *epsilonConfig)
+
+func (o epsilonOption) applyEpsilon(c *epsilonConfig) {
+	o(c)
+}
+
+func (o Option) applyEpsilon(c *epsilonConfig) {
+	c.opts = append(c.opts, o)
+}
+
+// WithExploration makes an EpsilonGreedy explore by drawing from s, which
+// must have the same length as the sampler it wraps, instead of uniformly.
+func WithExploration(s Sampler) EpsilonOption {
+	return epsilonOption(func(c *epsilonConfig) {
+		c.exploration = s
+	})
+}
+
+// EpsilonGreedy wraps a Sampler so that a fraction of draws, epsilon,
+// explore: they come from a uniform choice over the indices, or from the
+// distribution given WithExploration, instead of from the wrapped sampler.
+// Epsilon follows a schedule over the number of draws made, so exploration
+// can taper off.  The draw count is atomic, so NextFrom is as safe for
+// concurrent use as the samplers it draws from.
+type EpsilonGreedy struct {
+	s        Sampler
+	explore  Sampler // nil for uniform
+	schedule EpsilonSchedule
+	rand     *r.Rand
+	draws    atomic.Uint64
+	explored atomic.Uint64
+}
+
+// NewEpsilonGreedy wraps s, exploring at the rate schedule gives.  It
+// returns an error if schedule is nil or its first rate is NaN.
+func NewEpsilonGreedy(s Sampler, schedule EpsilonSchedule, opts ...EpsilonOption) (*EpsilonGreedy, error) {
+	if schedule == nil {
+		return nil, &SampleError{"no exploration schedule"}
+	}
+	if math.IsNaN(schedule(0)) {
+		return nil, &SampleError{"exploration schedule gives NaN"}
+	}
+	var ec epsilonConfig
+	for _, opt := range opts {
+		opt.applyEpsilon(&ec)
+	}
+	cfg := newConfig(ec.opts)
+	if ec.exploration != nil && ec.exploration.Len() != s.Len() {
+		return nil, &SampleError{"exploration sampler has a different length"}
+	}
+	return &EpsilonGreedy{s: s, explore: ec.exploration, schedule: schedule, rand: cfg.newRand()}, nil
+}
+
+func (e *EpsilonGreedy) Next() int {
+	return e.NextFrom(e.rand)
+}
+
+func (e *EpsilonGreedy) NextFrom(rng *r.Rand) int {
+	t := e.draws.Add(1) - 1
+	if rng.Float64() >= e.epsilon(t) {
+		return e.s.NextFrom(rng)
+	}
+	e.explored.Add(1)
+	if e.explore != nil {
+		return e.explore.NextFrom(rng)
+	}
+	return rng.Intn(e.s.Len())
+}
+
+func (e *EpsilonGreedy) Len() int {
+	return e.s.Len()
+}
+
+// Epsilon returns the exploration rate the next draw will use.
+func (e *EpsilonGreedy) Epsilon() float64 {
+	return e.epsilon(e.draws.Load())
+}
+
+// Draws returns how many draws have been made, and how many of them
+// explored.
+func (e *EpsilonGreedy) Draws() (total, explored uint64) {
+	return e.draws.Load(), e.explored.Load()
+}
+
+func (e *EpsilonGreedy) epsilon(t uint64) float64 {
+	eps := e.schedule(t)
+	if !(eps > 0) {
+		return 0
+	}
+	return min(eps, 1)
+}
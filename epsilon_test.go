@@ -0,0 +1,100 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestEpsilonGreedy(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(2, 10).Draw(t, "n")
+		eps := rapid.Float64Range(0.05, 0.95).Draw(t, "eps")
+
+		/* The wrapped sampler only ever returns 0, so every other index
+		 * must come from exploring, uniformly.
+		 */
+		probs := make([]float64, n)
+		probs[0] = 1
+		as, _ := InitWithSeed(probs, 1)
+		e, err := NewEpsilonGreedy(as, ConstantEpsilon(eps), WithSeed(2))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		sz := 50_000
+		counts := make([]int, n)
+		for range sz {
+			counts[e.Next()]++
+		}
+		total, explored := e.Draws()
+		if total != uint64(sz) {
+			t.Fatalf("counted %d draws, made %d\n", total, sz)
+		}
+		sd := math.Sqrt(float64(sz) * eps * (1 - eps))
+		if math.Abs(float64(explored)-float64(sz)*eps) > 6*sd {
+			t.Fatalf("explored %d of %d at rate %v\n", explored, sz, eps)
+		}
+		want := float64(explored) / float64(n)
+		for i := 1; i < n; i++ {
+			if math.Abs(float64(counts[i])-want) > 6*math.Sqrt(want) {
+				t.Fatalf("index %d drawn %d times, want about %v\n", i, counts[i], want)
+			}
+		}
+	})
+}
+
+func TestEpsilonExploration(t *testing.T) {
+	as, _ := Init([]float64{1, 0, 0})
+	other, _ := Init([]float64{0, 0, 1})
+	e, err := NewEpsilonGreedy(as, ConstantEpsilon(1), WithExploration(other))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for range 1000 {
+		if i := e.Next(); i != 2 {
+			t.Fatalf("explored to %d, want 2\n", i)
+		}
+	}
+
+	short, _ := Init([]float64{1, 1})
+	if _, err := NewEpsilonGreedy(as, ConstantEpsilon(1), WithExploration(short)); err == nil {
+		t.Fatalf("expected error for mismatched exploration length\n")
+	}
+	if _, err := NewEpsilonGreedy(as, nil); err == nil {
+		t.Fatalf("expected error for a nil schedule\n")
+	}
+	if _, err := NewEpsilonGreedy(as, ExponentialEpsilon(0.5, 0.1, 0)); err == nil {
+		t.Fatalf("expected error for a zero half life\n")
+	}
+}
+
+func TestEpsilonSchedules(t *testing.T) {
+	exp := ExponentialEpsilon(0.5, 0.1, 100)
+	if got := exp(0); got != 0.5 {
+		t.Fatalf("exponential starts at %v\n", got)
+	}
+	if got := exp(100); math.Abs(got-0.3) > 1e-12 {
+		t.Fatalf("exponential after one half life is %v, want 0.3\n", got)
+	}
+	inv := InverseEpsilon(1, 0.01, 10)
+	if got := inv(10); got != 0.5 {
+		t.Fatalf("inverse at scale is %v, want 0.5\n", got)
+	}
+	if got := inv(1 << 40); got != 0.01 {
+		t.Fatalf("inverse floor is %v, want 0.01\n", got)
+	}
+
+	/* Decay is driven by the draw count, and rates are clamped. */
+	as, _ := Init([]float64{1, 1})
+	e, _ := NewEpsilonGreedy(as, InverseEpsilon(2, 0, 1))
+	if got := e.Epsilon(); got != 1 {
+		t.Fatalf("epsilon %v not clamped to 1\n", got)
+	}
+	for range 3 {
+		e.Next()
+	}
+	if got := e.Epsilon(); got != 0.5 {
+		t.Fatalf("epsilon after 3 draws is %v, want 0.5\n", got)
+	}
+}
@@ -0,0 +1,126 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"runtime"
+	"sync"
+)
+
+/* estimateChunk is the number of draws each chunk of an estimate makes,
+ * from a random source of its own.  As with parallelChunk, the layout
+ * depends only on n, so the result does not depend on the number of
+ * workers.
+ */
+const estimateChunk = 1 << 16
+
+/* estimateBatch is how many draws a chunk makes at a time. */
+const estimateBatch = 1 << 10
+
+/* estimateZ is the standard normal quantile for a two-sided 95% interval. */
+const estimateZ = 1.959963984540054
+
+// Estimate approximates the expected value of f(i), for i drawn from the
+// sampler, by averaging f over n draws.  It returns the estimate and the
+// half-width of an approximate 95% confidence interval around it, from the
+// standard error of the mean.  With no draws the estimate is NaN, and with
+// fewer than two ci is +Inf.  The draws are made in batches, as by NextN,
+// and counted like any other draws.
+func (s *AliasSampler) Estimate(f func(i int) float64, n int) (mean, ci float64) {
+	return s.EstimateParallel(f, n, 1)
+}
+
+// EstimateParallel is Estimate with the draws split between up to workers
+// goroutines; zero or less means runtime.GOMAXPROCS(0).  f must be safe to
+// call concurrently.  The draws are divided into fixed-size chunks, each
+// with a random source seeded from the sampler's, so a given seed gives the
+// same estimate whatever the number of workers.
+func (s *AliasSampler) EstimateParallel(f func(i int) float64, n, workers int) (mean, ci float64) {
+	if n <= 0 {
+		return math.NaN(), math.Inf(1)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := (n + estimateChunk - 1) / estimateChunk
+	seeds := make([]int64, chunks)
+	for c := range seeds {
+		seeds[c] = s.source().Int63()
+	}
+	partial := make([]welford, chunks)
+	run := func(c int) {
+		rng := r.New(r.NewSource(seeds[c]))
+		draws := make([]int, estimateBatch)
+		for left := min(estimateChunk, n-c*estimateChunk); left > 0; left -= len(draws) {
+			draws = draws[:min(left, estimateBatch)]
+			s.nextNFrom(draws, rng)
+			s.record(draws)
+			for _, i := range draws {
+				partial[c].add(f(i))
+			}
+		}
+	}
+
+	if workers == 1 || chunks == 1 {
+		for c := range chunks {
+			run(c)
+		}
+	} else {
+		var wg sync.WaitGroup
+		next := make(chan int)
+		for range min(workers, chunks) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := range next {
+					run(c)
+				}
+			}()
+		}
+		for c := range chunks {
+			next <- c
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	/* Merge in chunk order, so that the rounding is the same however the
+	 * chunks were scheduled.
+	 */
+	var total welford
+	for _, w := range partial {
+		total.merge(w)
+	}
+	if total.n < 2 {
+		return total.mean, math.Inf(1)
+	}
+	variance := total.m2 / (total.n - 1)
+	return total.mean, estimateZ * math.Sqrt(variance/total.n)
+}
+
+/* welford accumulates a mean and sum of squared deviations in one pass,
+ * without the cancellation of summing squares.
+ */
+type welford struct {
+	n, mean, m2 float64
+}
+
+func (w *welford) add(x float64) {
+	w.n++
+	d := x - w.mean
+	w.mean += d / w.n
+	w.m2 += d * (x - w.mean)
+}
+
+/* merge folds o into w, by Chan et al.'s pairwise update. */
+func (w *welford) merge(o welford) {
+	if o.n == 0 {
+		return
+	}
+	n := w.n + o.n
+	d := o.mean - w.mean
+	w.mean += d * o.n / n
+	w.m2 += o.m2 + d*d*w.n*o.n/n
+	w.n = n
+}
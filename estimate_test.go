@@ -0,0 +1,60 @@
+package alias_sample
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestEstimate(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
+		values := rapid.SliceOfN(rapid.Float64Range(-100, 100), len(probs), len(probs)).Draw(t, "values")
+		n := rapid.IntRange(2, 300_000).Draw(t, "n")
+		seed := rapid.Int64().Draw(t, "seed")
+		f := func(i int) float64 { return values[i] }
+
+		as, _ := InitWithSeed(probs, seed, WithTracking())
+		mean, ci := as.Estimate(f, n)
+		want, _ := as.Mean(values)
+		variance, _ := as.Variance(values)
+
+		/* Check against the true standard error, since with few draws
+		 * of a skewed distribution the estimated one can be far too
+		 * small.  The normal approximation also fails then, when one
+		 * draw of a rare value moves the mean by many standard errors,
+		 * so allow for that as Bernstein's inequality does, with a term
+		 * in the spread of the values over n.
+		 */
+		spread := slices.Max(values) - slices.Min(values)
+		se := math.Sqrt(variance / float64(n))
+		if math.Abs(mean-want) > 6*se+20*spread/float64(n)+1e-9 {
+			t.Fatalf("estimated %v ± %v, want %v with standard error %v\n", mean, ci, want, se)
+		}
+		if as.Draws() != uint64(n) {
+			t.Fatalf("made %d draws, want %d\n", as.Draws(), n)
+		}
+
+		/* The number of workers doesn't change the result. */
+		as2, _ := InitWithSeed(probs, seed)
+		workers := rapid.IntRange(0, 8).Draw(t, "workers")
+		if m, c := as2.EstimateParallel(f, n, workers); m != mean || c != ci {
+			t.Fatalf("%d workers estimated %v ± %v, one %v ± %v\n", workers, m, c, mean, ci)
+		}
+	})
+}
+
+func TestEstimateFew(t *testing.T) {
+	as, _ := Init([]float64{1, 2})
+	if mean, ci := as.Estimate(func(int) float64 { return 1 }, 0); !math.IsNaN(mean) || !math.IsInf(ci, 1) {
+		t.Fatalf("no draws: got %v ± %v\n", mean, ci)
+	}
+	if mean, ci := as.Estimate(func(int) float64 { return 3 }, 1); mean != 3 || !math.IsInf(ci, 1) {
+		t.Fatalf("one draw: got %v ± %v\n", mean, ci)
+	}
+	if mean, ci := as.Estimate(func(int) float64 { return 3 }, 1000); mean != 3 || ci != 0 {
+		t.Fatalf("constant f: got %v ± %v\n", mean, ci)
+	}
+}
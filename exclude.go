@@ -0,0 +1,91 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+/* excludeRetries is how many ordinary draws NextExcluding tries before
+ * falling back to an exact scan of the table.
+ */
+const excludeRetries = 16
+
+// NextExcluding draws an index conditioned on it not being in exclude, such
+// as picking a different server than last time, without rebuilding the
+// table.  It makes a bounded number of ordinary draws, rejecting excluded
+// ones, and then falls back to an O(n) scan that samples the conditional
+// distribution directly, so the result is exact either way.  It returns
+// an error if every index that can be drawn is excluded.
+func (s *AliasSampler) NextExcluding(exclude map[int]struct{}) (int, error) {
+	return s.NextExcludingFunc(func(i int) bool {
+		_, ok := exclude[i]
+		return ok
+	})
+}
+
+// NextExcludingFunc is like NextExcluding, but takes a predicate reporting
+// whether an index is excluded, so that any set representation (a bitset,
+// say) can be used.
+func (s *AliasSampler) NextExcludingFunc(excluded func(int) bool) (int, error) {
+	return s.nextExcluding(s.source(), excluded)
+}
+
+func (s *AliasSampler) nextExcluding(rng *r.Rand, excluded func(int) bool) (int, error) {
+	for range excludeRetries {
+		if i := s.nextFrom(rng); !excluded(i) {
+			return i, nil
+		}
+	}
+
+	/* Each column sends prob/n of the mass to itself and the rest to its
+	 * alias.  Total up what reaches indices that aren't excluded, then
+	 * walk the columns again to find where a uniform draw over that mass
+	 * lands.
+	 */
+	var mass float64
+	s.eachShare(func(i int, share float64) bool {
+		if !excluded(i) {
+			mass += share
+		}
+		return true
+	})
+	if mass <= 0 {
+		return 0, &SampleError{"every index with nonzero probability is excluded"}
+	}
+
+	u := rng.Float64() * mass
+	res, last := -1, -1
+	s.eachShare(func(i int, share float64) bool {
+		if excluded(i) || share == 0 {
+			return true
+		}
+		last = i
+		u -= share
+		if u < 0 {
+			res = i
+			return false
+		}
+		return true
+	})
+	if res < 0 {
+		/* Rounding left a sliver of u uncovered. */
+		res = last
+	}
+	return res, nil
+}
+
+/* eachShare calls f with each portion of probability the table assigns,
+ * column by column, until f returns false.  An index receives shares from
+ * its own column and from every column aliased to it.
+ */
+func (s *AliasSampler) eachShare(f func(i int, share float64) bool) {
+	n := float64(s.n)
+	for column := range s.n {
+		p := s.prob(column)
+		if !f(column, p/n) {
+			return
+		}
+		if p < 1 && !f(s.aliasOf(column), (1-p)/n) {
+			return
+		}
+	}
+}
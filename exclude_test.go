@@ -0,0 +1,57 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestNextExcluding(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 2, 20).Draw(t, "probs")
+		as, err := Init(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Exclude all but one or two indices, so that most draws go
+		 * through the exact fallback.
+		 */
+		keep := rapid.SliceOfNDistinct(rapid.IntRange(0, len(probs)-1), 1, 2, rapid.ID[int]).Draw(t, "keep")
+		exclude := map[int]struct{}{}
+		for i := range probs {
+			exclude[i] = struct{}{}
+		}
+		var tot float64
+		for _, k := range keep {
+			delete(exclude, k)
+			tot += probs[k]
+		}
+
+		sz := 20_000
+		counts := map[int]int{}
+		for range sz {
+			i, err := as.NextExcluding(exclude)
+			if err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+			if _, ok := exclude[i]; ok {
+				t.Fatalf("drew excluded index %d\n", i)
+			}
+			counts[i]++
+		}
+		for _, k := range keep {
+			if got := float64(counts[k]) / float64(sz); math.Abs(got-probs[k]/tot) > 0.02 {
+				t.Fatalf("index %d: got %g, want %g\n", k, got, probs[k]/tot)
+			}
+		}
+
+		exclude[keep[0]] = struct{}{}
+		if len(keep) == 1 {
+			if _, err := as.NextExcluding(exclude); err == nil {
+				t.Fatalf("excluding everything was accepted")
+			}
+		}
+	})
+}
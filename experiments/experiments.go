@@ -0,0 +1,186 @@
+// Package experiments assigns users to the variants of A/B experiments.
+// Assignment is sticky: a user's variant is a function of the experiment's
+// name and the user's key alone, so every server agrees on it without any
+// shared state, and it holds across restarts.
+package experiments
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Variant is one arm of an experiment and its share of the users.
+type Variant struct {
+	Name   string
+	Weight float64
+}
+
+// Experiment assigns keys to variants in proportion to their weights.  A
+// key is assigned by hashing it, with the experiment's name as salt, to a
+// point in [0, 1) and looking that point up in the variants' cumulative
+// distribution.  A second, independent hash decides whether the key falls
+// within the ramp, the fraction of users enrolled at all, so raising the
+// ramp only ever adds users and never moves those already enrolled to
+// another variant.  All methods are safe for concurrent use.
+type Experiment struct {
+	name      string
+	variants  []string
+	cdf       *alias_sample.CDFSampler
+	ramp      atomic.Uint64 // math.Float64bits of the enrolled fraction
+	exposures []atomic.Uint64
+	excluded  atomic.Uint64
+}
+
+// New defines an experiment, with every user enrolled.  Variant names must
+// be unique, and weights finite and non-negative, with at least one
+// positive.
+func New(name string, variants []Variant) (*Experiment, error) {
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("experiments: %s has no variants", name)
+	}
+	seen := make(map[string]bool, len(variants))
+	names := make([]string, len(variants))
+	weights := make([]float64, len(variants))
+	var total float64
+	for i, v := range variants {
+		if seen[v.Name] {
+			return nil, fmt.Errorf("experiments: %s has variant %q twice", name, v.Name)
+		}
+		seen[v.Name] = true
+		if !alias_sample.ValidWeight(v.Weight) {
+			return nil, fmt.Errorf("experiments: %s variant %q has weight %v", name, v.Name, v.Weight)
+		}
+		names[i], weights[i] = v.Name, v.Weight
+		total += v.Weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("experiments: %s has no variant with positive weight", name)
+	}
+	cdf, err := alias_sample.InitCDF(weights)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Experiment{name: name, variants: names, cdf: cdf, exposures: make([]atomic.Uint64, len(variants))}
+	e.ramp.Store(math.Float64bits(1))
+	return e, nil
+}
+
+func (e *Experiment) Name() string {
+	return e.name
+}
+
+// Assign returns the variant key is in, counting an exposure to it, or
+// false if key is outside the ramp.
+func (e *Experiment) Assign(key string) (string, bool) {
+	if e.point(key, "ramp") >= e.Ramp() {
+		e.excluded.Add(1)
+		return "", false
+	}
+	i := e.cdf.Quantile(e.point(key, "variant"))
+	e.exposures[i].Add(1)
+	return e.variants[i], true
+}
+
+// SetRamp enrolls the given fraction of users, between 0 and 1.
+func (e *Experiment) SetRamp(fraction float64) error {
+	if !(fraction >= 0 && fraction <= 1) {
+		return fmt.Errorf("experiments: ramp %v is not between 0 and 1", fraction)
+	}
+	e.ramp.Store(math.Float64bits(fraction))
+	return nil
+}
+
+// Ramp returns the fraction of users enrolled.
+func (e *Experiment) Ramp() float64 {
+	return math.Float64frombits(e.ramp.Load())
+}
+
+// Exposures returns how many times Assign has returned each variant, and
+// how many times it has turned a key away for being outside the ramp.
+// Calls for the same key are counted each time.
+func (e *Experiment) Exposures() (variants map[string]uint64, excluded uint64) {
+	variants = make(map[string]uint64, len(e.variants))
+	for i, name := range e.variants {
+		variants[name] = e.exposures[i].Load()
+	}
+	return variants, e.excluded.Load()
+}
+
+/* point hashes key, salted with the experiment's name and purpose, to a
+ * uniform point in [0, 1).  FNV alone mixes its last bytes poorly, so the
+ * hash goes through a splitmix64 finalizer.
+ */
+func (e *Experiment) point(key, purpose string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(e.name))
+	h.Write([]byte{0})
+	h.Write([]byte(purpose))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	z := h.Sum64()
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11) * 0x1p-53
+}
+
+// Registry holds a set of experiments by name.  It is safe for concurrent
+// use.
+type Registry struct {
+	mu          sync.RWMutex
+	experiments map[string]*Experiment
+}
+
+func NewRegistry() *Registry {
+	return &Registry{experiments: map[string]*Experiment{}}
+}
+
+// Define adds a new experiment; see New.  It is an error to define the
+// same name twice.
+func (reg *Registry) Define(name string, variants []Variant) (*Experiment, error) {
+	e, err := New(name, variants)
+	if err != nil {
+		return nil, err
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if _, ok := reg.experiments[name]; ok {
+		return nil, fmt.Errorf("experiments: %s is already defined", name)
+	}
+	reg.experiments[name] = e
+	return e, nil
+}
+
+// Lookup returns the named experiment, or nil if there isn't one.
+func (reg *Registry) Lookup(name string) *Experiment {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	return reg.experiments[name]
+}
+
+// Remove ends the named experiment.
+func (reg *Registry) Remove(name string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	delete(reg.experiments, name)
+}
+
+// Assign returns key's variant in every experiment it is enrolled in,
+// counting an exposure to each.
+func (reg *Registry) Assign(key string) map[string]string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	res := map[string]string{}
+	for name, e := range reg.experiments {
+		if v, ok := e.Assign(key); ok {
+			res[name] = v
+		}
+	}
+	return res
+}
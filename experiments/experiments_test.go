@@ -0,0 +1,118 @@
+package experiments
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestAssign(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 10).Draw(t, "weights")
+		weights[0] += 0.1
+		variants := make([]Variant, len(weights))
+		var total float64
+		for i, w := range weights {
+			variants[i] = Variant{Name: fmt.Sprint("v", i), Weight: w}
+			total += w
+		}
+		e, err := New(rapid.StringN(1, 10, -1).Draw(t, "name"), variants)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		sz := 50_000
+		for k := range sz {
+			v, ok := e.Assign(fmt.Sprint("user", k))
+			if !ok {
+				t.Fatalf("user %d not enrolled at full ramp\n", k)
+			}
+			if again, _ := e.Assign(fmt.Sprint("user", k)); again != v {
+				t.Fatalf("user %d assigned %s, then %s\n", k, v, again)
+			}
+		}
+		counts, excluded := e.Exposures()
+		if excluded != 0 {
+			t.Fatalf("%d excluded at full ramp\n", excluded)
+		}
+		for i, w := range weights {
+			got := float64(counts[variants[i].Name]) / float64(2*sz)
+			if math.Abs(got-w/total) > 0.015 {
+				t.Fatalf("variant %d: got share %v, want %v\n", i, got, w/total)
+			}
+		}
+	})
+}
+
+func TestRamp(t *testing.T) {
+	e, _ := New("checkout", []Variant{{"control", 1}, {"treatment", 1}})
+	full := map[string]string{}
+	for k := range 10_000 {
+		full[fmt.Sprint(k)], _ = e.Assign(fmt.Sprint(k))
+	}
+
+	/* Lowering the ramp drops users but doesn't move anyone still
+	 * enrolled, and raising it brings the same users back.
+	 */
+	for _, ramp := range []float64{0.1, 0.5, 0.9} {
+		if err := e.SetRamp(ramp); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		enrolled := 0
+		for key, want := range full {
+			if v, ok := e.Assign(key); ok {
+				enrolled++
+				if v != want {
+					t.Fatalf("ramp %v moved %s from %s to %s\n", ramp, key, want, v)
+				}
+			}
+		}
+		if got := float64(enrolled) / float64(len(full)); math.Abs(got-ramp) > 0.03 {
+			t.Fatalf("ramp %v enrolled %v of users\n", ramp, got)
+		}
+	}
+	if err := e.SetRamp(1.5); err == nil {
+		t.Fatalf("ramp above 1 was accepted\n")
+	}
+	if _, excluded := e.Exposures(); excluded == 0 {
+		t.Fatalf("no exclusions counted\n")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry()
+	if _, err := reg.Define("a", []Variant{{"x", 1}, {"y", 1}}); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	b, _ := reg.Define("b", []Variant{{"only", 1}})
+	if _, err := reg.Define("a", []Variant{{"x", 1}}); err == nil {
+		t.Fatalf("redefinition was accepted\n")
+	}
+	if reg.Lookup("b") != b || reg.Lookup("c") != nil {
+		t.Fatalf("lookup found the wrong experiments\n")
+	}
+
+	/* With b ramped down to nothing, only a assigns. */
+	b.SetRamp(0)
+	if got := reg.Assign("user"); len(got) != 1 || (got["a"] != "x" && got["a"] != "y") {
+		t.Fatalf("got assignments %v\n", got)
+	}
+	reg.Remove("a")
+	if got := reg.Assign("user"); len(got) != 0 {
+		t.Fatalf("got assignments %v after removing a\n", got)
+	}
+
+	for _, bad := range [][]Variant{
+		nil,
+		{{"x", 1}, {"x", 2}},
+		{{"x", 0}},
+		{{"x", -1}, {"y", 2}},
+		{{"x", math.Inf(1)}},
+	} {
+		if _, err := New("bad", bad); err == nil {
+			t.Errorf("accepted variants %v\n", bad)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+)
+
+// FairShare is a two-level selector for multi-tenant queues.  Next first
+// picks a tenant with probability proportional to its configured share,
+// among the tenants that have items waiting, so that an idle tenant's
+// share is split between the busy ones in proportion to theirs.  It then
+// takes one of that tenant's items, chosen by the items' own weights.
+// Both levels are trees of partial sums, so Push and Next take
+// O(log tenants + log items).  A FairShare is safe for concurrent use.
+type FairShare[K comparable, T any] struct {
+	mu     sync.Mutex
+	rand   *r.Rand
+	index  map[K]int // each tenant's slot in the slices and in active
+	keys   []K
+	shares []float64
+	queues []*bag[T]
+	active *Weighted // a tenant's share while it has items, else 0
+}
+
+// TenantShare is a tenant and its share, for NewFairShare.
+type TenantShare[K comparable] struct {
+	Tenant K
+	Share  float64
+}
+
+// NewFairShare returns a FairShare for the tenants in shares, with no
+// items.  Shares must be finite and non-negative.  Tenants are added in
+// the order given, which with WithSeed makes the sequence from Next
+// reproducible; a later entry for a tenant replaces an earlier one.  A
+// tenant with share 0 is only served when no tenant with a positive share
+// has items, and such tenants are then served in the order they were
+// added.  The options are applied as for Init; only WithSeed matters.
+func NewFairShare[K comparable, T any](shares []TenantShare[K], opts ...Option) (*FairShare[K, T], error) {
+	cfg := newConfig(opts)
+	f := &FairShare[K, T]{
+		rand:   cfg.newRand(),
+		index:  map[K]int{},
+		active: &Weighted{n: bagInitial, leaves: bagInitial, tree: make([]float64, 2*bagInitial)},
+	}
+	for _, ts := range shares {
+		if err := f.SetShare(ts.Tenant, ts.Share); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// SetShare sets a tenant's share, adding the tenant if it is new.
+func (f *FairShare[K, T]) SetShare(tenant K, share float64) error {
+	if !ValidWeight(share) {
+		return &SampleError{"shares must be finite and non-negative"}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.index[tenant]
+	if !ok {
+		i = len(f.keys)
+		if i == f.active.n {
+			f.active.grow(2 * i)
+		}
+		f.index[tenant] = i
+		f.keys = append(f.keys, tenant)
+		f.shares = append(f.shares, 0)
+		f.queues = append(f.queues, newBag[T]())
+	}
+	f.shares[i] = share
+	f.activate(i)
+	return nil
+}
+
+// Push adds an item for tenant with the given weight within the tenant,
+// which must be positive and finite.  It is an error to push for a tenant
+// that has no share.
+func (f *FairShare[K, T]) Push(tenant K, item T, weight float64) error {
+	if !(weight > 0) || math.IsInf(weight, 1) {
+		return &SampleError{"weight must be positive and finite"}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.index[tenant]
+	if !ok {
+		return &SampleError{"tenant has no share"}
+	}
+	f.queues[i].add(item, weight)
+	f.activate(i)
+	return nil
+}
+
+// Next removes and returns an item and its tenant, or reports false if no
+// tenant has any.
+func (f *FairShare[K, T]) Next() (tenant K, item T, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.active.TakeFrom(f.rand)
+	if !ok {
+		/* Only tenants with share 0 can have items left. */
+		for j, q := range f.queues {
+			if q.count > 0 {
+				i, ok = j, true
+				break
+			}
+		}
+		if !ok {
+			return tenant, item, false
+		}
+	}
+	item, _ = f.queues[i].take(f.rand)
+	f.activate(i)
+	return f.keys[i], item, true
+}
+
+// Len returns the number of items waiting across all tenants.
+func (f *FairShare[K, T]) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, q := range f.queues {
+		n += q.count
+	}
+	return n
+}
+
+// Effective returns the share of Next calls each tenant is getting right
+// now: its configured share normalized over the tenants with items
+// waiting.  Tenants without items are left out.
+func (f *FairShare[K, T]) Effective() map[K]float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := map[K]float64{}
+	total := f.active.tree[1]
+	for i, tenant := range f.keys {
+		if f.queues[i].count == 0 {
+			continue
+		}
+		if total == 0 {
+			/* The first tenant with items gets everything. */
+			res[tenant] = 1
+			break
+		}
+		res[tenant] = f.shares[i] / total
+	}
+	return res
+}
+
+/* activate sets tenant i's weight in the tree to its share if it has items
+ * waiting, and to zero if not.  f.mu must be held.
+ */
+func (f *FairShare[K, T]) activate(i int) {
+	weight := 0.0
+	if f.queues[i].count > 0 {
+		weight = f.shares[i]
+	}
+	f.active.set(i, weight)
+}
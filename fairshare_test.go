@@ -0,0 +1,133 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestFairShare(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		shares := rapid.SliceOfN(rapid.Float64Range(0.1, 5.0), 2, 10).Draw(t, "shares")
+		config := make([]TenantShare[int], len(shares))
+		for i, s := range shares {
+			config[i] = TenantShare[int]{i, s}
+		}
+		f, err := NewFairShare[int, int](config, WithSeed(rapid.Int64().Draw(t, "seed")))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Tenant 0 is idle, so its share goes to the others. */
+		per := 20_000
+		var busy float64
+		for tenant := 1; tenant < len(shares); tenant++ {
+			busy += shares[tenant]
+			for i := range per {
+				f.Push(tenant, i, 1)
+			}
+		}
+		want := f.Effective()
+		for tenant := 1; tenant < len(shares); tenant++ {
+			if math.Abs(want[tenant]-shares[tenant]/busy) > 1e-9 {
+				t.Fatalf("effective shares %v for shares %v\n", want, shares)
+			}
+		}
+
+		/* Serve only a fraction so no tenant runs dry. */
+		sz := per / 2
+		counts := map[int]float64{}
+		for range sz {
+			tenant, _, ok := f.Next()
+			if !ok {
+				t.Fatalf("ran out of items\n")
+			}
+			counts[tenant]++
+		}
+		for tenant, w := range want {
+			if got := counts[tenant] / float64(sz); math.Abs(got-w) > 0.02 {
+				t.Fatalf("tenant %d served %v of the time, want %v\n", tenant, got, w)
+			}
+		}
+	})
+}
+
+func TestFairShareItems(t *testing.T) {
+	f, _ := NewFairShare[string, string]([]TenantShare[string]{{"a", 1}, {"b", 0}}, WithSeed(1))
+	if _, _, ok := f.Next(); ok {
+		t.Fatalf("empty selector returned an item\n")
+	}
+	if err := f.Push("c", "x", 1); err == nil {
+		t.Fatalf("push for a tenant without a share was accepted\n")
+	}
+	if err := f.Push("a", "x", 0); err == nil {
+		t.Fatalf("zero item weight was accepted\n")
+	}
+
+	/* Items within a tenant come out by weight, and b, with no share,
+	 * goes last.
+	 */
+	f.Push("b", "b1", 1)
+	for i := range 40 {
+		f.Push("a", string(rune('A'+i)), float64(i+1))
+	}
+	if got := f.Effective(); len(got) != 2 || got["a"] != 1 || got["b"] != 0 {
+		t.Fatalf("effective shares %v\n", got)
+	}
+	sum := 0
+	for i := range 40 {
+		tenant, item, ok := f.Next()
+		if !ok || tenant != "a" {
+			t.Fatalf("item %d: got %s/%s\n", i, tenant, item)
+		}
+		if i < 10 {
+			sum += int(item[0] - 'A')
+		}
+	}
+	/* The first ten picks lean towards the heavier items. */
+	if sum < 10*20 {
+		t.Fatalf("first ten items had mean index %v\n", float64(sum)/10)
+	}
+	if tenant, item, ok := f.Next(); !ok || tenant != "b" || item != "b1" {
+		t.Fatalf("got %s/%s, %v\n", tenant, item, ok)
+	}
+	if f.Len() != 0 {
+		t.Fatalf("Len is %d after draining\n", f.Len())
+	}
+
+	if err := f.SetShare("z", -1); err == nil {
+		t.Fatalf("negative share was accepted\n")
+	}
+	for i := range 40 {
+		f.SetShare(string(rune('a'+i)), 1)
+	}
+	f.Push("z", "last", 1)
+	if tenant, _, _ := f.Next(); tenant != "z" {
+		t.Fatalf("got tenant %s after growing\n", tenant)
+	}
+}
+
+func TestFairShareSeed(t *testing.T) {
+	shares := []TenantShare[string]{{"a", 1}, {"b", 1}, {"c", 1}, {"d", 1}, {"e", 1}, {"f", 1}}
+	run := func() string {
+		f, _ := NewFairShare[string, int](shares, WithSeed(7))
+		for _, ts := range shares {
+			for i := range 10 {
+				f.Push(ts.Tenant, i, 1)
+			}
+		}
+		var seq []byte
+		for range 40 {
+			tenant, _, _ := f.Next()
+			seq = append(seq, tenant[0])
+		}
+		return string(seq)
+	}
+	want := run()
+	for range 5 {
+		if got := run(); got != want {
+			t.Fatalf("seeded runs drew %s and %s\n", want, got)
+		}
+	}
+}
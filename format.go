@@ -0,0 +1,36 @@
+package alias_sample
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Format implements fmt.Formatter.  %v and %s print a one-line summary of
+// the sampler, and %+v prints the whole table as Dump does.
+func (s *AliasSampler) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		s.Dump(f)
+	case verb == 'v' || verb == 's':
+		fmt.Fprintf(f, "AliasSampler{%d indices, %s}", s.n, s.describe())
+	default:
+		fmt.Fprintf(f, "%%!%c(*alias_sample.AliasSampler)", verb)
+	}
+}
+
+// LogValue implements slog.LogValuer, logging the sampler's name, size and
+// how its table is stored rather than the table itself.
+func (s *AliasSampler) LogValue() slog.Value {
+	var attrs []slog.Attr
+	if s.name != "" {
+		attrs = append(attrs, slog.String("name", s.name))
+	}
+	attrs = append(attrs,
+		slog.Int("n", s.n),
+		slog.String("table", s.describe()),
+	)
+	if s.counts != nil {
+		attrs = append(attrs, slog.Uint64("draws", s.Draws()))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,35 @@
+package alias_sample
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	as, _ := Init([]float64{1, 2, 3})
+	if got, want := fmt.Sprintf("%v", as), "AliasSampler{3 indices, float64 probabilities}"; got != want {
+		t.Errorf("%%v: got %q, want %q\n", got, want)
+	}
+	if got := fmt.Sprintf("%s", as); got != fmt.Sprintf("%v", as) {
+		t.Errorf("%%s: got %q\n", got)
+	}
+	var dump strings.Builder
+	as.Dump(&dump)
+	if got := fmt.Sprintf("%+v", as); got != dump.String() {
+		t.Errorf("%%+v: got %q, want the dump %q\n", got, dump.String())
+	}
+	if got := fmt.Sprintf("%d", as); !strings.HasPrefix(got, "%!d(") {
+		t.Errorf("%%d: got %q\n", got)
+	}
+
+	uniform, _ := Init([]float64{2, 2}, WithTracking())
+	uniform.Next()
+	var b bytes.Buffer
+	slog.New(slog.NewTextHandler(&b, nil)).Info("built", "sampler", uniform)
+	if got := b.String(); !strings.Contains(got, `sampler.n=2 sampler.table="uniform, no table" sampler.draws=1`) {
+		t.Errorf("slog: got %q\n", got)
+	}
+}
@@ -0,0 +1,116 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"slices"
+)
+
+// Frozen is an alias table that can never change.  It has none of
+// AliasSampler's mutable state: no random source of its own, no draw
+// counts, no metrics, and no caches filled in on first use; the
+// distribution Prob reports is recovered once, when the table is frozen.
+// So every method is safe to call concurrently, with no locking at all,
+// and a *Frozen can be shared freely by reference.
+//
+// Build the weights with AliasSampler, Dynamic or anything else that
+// yields one, and Freeze the result once it is final.
+type Frozen struct {
+	t *AliasSampler
+}
+
+// InitFrozen builds a Frozen from probs, as Init does.  Options that
+// attach state to draws, WithTracking and WithMetrics, have no effect.
+func InitFrozen(probs []float64, opts ...Option) (*Frozen, error) {
+	s, err := Init(probs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Freeze(), nil
+}
+
+// Freeze returns an immutable copy of the sampler's table, which shares its
+// columns rather than copying them.  It takes O(n) time, to recover the
+// distribution, and must not race with UnmarshalBinary or ReadFrom on s.
+func (s *AliasSampler) Freeze() *Frozen {
+	t := s.copyTable()
+	t.probabilities()
+	return &Frozen{t: t}
+}
+
+// Freeze returns an immutable copy of the table currently in use.
+func (d *Dynamic) Freeze() *Frozen {
+	return d.current.Load().Freeze()
+}
+
+// Next draws an index using math/rand's top-level source, which is safe
+// for concurrent use.
+func (f *Frozen) Next() int {
+	t := f.t
+	if t.mode == modeConstant {
+		return t.only
+	}
+	column := r.Intn(t.n)
+	if t.mode == modeUniform || r.Float64() < t.prob(column) {
+		return column
+	}
+	return t.aliasOf(column)
+}
+
+// NextFrom draws an index using rng.
+func (f *Frozen) NextFrom(rng *r.Rand) int {
+	return f.t.nextFrom(rng)
+}
+
+// NextP draws an index as Next does, and returns it with its normalized
+// probability.
+func (f *Frozen) NextP() (index int, p float64) {
+	i := f.Next()
+	return i, f.t.probabilities()[i]
+}
+
+// NextPFrom is NextP drawing from rng.
+func (f *Frozen) NextPFrom(rng *r.Rand) (index int, p float64) {
+	i := f.t.nextFrom(rng)
+	return i, f.t.probabilities()[i]
+}
+
+// NextNFrom fills dst with independent draws using rng, as NextN does.
+func (f *Frozen) NextNFrom(dst []int, rng *r.Rand) {
+	f.t.nextNFrom(dst, rng)
+}
+
+func (f *Frozen) Len() int {
+	return f.t.n
+}
+
+// Prob returns the normalized probability of drawing index i, or 0 if i is
+// out of range.
+func (f *Frozen) Prob(i int) float64 {
+	if i < 0 || i >= f.t.n {
+		return 0
+	}
+	return f.t.probabilities()[i]
+}
+
+// Probabilities returns a copy of the normalized distribution the table
+// draws from, as AliasSampler.Probabilities does.
+func (f *Frozen) Probabilities() []float64 {
+	return slices.Clone(f.t.probabilities())
+}
+
+// ParentIndex returns the index that i stands for in the sampler the table
+// was derived from by Subset, as AliasSampler.ParentIndex does.
+func (f *Frozen) ParentIndex(i int) int {
+	return f.t.ParentIndex(i)
+}
+
+// Stats returns what was recorded when the table was built.
+func (f *Frozen) Stats() Stats {
+	return f.t.Stats()
+}
+
+// MarshalBinary encodes the table as AliasSampler.MarshalBinary does; Load
+// decodes it, and Freeze makes it immutable again.
+func (f *Frozen) MarshalBinary() ([]byte, error) {
+	return f.t.MarshalBinary()
+}
@@ -0,0 +1,103 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+	"testing"
+
+	"github.com/evanmcc/alias_sample/testutil"
+	"pgregory.net/rapid"
+)
+
+func TestFrozen(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := testutil.Weights().Draw(t, "probs")
+		s, err := Init(probs, WithSeed(1), WithTracking())
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		f := s.Freeze()
+		if f.Len() != s.Len() {
+			t.Fatalf("frozen length %d, want %d\n", f.Len(), s.Len())
+		}
+		for i := -1; i <= s.Len(); i++ {
+			if f.Prob(i) != s.Prob(i) {
+				t.Fatalf("frozen prob %d is %v, want %v\n", i, f.Prob(i), s.Prob(i))
+			}
+		}
+
+		/* The same source draws the same indices, and the frozen table
+		 * counts none of them.
+		 */
+		a, b := r.New(r.NewSource(7)), r.New(r.NewSource(7))
+		for range 100 {
+			if got, want := f.NextFrom(a), s.NextFrom(b); got != want {
+				t.Fatalf("frozen draw %d, want %d\n", got, want)
+			}
+		}
+		if i, p := f.NextP(); p != s.Prob(i) {
+			t.Fatalf("NextP gave p %g for %d, want %g\n", p, i, s.Prob(i))
+		}
+		if i, p := f.NextPFrom(a); p != s.Prob(i) {
+			t.Fatalf("NextPFrom gave p %g for %d, want %g\n", p, i, s.Prob(i))
+		}
+		f.NextNFrom(make([]int, 10), a)
+		if s.Draws() != 100 {
+			t.Fatalf("sampler counted %d draws, want 100\n", s.Draws())
+		}
+
+		/* Replacing the sampler leaves the frozen copy as it was. */
+		other, err := Init([]float64{1, 2})
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		data, err := other.MarshalBinary()
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		want := f.NextFrom(r.New(r.NewSource(3)))
+		if err := s.UnmarshalBinary(data); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if got := f.NextFrom(r.New(r.NewSource(3))); got != want || f.Len() != len(probs) {
+			t.Fatalf("frozen table changed with its sampler\n")
+		}
+	})
+}
+
+func TestFrozenConcurrent(t *testing.T) {
+	probs := []float64{1, 2, 3, 4, 5, 6}
+	f, err := InitFrozen(probs)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	workers, sz := 8, 20_000
+	got := make([][]float64, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[w] = make([]float64, len(probs))
+			for range sz {
+				got[w][f.Next()]++
+				f.Prob(0)
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := float64(workers * sz)
+	for i := range probs {
+		var c float64
+		for w := range workers {
+			c += got[w][i]
+		}
+		p := probs[i] / 21
+		if math.Abs(c-total*p) > 6*math.Sqrt(total*p*(1-p)) {
+			t.Fatalf("drew %d %v times of %v, want p %v\n", i, c, total, p)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+)
+
+/* geometricTail is the most probability mass the table leaves to the tail,
+ * and geometricMaxTable caps the table size for very small p.
+ */
+const (
+	geometricTail     = 1.0 / 1024
+	geometricMaxTable = 1 << 12
+)
+
+// GeometricSampler draws the number of failures before the first success
+// in independent trials that each succeed with probability p, so that
+// P(k) = (1-p)^k p for k = 0, 1, 2, ...
+//
+// The head of the distribution is served from an alias table over 0..m-1
+// plus one extra column for the tail beyond it, where m is chosen so that
+// the tail holds at most about 1/1024 of the mass (m is capped at 4096 for
+// very small p).  A draw that lands in the tail is completed by inversion,
+// which is exact because the geometric distribution is memoryless: past
+// m, the remaining count is again geometric with the same p.
+type GeometricSampler struct {
+	table *AliasSampler
+	m     int
+	logq  float64 // log(1-p)
+}
+
+// NewGeometric returns a geometric sampler with success probability p,
+// which must be in (0, 1].  Counts too large for an int, which only very
+// small p can produce, are returned as math.MaxInt.  Further options are
+// applied to the table as for Init.
+func NewGeometric(p float64, seed int64, opts ...Option) (*GeometricSampler, error) {
+	if !(p > 0 && p <= 1) {
+		return nil, &SampleError{"geometric: p must be in (0, 1]"}
+	}
+
+	logq := math.Log1p(-p)
+	m := geometricMaxTable
+	if logq < math.Log(geometricTail)/geometricMaxTable {
+		m = max(int(math.Ceil(math.Log(geometricTail)/logq)), 1)
+	}
+
+	/* weights[k] = (1-p)^k p for the head; the last column is the tail,
+	 * (1-p)^m.
+	 */
+	weights := make([]float64, m+1)
+	for k := range m {
+		weights[k] = math.Pow(1-p, float64(k)) * p
+	}
+	weights[m] = math.Pow(1-p, float64(m))
+
+	table, err := InitInPlace(weights, append(opts[:len(opts):len(opts)], WithSeed(seed))...)
+	if err != nil {
+		return nil, err
+	}
+	return &GeometricSampler{table: table, m: m, logq: logq}, nil
+}
+
+func (g *GeometricSampler) Next() int {
+	return g.NextFrom(g.table.source())
+}
+
+func (g *GeometricSampler) NextFrom(rng *r.Rand) int {
+	k := g.table.NextFrom(rng)
+	if k < g.m {
+		return k
+	}
+
+	/* Invert the survival function (1-p)^k, using 1-U so the argument
+	 * to Log is never zero.  For very small p the count can pass what an
+	 * int holds, so it saturates at MaxInt.
+	 */
+	u := 1 - rng.Float64()
+	count := float64(g.m) + math.Floor(math.Log(u)/g.logq)
+	if count >= math.MaxInt {
+		return math.MaxInt
+	}
+	return int(count)
+}
@@ -0,0 +1,57 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeometric(t *testing.T) {
+	for _, p := range []float64{0.9, 0.3, 0.01, 1e-4} {
+		g, err := NewGeometric(p, 1)
+		if err != nil {
+			t.Fatalf("p=%g: got err %v\n", p, err)
+		}
+
+		/* Compare the sample mean, which depends on the tail, and the
+		 * head of the distribution against the exact values.
+		 */
+		sz := 200_000
+		counts := make([]int, 3)
+		var sum float64
+		for range sz {
+			k := g.Next()
+			sum += float64(k)
+			if k < len(counts) {
+				counts[k]++
+			}
+		}
+		mean := (1 - p) / p
+		sd := math.Sqrt(1-p) / p
+		if got := sum / float64(sz); math.Abs(got-mean) > 5*sd/math.Sqrt(float64(sz)) {
+			t.Errorf("p=%g: mean %g, want %g\n", p, got, mean)
+		}
+		for k, c := range counts {
+			want := math.Pow(1-p, float64(k)) * p
+			if got := float64(c) / float64(sz); math.Abs(got-want) > 0.005 {
+				t.Errorf("p=%g: P(%d) = %g, want %g\n", p, k, got, want)
+			}
+		}
+	}
+
+	g, _ := NewGeometric(1, 1)
+	if k := g.Next(); k != 0 {
+		t.Errorf("p=1 drew %d\n", k)
+	}
+	/* Almost every draw overflows an int, and saturates. */
+	g, _ = NewGeometric(1e-300, 1)
+	for range 1000 {
+		if k := g.Next(); k < 0 {
+			t.Fatalf("p=1e-300 drew %d\n", k)
+		}
+	}
+	for _, p := range []float64{0, -1, 1.5, math.NaN()} {
+		if _, err := NewGeometric(p, 1); err == nil {
+			t.Errorf("p=%g was accepted\n", p)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// Rander matches gonum's distuv.Rander, so that samplers can be passed to
+// code written against it.  gonum itself is not a dependency.
+type Rander interface {
+	Rand() float64
+}
+
+var (
+	_ Rander = (*AliasSampler)(nil)
+	_ Rander = Categorical{}
+)
+
+// Rand draws an index, as a float64, satisfying distuv.Rander.
+func (s *AliasSampler) Rand() float64 {
+	return float64(s.Next())
+}
+
+// Categorical presents an AliasSampler in the shape of gonum's
+// distuv.Categorical, treating index i as the value float64(i), so it can
+// be used with gonum's statistics tooling as a univariate distribution.
+// Like the AliasSampler methods it wraps, the first query recovers the
+// distribution from the table.
+type Categorical struct {
+	s *AliasSampler
+}
+
+// Categorical returns s as a Categorical.
+func (s *AliasSampler) Categorical() Categorical {
+	return Categorical{s: s}
+}
+
+func (c Categorical) Rand() float64 {
+	return c.s.Rand()
+}
+
+// Prob returns the probability of x, which is 0 unless x is one of the
+// indices.
+func (c Categorical) Prob(x float64) float64 {
+	i, ok := c.index(x)
+	if !ok {
+		return 0
+	}
+	return c.s.Prob(i)
+}
+
+func (c Categorical) LogProb(x float64) float64 {
+	return math.Log(c.Prob(x))
+}
+
+// CDF returns the probability of drawing a value <= x.
+func (c Categorical) CDF(x float64) float64 {
+	switch {
+	case math.IsNaN(x):
+		return math.NaN()
+	case x < 0:
+		return 0
+	case x >= float64(c.s.n-1):
+		return 1
+	}
+	return c.s.CDF(int(x))
+}
+
+// Survival returns the probability of drawing a value > x.
+func (c Categorical) Survival(x float64) float64 {
+	return 1 - c.CDF(x)
+}
+
+// Quantile returns the smallest value whose CDF is at least p.
+func (c Categorical) Quantile(p float64) float64 {
+	return float64(c.s.Quantile(p))
+}
+
+func (c Categorical) Mean() float64 {
+	var mean float64
+	for i, p := range c.s.probabilities() {
+		mean += p * float64(i)
+	}
+	return mean
+}
+
+func (c Categorical) Variance() float64 {
+	mean := c.Mean()
+	var variance float64
+	for i, p := range c.s.probabilities() {
+		d := float64(i) - mean
+		variance += p * d * d
+	}
+	return variance
+}
+
+func (c Categorical) StdDev() float64 {
+	return math.Sqrt(c.Variance())
+}
+
+func (c Categorical) Entropy() float64 {
+	return c.s.Entropy()
+}
+
+func (c Categorical) Len() int {
+	return c.s.n
+}
+
+/* index returns the index x names, if it names one. */
+func (c Categorical) index(x float64) (int, bool) {
+	if x < 0 || x >= float64(c.s.n) || x != math.Trunc(x) {
+		return 0, false
+	}
+	return int(x), true
+}
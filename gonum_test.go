@@ -0,0 +1,55 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCategorical(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 20).Draw(t, "probs")
+		probs[0] += 0.001
+		x := rapid.Float64Range(-2, float64(len(probs)+1)).Draw(t, "x")
+
+		as, _ := Init(probs)
+		c := as.Categorical()
+		dist := tableProbs(as)
+
+		values := make([]float64, len(probs))
+		for i := range values {
+			values[i] = float64(i)
+		}
+		mean, _ := as.Mean(values)
+		variance, _ := as.Variance(values)
+		if math.Abs(c.Mean()-mean) > 1e-9 || math.Abs(c.Variance()-variance) > 1e-9 {
+			t.Fatalf("got mean %g variance %g, want %g and %g\n", c.Mean(), c.Variance(), mean, variance)
+		}
+
+		var want float64
+		for i, p := range dist {
+			if float64(i) <= x {
+				want += p
+			}
+		}
+		if math.Abs(c.CDF(x)-want) > 1e-9 || math.Abs(c.Survival(x)-(1-want)) > 1e-9 {
+			t.Fatalf("CDF(%g): got %g, want %g\n", x, c.CDF(x), want)
+		}
+
+		i := math.Floor(x)
+		if i >= 0 && int(i) < len(probs) {
+			if math.Abs(c.Prob(i)-dist[int(i)]) > 1e-12 {
+				t.Fatalf("Prob(%g): got %g, want %g\n", i, c.Prob(i), dist[int(i)])
+			}
+		}
+		if x != i && c.Prob(x) != 0 {
+			t.Fatalf("Prob(%g): got %g for a non-integer\n", x, c.Prob(x))
+		}
+
+		r := c.Rand()
+		if r != math.Trunc(r) || c.Prob(r) == 0 {
+			t.Fatalf("drew %g\n", r)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"fmt"
+	r "math/rand"
+	"slices"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// ChungLu generates an undirected random graph in which node i has
+// expected degree degrees[i], for benchmarking walks over graphs with a
+// realistic degree distribution.  It uses the Poisson form of the
+// Chung-Lu model: the number of edges is Poisson with mean half the total
+// degree, and both ends of each edge are drawn from an alias table over
+// the degrees, so an edge joins i and j with probability proportional to
+// degrees[i]*degrees[j].  That makes generation linear in the number of
+// edges.  It may produce self-loops and repeated edges; with simple set,
+// they are dropped, which lowers the degrees of the heaviest nodes
+// somewhat.  Each edge is stored in both directions.  Of the options,
+// only WithSeed applies.
+func ChungLu(degrees []float64, simple bool, opts ...Option) (*Graph, error) {
+	cfg := newConfig(opts)
+	var tot float64
+	for _, d := range degrees {
+		if !alias_sample.ValidWeight(d) {
+			return nil, fmt.Errorf("graph: expected degree %v", d)
+		}
+		tot += d
+	}
+	if tot == 0 {
+		offsets := make([]int, len(degrees)+1)
+		return New(offsets, nil, nil)
+	}
+
+	rng := r.New(r.NewSource(cfg.seed))
+	count, err := alias_sample.NewPoisson(tot/2, rng.Int63())
+	if err != nil {
+		return nil, fmt.Errorf("graph: %w", err)
+	}
+	ends, _ := alias_sample.Init(degrees)
+	m := count.Next()
+	src := make([]int, 0, m)
+	dst := make([]int, 0, m)
+	for range m {
+		i, j := ends.NextFrom(rng), ends.NextFrom(rng)
+		if simple && i == j {
+			continue
+		}
+		src = append(src, i)
+		dst = append(dst, j)
+	}
+
+	/* Lay the edges out by source, once each way. */
+	n := len(degrees)
+	offsets := make([]int, n+1)
+	for e := range src {
+		offsets[src[e]+1]++
+		offsets[dst[e]+1]++
+	}
+	for u := range n {
+		offsets[u+1] += offsets[u]
+	}
+	targets := make([]int, offsets[n])
+	fill := slices.Clone(offsets[:n])
+	for e := range src {
+		targets[fill[src[e]]] = dst[e]
+		fill[src[e]]++
+		targets[fill[dst[e]]] = src[e]
+		fill[dst[e]]++
+	}
+	if simple {
+		offsets, targets = dedupe(offsets, targets)
+	}
+	return New(offsets, targets, nil)
+}
+
+/* dedupe sorts each node's targets and drops repeats, compacting the CSR
+ * arrays in place.
+ */
+func dedupe(offsets, targets []int) ([]int, []int) {
+	out := 0
+	for u := range len(offsets) - 1 {
+		ts := targets[offsets[u]:offsets[u+1]]
+		slices.Sort(ts)
+		offsets[u] = out
+		for i, v := range ts {
+			if i == 0 || v != ts[i-1] {
+				targets[out] = v
+				out++
+			}
+		}
+	}
+	offsets[len(offsets)-1] = out
+	return offsets, targets[:out]
+}
@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestChungLu(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		degrees := rapid.SliceOfN(rapid.Float64Range(0, 20), 1, 30).Draw(t, "degrees")
+		simple := rapid.Bool().Draw(t, "simple")
+		seed := rapid.Int64().Draw(t, "seed")
+		g, err := ChungLu(degrees, simple, WithSeed(seed))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if g.Len() != len(degrees) {
+			t.Fatalf("graph has %d nodes, want %d\n", g.Len(), len(degrees))
+		}
+
+		/* Undirected: every edge appears once each way. */
+		edges := map[[2]int]int{}
+		for u := range g.Len() {
+			ts, _ := g.Neighbors(u)
+			for _, v := range ts {
+				if degrees[u] == 0 || degrees[v] == 0 {
+					t.Fatalf("edge %d-%d touches a node of degree 0\n", u, v)
+				}
+				if simple && u == v {
+					t.Fatalf("simple graph has a self-loop at %d\n", u)
+				}
+				edges[[2]int{u, v}]++
+			}
+			if simple && !slices.IsSorted(ts) || simple && len(slices.Compact(slices.Clone(ts))) != len(ts) {
+				t.Fatalf("simple graph has repeated edges at %d: %v\n", u, ts)
+			}
+		}
+		for e, c := range edges {
+			if e[0] != e[1] && edges[[2]int{e[1], e[0]}] != c {
+				t.Fatalf("edge %v appears %d times, but its reverse %d\n", e, c, edges[[2]int{e[1], e[0]}])
+			}
+		}
+
+		again, _ := ChungLu(degrees, simple, WithSeed(seed))
+		if !slices.Equal(g.offsets, again.offsets) || !slices.Equal(g.targets, again.targets) {
+			t.Fatalf("same seed gave a different graph\n")
+		}
+	})
+}
+
+func TestChungLuDegrees(t *testing.T) {
+	degrees := []float64{1, 2, 5, 10, 0, 3}
+	sz := 2000
+	got := make([]float64, len(degrees))
+	for i := range sz {
+		g, _ := ChungLu(degrees, false, WithSeed(int64(i)))
+		for u := range got {
+			got[u] += float64(g.offsets[u+1] - g.offsets[u])
+		}
+	}
+	for u, d := range degrees {
+		/* Degrees are sums of Poisson counts, with self-loops counting
+		 * twice, so the variance is at most twice the mean.
+		 */
+		if math.Abs(got[u]-float64(sz)*d) > 6*math.Sqrt(2*float64(sz)*d)+1 {
+			t.Fatalf("node %d had mean degree %v, want %v\n", u, got[u]/float64(sz), d)
+		}
+	}
+
+	if _, err := ChungLu([]float64{1, -1}, false); err == nil {
+		t.Fatalf("expected error for a negative degree\n")
+	}
+	if g, err := ChungLu([]float64{0, 0}, true); err != nil || g.Edges() != 0 {
+		t.Fatalf("all-zero degrees gave %v, %v\n", g, err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package graph generates weighted random walks over graphs stored in
+// compressed sparse row (CSR) form, with an alias table per node so that
+// each step costs the same however many neighbours a node has.
+package graph
+
+import (
+	"fmt"
+	r "math/rand"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Graph is a directed graph in CSR form: the out-edges of node u are
+// targets[offsets[u]:offsets[u+1]], with the matching weights.  An
+// undirected graph stores each edge in both directions.  A Graph is never
+// modified after construction, so it may be walked from any number of
+// goroutines at once, each with its own rand.Rand.
+type Graph struct {
+	offsets []int
+	targets []int
+	weights []float64
+
+	/* tables[u] picks among u's out-edges, or is nil if they all have
+	 * the same weight, or if u has no out-edge with any weight, in which
+	 * case dead[u] is set.
+	 */
+	tables []*alias_sample.AliasSampler
+	dead   []bool
+}
+
+// New returns a graph over len(offsets)-1 nodes from CSR arrays, which it
+// keeps rather than copies, so they must not be modified afterwards.
+// offsets must start at zero, never decrease, and end at len(targets);
+// weights gives each edge's weight, or nil for an unweighted graph.  A
+// node whose out-edges all have zero weight is a dead end, as is one with
+// none.
+func New(offsets, targets []int, weights []float64) (*Graph, error) {
+	if len(offsets) == 0 || offsets[0] != 0 || offsets[len(offsets)-1] != len(targets) {
+		return nil, fmt.Errorf("graph: offsets must run from 0 to the number of edges")
+	}
+	if weights != nil && len(weights) != len(targets) {
+		return nil, fmt.Errorf("graph: %d weights for %d edges", len(weights), len(targets))
+	}
+	n := len(offsets) - 1
+	for u := range n {
+		if offsets[u+1] < offsets[u] {
+			return nil, fmt.Errorf("graph: offsets decrease at node %d", u)
+		}
+	}
+	for e, v := range targets {
+		if v < 0 || v >= n {
+			return nil, fmt.Errorf("graph: edge %d points to missing node %d", e, v)
+		}
+	}
+	for e, w := range weights {
+		if !alias_sample.ValidWeight(w) {
+			return nil, fmt.Errorf("graph: edge %d has weight %v", e, w)
+		}
+	}
+
+	g := &Graph{offsets: offsets, targets: targets, weights: weights, dead: make([]bool, n)}
+	if weights == nil {
+		for u := range n {
+			g.dead[u] = offsets[u+1] == offsets[u]
+		}
+		return g, nil
+	}
+
+	/* Build every table that needs one in a single BuildMany, so that a
+	 * graph with millions of nodes doesn't make millions of allocations.
+	 */
+	var sets [][]float64
+	var nodes []int
+	for u := range n {
+		ws := weights[offsets[u]:offsets[u+1]]
+		switch {
+		case !anyPositive(ws):
+			g.dead[u] = true
+		case !allEqual(ws):
+			sets = append(sets, ws)
+			nodes = append(nodes, u)
+		}
+	}
+	if len(sets) > 0 {
+		tables, err := alias_sample.BuildMany(sets)
+		if err != nil {
+			return nil, err
+		}
+		g.tables = make([]*alias_sample.AliasSampler, n)
+		for i, u := range nodes {
+			g.tables[u] = tables[i]
+		}
+	}
+	return g, nil
+}
+
+// Len returns the number of nodes.
+func (g *Graph) Len() int {
+	return len(g.offsets) - 1
+}
+
+// Edges returns the number of edges.
+func (g *Graph) Edges() int {
+	return len(g.targets)
+}
+
+// Neighbors returns u's out-edges and their weights, which are nil for an
+// unweighted graph.  The slices share the graph's storage and must not be
+// modified.
+func (g *Graph) Neighbors(u int) (targets []int, weights []float64) {
+	lo, hi := g.offsets[u], g.offsets[u+1]
+	if g.weights != nil {
+		weights = g.weights[lo:hi:hi]
+	}
+	return g.targets[lo:hi:hi], weights
+}
+
+// Step returns a neighbour of u, chosen with probability proportional to
+// the weight of the edge to it, or false if u is a dead end.
+func (g *Graph) Step(u int, rng *r.Rand) (int, bool) {
+	e, ok := g.edge(u, rng)
+	if !ok {
+		return 0, false
+	}
+	return g.targets[e], true
+}
+
+// Walk returns a walk of up to length nodes beginning at start.  It is
+// shorter only if it reaches a dead end.
+func (g *Graph) Walk(start, length int, rng *r.Rand) []int {
+	if length <= 0 {
+		return nil
+	}
+	return g.walk(make([]int, 0, length), start, rng)
+}
+
+/* walk appends a walk from start to dst, until dst is full or the walk
+ * reaches a dead end.
+ */
+func (g *Graph) walk(dst []int, start int, rng *r.Rand) []int {
+	dst = append(dst, start)
+	for u := start; len(dst) < cap(dst); {
+		v, ok := g.Step(u, rng)
+		if !ok {
+			break
+		}
+		dst = append(dst, v)
+		u = v
+	}
+	return dst
+}
+
+/* edge picks one of u's out-edges, returning its index in targets. */
+func (g *Graph) edge(u int, rng *r.Rand) (int, bool) {
+	if g.dead[u] {
+		return 0, false
+	}
+	lo := g.offsets[u]
+	if g.tables != nil && g.tables[u] != nil {
+		return lo + g.tables[u].NextFrom(rng), true
+	}
+	return lo + rng.Intn(g.offsets[u+1]-lo), true
+}
+
+/* weight returns edge e's weight, which is 1 in an unweighted graph. */
+func (g *Graph) weight(e int) float64 {
+	if g.weights == nil {
+		return 1
+	}
+	return g.weights[e]
+}
+
+func anyPositive(ws []float64) bool {
+	for _, w := range ws {
+		if w > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func allEqual(ws []float64) bool {
+	for _, w := range ws[1:] {
+		if w != ws[0] {
+			return false
+		}
+	}
+	return true
+}
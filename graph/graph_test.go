@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"math"
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+/* randomGraph draws a small weighted CSR graph, with some dead ends and
+ * some nodes whose out-edges share one weight.
+ */
+func randomGraph(t *rapid.T) (offsets, targets []int, weights []float64) {
+	n := rapid.IntRange(1, 8).Draw(t, "n")
+	offsets = []int{0}
+	for range n {
+		deg := rapid.IntRange(0, 5).Draw(t, "deg")
+		equal := rapid.Bool().Draw(t, "equal")
+		for range deg {
+			targets = append(targets, rapid.IntRange(0, n-1).Draw(t, "target"))
+			if equal {
+				weights = append(weights, 1)
+			} else {
+				weights = append(weights, rapid.Float64Range(0, 3).Draw(t, "weight"))
+			}
+		}
+		offsets = append(offsets, len(targets))
+	}
+	return offsets, targets, weights
+}
+
+func TestStep(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		offsets, targets, weights := randomGraph(t)
+		if rapid.Bool().Draw(t, "unweighted") {
+			weights = nil
+		}
+		g, err := New(offsets, targets, weights)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		rng := r.New(r.NewSource(1))
+		sz := 20_000
+		for u := range g.Len() {
+			ts, ws := g.Neighbors(u)
+			var tot float64
+			want := map[int]float64{}
+			for e, v := range ts {
+				w := 1.0
+				if ws != nil {
+					w = ws[e]
+				}
+				want[v] += w
+				tot += w
+			}
+			if tot == 0 {
+				if v, ok := g.Step(u, rng); ok {
+					t.Fatalf("stepped from dead end %d to %d\n", u, v)
+				}
+				continue
+			}
+			got := map[int]float64{}
+			for range sz {
+				v, ok := g.Step(u, rng)
+				if !ok {
+					t.Fatalf("no step from %d, which has edges\n", u)
+				}
+				got[v]++
+			}
+			for v, c := range got {
+				if want[v] == 0 {
+					t.Fatalf("stepped from %d to %d along no edge\n", u, v)
+				}
+				p := want[v] / tot
+				if math.Abs(c-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+					t.Fatalf("stepped %d->%d %v times of %d, want p %v\n", u, v, c, sz, p)
+				}
+			}
+		}
+	})
+}
+
+func TestWalkDeadEnd(t *testing.T) {
+	/* 0 -> 1 -> 2, and 2 has only a zero-weight edge back. */
+	g, err := New([]int{0, 1, 2, 3}, []int{1, 2, 0}, []float64{1, 1, 0})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	walk := g.Walk(0, 10, r.New(r.NewSource(1)))
+	if len(walk) != 3 || walk[0] != 0 || walk[1] != 1 || walk[2] != 2 {
+		t.Fatalf("walk %v did not stop at the dead end\n", walk)
+	}
+	if walk := g.Walk(0, 2, r.New(r.NewSource(1))); len(walk) != 2 {
+		t.Fatalf("walk %v is not 2 long\n", walk)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	cases := map[string]struct {
+		offsets, targets []int
+		weights          []float64
+	}{
+		"no offsets":      {nil, nil, nil},
+		"bad first":       {[]int{1, 1}, []int{0}, nil},
+		"bad last":        {[]int{0, 1}, []int{0, 0}, nil},
+		"decreasing":      {[]int{0, 2, 1, 2}, []int{0, 0}, nil},
+		"missing target":  {[]int{0, 1}, []int{1}, nil},
+		"weights length":  {[]int{0, 1}, []int{0}, []float64{1, 1}},
+		"negative weight": {[]int{0, 1}, []int{0}, []float64{-1}},
+		"nan weight":      {[]int{0, 1}, []int{0}, []float64{math.NaN()}},
+	}
+	for name, c := range cases {
+		if _, err := New(c.offsets, c.targets, c.weights); err == nil {
+			t.Fatalf("%s: expected error\n", name)
+		}
+	}
+}
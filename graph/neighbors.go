@@ -0,0 +1,158 @@
+package graph
+
+import (
+	r "math/rand"
+	"slices"
+	"sync"
+)
+
+/* neighborTries is how many draws SampleKNeighbors makes for each pick
+ * without replacement, rejecting edges already picked, before it falls
+ * back to scanning the edges that are left.
+ */
+const neighborTries = 16
+
+// NeighborSampler samples the neighbours of nodes, as for GNN-style
+// mini-batch training.  It draws from the per-node tables of a Graph, so
+// one neighbour costs a couple of random numbers however high the node's
+// degree.  All methods are safe for concurrent use; those without an rng
+// draw from a pool of random sources.
+type NeighborSampler struct {
+	g     *Graph
+	rands sync.Pool
+
+	/* live counts each node's edges with positive weight, or is nil in
+	 * an unweighted graph, where every edge counts.
+	 */
+	live []int
+}
+
+// NewNeighborSampler returns a sampler over the graph New would build
+// from the same CSR arrays.
+func NewNeighborSampler(offsets, targets []int, weights []float64) (*NeighborSampler, error) {
+	g, err := New(offsets, targets, weights)
+	if err != nil {
+		return nil, err
+	}
+	ns := &NeighborSampler{g: g}
+	ns.rands.New = func() any {
+		return r.New(r.NewSource(r.Int63()))
+	}
+	if weights != nil {
+		ns.live = make([]int, g.Len())
+		for u := range g.Len() {
+			for _, w := range weights[offsets[u]:offsets[u+1]] {
+				if w > 0 {
+					ns.live[u]++
+				}
+			}
+		}
+	}
+	return ns, nil
+}
+
+// Graph returns the graph the sampler draws from.
+func (ns *NeighborSampler) Graph() *Graph {
+	return ns.g
+}
+
+// SampleNeighbor returns a neighbour of u, chosen in proportion to the
+// weight of the edge to it, or false if u has no edge with any weight.
+func (ns *NeighborSampler) SampleNeighbor(u int) (int, bool) {
+	rng := ns.rands.Get().(*r.Rand)
+	v, ok := ns.g.Step(u, rng)
+	ns.rands.Put(rng)
+	return v, ok
+}
+
+// SampleKNeighbors returns k neighbours of u.  With replacement, they are
+// k independent draws, or none if u has no edge with any weight.
+// Without, they are drawn one by one from the edges not yet drawn, as a
+// weighted shuffle would order them; if u has no more than k edges with
+// weight, all of them are returned, in no particular order.  Edges, not
+// neighbours, are distinct, so a neighbour with two edges to it may be
+// returned twice.
+func (ns *NeighborSampler) SampleKNeighbors(u, k int, replace bool) []int {
+	rng := ns.rands.Get().(*r.Rand)
+	res := ns.AppendKNeighbors(nil, u, k, replace, rng)
+	ns.rands.Put(rng)
+	return res
+}
+
+// AppendKNeighbors is SampleKNeighbors appending to dst and drawing from
+// rng, which must not be used concurrently, so that a caller filling a
+// mini-batch can reuse one slice and source.
+func (ns *NeighborSampler) AppendKNeighbors(dst []int, u, k int, replace bool, rng *r.Rand) []int {
+	g := ns.g
+	if k <= 0 || g.dead[u] {
+		return dst
+	}
+	if replace {
+		for range k {
+			e, _ := g.edge(u, rng)
+			dst = append(dst, g.targets[e])
+		}
+		return dst
+	}
+
+	lo, hi := g.offsets[u], g.offsets[u+1]
+	live := hi - lo
+	if ns.live != nil {
+		live = ns.live[u]
+	}
+	if k >= live {
+		for e := lo; e < hi; e++ {
+			if g.weight(e) > 0 {
+				dst = append(dst, g.targets[e])
+			}
+		}
+		return dst
+	}
+
+	/* Make each pick from the table, rejecting edges already picked, and
+	 * only if that keeps failing scan what's left.  Either way a pick is
+	 * distributed as if the picked edges had been removed.
+	 */
+	picked := make([]int, 0, k)
+	for range k {
+		e, found := -1, false
+		for range neighborTries {
+			e, _ = g.edge(u, rng)
+			if !slices.Contains(picked, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			e = ns.remaining(lo, hi, picked, rng)
+		}
+		picked = append(picked, e)
+		dst = append(dst, g.targets[e])
+	}
+	return dst
+}
+
+/* remaining draws one of the edges from lo to hi that isn't in picked, in
+ * proportion to its weight.
+ */
+func (ns *NeighborSampler) remaining(lo, hi int, picked []int, rng *r.Rand) int {
+	var tot float64
+	for e := lo; e < hi; e++ {
+		if !slices.Contains(picked, e) {
+			tot += ns.g.weight(e)
+		}
+	}
+	x := rng.Float64() * tot
+	last := -1
+	for e := lo; e < hi; e++ {
+		if w := ns.g.weight(e); w > 0 && !slices.Contains(picked, e) {
+			if x < w {
+				return e
+			}
+			x -= w
+			last = e
+		}
+	}
+	/* Rounding can leave x just past the end. */
+	return last
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"math"
+	r "math/rand"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestSampleKNeighbors(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		offsets, targets, weights := randomGraph(t)
+		ns, err := NewNeighborSampler(offsets, targets, weights)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		u := rapid.IntRange(0, ns.Graph().Len()-1).Draw(t, "u")
+		k := rapid.IntRange(0, 6).Draw(t, "k")
+		lo, hi := offsets[u], offsets[u+1]
+		live := 0
+		for _, w := range weights[lo:hi] {
+			if w > 0 {
+				live++
+			}
+		}
+
+		with := ns.SampleKNeighbors(u, k, true)
+		if live > 0 && len(with) != k || live == 0 && len(with) != 0 {
+			t.Fatalf("drew %d with replacement, want %d, with %d live edges\n", len(with), k, live)
+		}
+		without := ns.SampleKNeighbors(u, k, false)
+		if len(without) != min(k, live) {
+			t.Fatalf("drew %d without replacement, want %d\n", len(without), min(k, live))
+		}
+
+		/* Every neighbour drawn without replacement uses up one of the
+		 * edges with weight to it.
+		 */
+		left := map[int]int{}
+		for e := lo; e < hi; e++ {
+			if weights[e] > 0 {
+				left[targets[e]]++
+			}
+		}
+		for _, v := range slices.Concat(with, without) {
+			if left[v] == 0 {
+				t.Fatalf("drew %d, which has no edge with weight from %d\n", v, u)
+			}
+		}
+		for _, v := range without {
+			if left[v]--; left[v] < 0 {
+				t.Fatalf("drew %d more times than it has edges\n", v)
+			}
+		}
+	})
+}
+
+func TestSampleKNeighborsWithoutReplacement(t *testing.T) {
+	/* A heavy edge makes rejection fail often, exercising the fallback.
+	 * Drawing 2 of 3 edges, the first pick goes by weight and the second
+	 * by weight among the rest, so the chance of leaving out edge i
+	 * follows directly.
+	 */
+	weights := []float64{100, 1, 3}
+	ns, _ := NewNeighborSampler([]int{0, 3, 3, 3, 3}, []int{1, 2, 3}, weights)
+	rng := r.New(r.NewSource(1))
+	sz := 50_000
+	missing := make([]float64, 4)
+	var buf []int
+	for range sz {
+		buf = ns.AppendKNeighbors(buf[:0], 0, 2, false, rng)
+		if len(buf) != 2 || buf[0] == buf[1] {
+			t.Fatalf("drew %v\n", buf)
+		}
+		missing[6-buf[0]-buf[1]]++
+	}
+	tot := 104.0
+	for i, w := range weights {
+		/* Edge i is left out if the other two are picked, in either
+		 * order.
+		 */
+		var p float64
+		for j, wj := range weights {
+			if j != i {
+				p += wj / tot * (tot - wj - w) / (tot - wj)
+			}
+		}
+		c := missing[i+1]
+		if math.Abs(c-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+			t.Fatalf("left out edge %d %v times of %d, want p %v\n", i, c, sz, p)
+		}
+	}
+
+	if v, ok := ns.SampleNeighbor(1); ok {
+		t.Fatalf("sampled %d from a node with no edges\n", v)
+	}
+	if got := ns.SampleKNeighbors(0, 5, false); len(got) != 3 {
+		t.Fatalf("drew %v without replacement, want every edge\n", got)
+	}
+}
@@ -0,0 +1,228 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	r "math/rand"
+	"slices"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Node2Vec generates node2vec's second-order biased walks.  Having come
+// to v from t, the walk moves on to each neighbour x of v in proportion
+// to the edge's weight times a bias: 1/p if x is t, 1 if x is also a
+// neighbour of t, and 1/q otherwise.  Small p keeps walks close to home;
+// small q sends them outward.
+//
+// NewNode2Vec precomputes an alias table for every edge, which makes
+// each step as cheap as a first-order one but takes memory for every
+// pair of an edge and a neighbour of its target; see Node2VecSize.
+// NewNode2VecOnDemand instead keeps only a sorted copy of the adjacency
+// and draws each step by rejection from the first-order tables, which
+// costs a few extra draws per step when p or q is far from 1.  Either
+// way a Node2Vec, like its Graph, is safe for concurrent walks.
+type Node2Vec struct {
+	g    *Graph
+	p, q float64
+
+	/* edges[e] picks the next edge after edge e, relative to the start
+	 * of its target's edges, or is nil if every next edge is equally
+	 * likely.  It is nil for the on-demand variant.
+	 */
+	edges []*alias_sample.AliasSampler
+
+	/* sorted holds each node's targets in order, for the on-demand
+	 * variant's neighbour test.
+	 */
+	sorted []int
+}
+
+// NewNode2Vec returns a node2vec walker over g with every edge's table
+// precomputed.
+func NewNode2Vec(g *Graph, p, q float64) (*Node2Vec, error) {
+	nv, err := newNode2Vec(g, p, q)
+	if err != nil {
+		return nil, err
+	}
+
+	/* Mark t's neighbours once, then bias the onward edges of each of
+	 * t's out-edges against them.
+	 */
+	mark := make([]int, g.Len())
+	for i := range mark {
+		mark[i] = -1
+	}
+	var sets [][]float64
+	var edges []int
+	for t := range g.Len() {
+		ts, _ := g.Neighbors(t)
+		for _, x := range ts {
+			mark[x] = t
+		}
+		for e := g.offsets[t]; e < g.offsets[t+1]; e++ {
+			v := g.targets[e]
+			if g.dead[v] {
+				continue
+			}
+			ws := make([]float64, g.offsets[v+1]-g.offsets[v])
+			for i := range ws {
+				x := g.targets[g.offsets[v]+i]
+				ws[i] = g.weight(g.offsets[v]+i) * nv.bias(t, x, mark[x] == t)
+			}
+			if !allEqual(ws) {
+				sets = append(sets, ws)
+				edges = append(edges, e)
+			}
+		}
+	}
+	nv.edges = make([]*alias_sample.AliasSampler, g.Edges())
+	if len(sets) > 0 {
+		tables, err := alias_sample.BuildMany(sets)
+		if err != nil {
+			return nil, err
+		}
+		for i, e := range edges {
+			nv.edges[e] = tables[i]
+		}
+	}
+	return nv, nil
+}
+
+// NewNode2VecOnDemand returns a node2vec walker over g that keeps no
+// per-edge tables.
+func NewNode2VecOnDemand(g *Graph, p, q float64) (*Node2Vec, error) {
+	nv, err := newNode2Vec(g, p, q)
+	if err != nil {
+		return nil, err
+	}
+	nv.sorted = slices.Clone(g.targets)
+	for u := range g.Len() {
+		slices.Sort(nv.sorted[g.offsets[u]:g.offsets[u+1]])
+	}
+	return nv, nil
+}
+
+func newNode2Vec(g *Graph, p, q float64) (*Node2Vec, error) {
+	if !(p > 0 && q > 0) || math.IsInf(p, 1) || math.IsInf(q, 1) {
+		return nil, fmt.Errorf("graph: node2vec needs positive, finite p and q, not %v and %v", p, q)
+	}
+	return &Node2Vec{g: g, p: p, q: q}, nil
+}
+
+// Node2VecSize returns how many entries NewNode2Vec's tables would hold
+// for g: one for each pair of an edge and an out-edge of its target.
+func Node2VecSize(g *Graph) int {
+	size := 0
+	for _, v := range g.targets {
+		size += g.offsets[v+1] - g.offsets[v]
+	}
+	return size
+}
+
+// Step returns the node after cur, having come to it from prev, or false
+// if cur is a dead end or there is no edge from prev to cur.
+func (nv *Node2Vec) Step(prev, cur int, rng *r.Rand) (int, bool) {
+	e, ok := nv.next(prev, cur, rng)
+	if !ok {
+		return 0, false
+	}
+	return nv.g.targets[e], true
+}
+
+// Walk returns a walk of up to length nodes beginning at start.  Its
+// first step is a first-order one, as there is nowhere to return to.
+func (nv *Node2Vec) Walk(start, length int, rng *r.Rand) []int {
+	if length <= 0 {
+		return nil
+	}
+	return nv.walk(make([]int, 0, length), start, rng)
+}
+
+// Walks generates a batch of walks as Graph.Walks does.
+func (nv *Node2Vec) Walks(count, length int, opts ...Option) ([][]int, error) {
+	return walks(nv.g.Len(), count, length, newConfig(opts), nv.walk)
+}
+
+func (nv *Node2Vec) walk(dst []int, start int, rng *r.Rand) []int {
+	dst = append(dst, start)
+	if len(dst) == cap(dst) {
+		return dst
+	}
+	prev := start
+	e, ok := nv.g.edge(start, rng)
+	for ok {
+		dst = append(dst, nv.g.targets[e])
+		if len(dst) == cap(dst) {
+			break
+		}
+		e, ok = nv.after(prev, e, rng)
+		prev = dst[len(dst)-1]
+	}
+	return dst
+}
+
+/* next picks the edge out of cur after arriving from prev.  With tables,
+ * this means finding the edge prev->cur first.
+ */
+func (nv *Node2Vec) next(prev, cur int, rng *r.Rand) (int, bool) {
+	if nv.edges == nil {
+		ts := nv.sorted[nv.g.offsets[prev]:nv.g.offsets[prev+1]]
+		if _, ok := slices.BinarySearch(ts, cur); !ok {
+			return 0, false
+		}
+		return nv.reject(prev, cur, rng)
+	}
+	ts, _ := nv.g.Neighbors(prev)
+	i := slices.Index(ts, cur)
+	if i < 0 {
+		return 0, false
+	}
+	return nv.after(prev, nv.g.offsets[prev]+i, rng)
+}
+
+/* after picks the edge to take after edge e, which leaves prev. */
+func (nv *Node2Vec) after(prev, e int, rng *r.Rand) (int, bool) {
+	if nv.edges == nil {
+		return nv.reject(prev, nv.g.targets[e], rng)
+	}
+	v := nv.g.targets[e]
+	if nv.g.dead[v] {
+		return 0, false
+	}
+	lo := nv.g.offsets[v]
+	if t := nv.edges[e]; t != nil {
+		return lo + t.NextFrom(rng), true
+	}
+	return lo + rng.Intn(nv.g.offsets[v+1]-lo), true
+}
+
+/* reject draws the edge out of cur from its first-order table and keeps
+ * it with probability proportional to its bias, until one is kept.
+ */
+func (nv *Node2Vec) reject(prev, cur int, rng *r.Rand) (int, bool) {
+	top := max(1/nv.p, 1, 1/nv.q)
+	for {
+		e, ok := nv.g.edge(cur, rng)
+		if !ok {
+			return 0, false
+		}
+		x := nv.g.targets[e]
+		ts := nv.sorted[nv.g.offsets[prev]:nv.g.offsets[prev+1]]
+		_, adjacent := slices.BinarySearch(ts, x)
+		if rng.Float64()*top < nv.bias(prev, x, adjacent) {
+			return e, true
+		}
+	}
+}
+
+func (nv *Node2Vec) bias(prev, x int, adjacent bool) float64 {
+	switch {
+	case x == prev:
+		return 1 / nv.p
+	case adjacent:
+		return 1
+	default:
+		return 1 / nv.q
+	}
+}
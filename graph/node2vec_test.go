@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"math"
+	r "math/rand"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestNode2Vec(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		offsets, targets, weights := randomGraph(t)
+		if rapid.Bool().Draw(t, "unweighted") {
+			weights = nil
+		}
+		g, _ := New(offsets, targets, weights)
+		if g.Edges() == 0 {
+			return
+		}
+		p := rapid.Float64Range(0.25, 4).Draw(t, "p")
+		q := rapid.Float64Range(0.25, 4).Draw(t, "q")
+		tables, err := NewNode2Vec(g, p, q)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		lazy, err := NewNode2VecOnDemand(g, p, q)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Work out the biased distribution after one edge by hand, and
+		 * check both variants against it.
+		 */
+		e := rapid.IntRange(0, g.Edges()-1).Draw(t, "edge")
+		prev := slices.IndexFunc(offsets[1:], func(o int) bool { return o > e })
+		cur := targets[e]
+		prevNeighbors, _ := g.Neighbors(prev)
+		want := map[int]float64{}
+		var tot float64
+		for i, x := range targets[offsets[cur]:offsets[cur+1]] {
+			w := g.weight(offsets[cur] + i)
+			switch {
+			case x == prev:
+				w /= p
+			case !slices.Contains(prevNeighbors, x):
+				w /= q
+			}
+			want[x] += w
+			tot += w
+		}
+
+		rng := r.New(r.NewSource(1))
+		sz := 20_000
+		for name, nv := range map[string]*Node2Vec{"tables": tables, "on demand": lazy} {
+			got := map[int]float64{}
+			for range sz {
+				x, ok := nv.Step(prev, cur, rng)
+				if ok != (tot > 0) {
+					t.Fatalf("%s: step from %d->%d ok %v with weight %v\n", name, prev, cur, ok, tot)
+				}
+				if !ok {
+					break
+				}
+				got[x]++
+			}
+			for x, c := range got {
+				pr := want[x] / tot
+				if pr == 0 {
+					t.Fatalf("%s: stepped %d->%d->%d along no edge\n", name, prev, cur, x)
+				}
+				if math.Abs(c-float64(sz)*pr) > 6*math.Sqrt(float64(sz)*pr*(1-pr))+1 {
+					t.Fatalf("%s: stepped %d->%d->%d %v times of %d, want p %v\n", name, prev, cur, x, c, sz, pr)
+				}
+			}
+
+			ws, err := nv.Walks(200, 10, WithSeed(2))
+			if err != nil {
+				t.Fatalf("%s: got err %v\n", name, err)
+			}
+			for _, w := range ws {
+				for i := 1; i < len(w); i++ {
+					ts, _ := g.Neighbors(w[i-1])
+					if !slices.Contains(ts, w[i]) {
+						t.Fatalf("%s: walk %v steps along a missing edge\n", name, w)
+					}
+				}
+			}
+		}
+	})
+}
+
+func TestNode2VecReturn(t *testing.T) {
+	/* On a path 0 - 1 - 2, a tiny p makes walks from 1 bounce straight
+	 * back to where they came from.
+	 */
+	g, _ := New([]int{0, 1, 3, 4}, []int{1, 0, 2, 1}, nil)
+	for _, mk := range []func(*Graph, float64, float64) (*Node2Vec, error){NewNode2Vec, NewNode2VecOnDemand} {
+		nv, _ := mk(g, 1e-6, 1)
+		rng := r.New(r.NewSource(1))
+		back := 0
+		for range 1000 {
+			if x, _ := nv.Step(0, 1, rng); x == 0 {
+				back++
+			}
+		}
+		if back < 990 {
+			t.Fatalf("returned %d times of 1000\n", back)
+		}
+		if _, ok := nv.Step(0, 2, rng); ok {
+			t.Fatalf("stepped after a missing edge\n")
+		}
+	}
+
+	if _, err := NewNode2Vec(g, 0, 1); err == nil {
+		t.Fatalf("expected error for p = 0\n")
+	}
+	if _, err := NewNode2VecOnDemand(g, 1, math.Inf(1)); err == nil {
+		t.Fatalf("expected error for infinite q\n")
+	}
+	if size := Node2VecSize(g); size != 6 {
+		t.Fatalf("node2vec size %d, want 6\n", size)
+	}
+}
@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"fmt"
+	r "math/rand"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+/* visitRound is how many walks Visits generates at a time, to bound its
+ * memory however many walks it is asked for.
+ */
+const visitRound = 1 << 12
+
+// Restart generates random walks with restart, as used to estimate
+// personalized PageRank: at every step the walk jumps back to a node
+// drawn from a seed distribution with probability alpha, and otherwise
+// takes a weighted step.  A walk at a dead end always restarts.  Like its
+// Graph, a Restart is safe for concurrent walks.
+type Restart struct {
+	g     *Graph
+	alpha float64
+	seeds *alias_sample.AliasSampler
+	probs []float64
+}
+
+// NewRestart returns a walker over g that restarts with probability alpha
+// to a node drawn in proportion to seeds, which has one weight per node.
+func NewRestart(g *Graph, alpha float64, seeds []float64) (*Restart, error) {
+	if !(alpha >= 0 && alpha <= 1) {
+		return nil, fmt.Errorf("graph: restart probability %v is not between 0 and 1", alpha)
+	}
+	if len(seeds) != g.Len() {
+		return nil, fmt.Errorf("graph: %d seed weights for %d nodes", len(seeds), g.Len())
+	}
+	for _, w := range seeds {
+		if !alias_sample.ValidWeight(w) {
+			return nil, fmt.Errorf("graph: seed weight %v", w)
+		}
+	}
+	if !anyPositive(seeds) {
+		return nil, fmt.Errorf("graph: seed weights are all zero")
+	}
+	s, _ := alias_sample.Init(seeds)
+	return &Restart{g: g, alpha: alpha, seeds: s, probs: seeds}, nil
+}
+
+// Step returns the node after u: a seed node if the walk restarts, or
+// one of u's neighbours otherwise.
+func (rw *Restart) Step(u int, rng *r.Rand) int {
+	if rng.Float64() >= rw.alpha {
+		if v, ok := rw.g.Step(u, rng); ok {
+			return v
+		}
+	}
+	return rw.seeds.NextFrom(rng)
+}
+
+// Walk returns a walk of length nodes, beginning at a seed node.
+func (rw *Restart) Walk(length int, rng *r.Rand) []int {
+	if length <= 0 {
+		return nil
+	}
+	return rw.walk(make([]int, 0, length), rw.seeds.NextFrom(rng), rng)
+}
+
+// Walks generates a batch of walks as Graph.Walks does, except that they
+// begin at seed nodes unless WithStarts says otherwise.
+func (rw *Restart) Walks(count, length int, opts ...Option) ([][]int, error) {
+	cfg := newConfig(opts)
+	if cfg.starts == nil {
+		cfg.starts = rw.probs
+	}
+	return walks(rw.g.Len(), count, length, cfg, rw.walk)
+}
+
+// Visits estimates how often a long walk with restart visits each node,
+// which is the personalized PageRank of the seed distribution, from the
+// visits made by count walks of length nodes.  The estimate is biased
+// towards the seeds when walks are not much longer than 1/alpha steps.
+// It takes the options Walks does.
+func (rw *Restart) Visits(count, length int, opts ...Option) ([]float64, error) {
+	cfg := newConfig(opts)
+	if cfg.starts == nil {
+		cfg.starts = rw.probs
+	}
+	if count <= 0 || length <= 0 {
+		return nil, fmt.Errorf("graph: cannot estimate visits from %d walks of length %d", count, length)
+	}
+
+	/* Each round gets its own seed, so the estimate still doesn't depend
+	 * on the number of workers.
+	 */
+	visits := make([]float64, rw.g.Len())
+	var total float64
+	rng := r.New(r.NewSource(cfg.seed))
+	for done := 0; done < count; done += visitRound {
+		round := *cfg
+		round.seed = rng.Int63()
+		ws, err := walks(rw.g.Len(), min(visitRound, count-done), length, &round, rw.walk)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range ws {
+			for _, u := range w {
+				visits[u]++
+			}
+			total += float64(len(w))
+		}
+	}
+	for u := range visits {
+		visits[u] /= total
+	}
+	return visits, nil
+}
+
+func (rw *Restart) walk(dst []int, start int, rng *r.Rand) []int {
+	dst = append(dst, start)
+	for u := start; len(dst) < cap(dst); {
+		u = rw.Step(u, rng)
+		dst = append(dst, u)
+	}
+	return dst
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"math"
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestRestartVisits(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		offsets, targets, weights := randomGraph(t)
+		g, _ := New(offsets, targets, weights)
+		n := g.Len()
+		alpha := rapid.Float64Range(0.1, 0.9).Draw(t, "alpha")
+		seeds := rapid.SliceOfN(rapid.Float64Range(0, 1), n, n).Draw(t, "seeds")
+		seeds[rapid.IntRange(0, n-1).Draw(t, "seed")] = 1
+		rw, err := NewRestart(g, alpha, seeds)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Find the personalized PageRank by power iteration. */
+		var seedTot float64
+		for _, s := range seeds {
+			seedTot += s
+		}
+		pr := make([]float64, n)
+		for u := range pr {
+			pr[u] = 1 / float64(n)
+		}
+		for range 200 {
+			next := make([]float64, n)
+			for u, mass := range pr {
+				ts, _ := g.Neighbors(u)
+				var tot float64
+				for e := range ts {
+					tot += g.weight(offsets[u] + e)
+				}
+				restart := alpha
+				if tot == 0 {
+					restart = 1
+				}
+				for v, s := range seeds {
+					next[v] += mass * restart * s / seedTot
+				}
+				for e, v := range ts {
+					next[v] += mass * (1 - restart) * g.weight(offsets[u]+e) / tot
+				}
+			}
+			pr = next
+		}
+
+		visits, err := rw.Visits(200, 5000, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		for u := range pr {
+			if math.Abs(visits[u]-pr[u]) > 0.01 {
+				t.Fatalf("node %d visited %v of the time, want %v\n", u, visits[u], pr[u])
+			}
+		}
+	})
+}
+
+func TestRestartWalk(t *testing.T) {
+	/* With alpha 1, every step is a restart to node 2. */
+	g, _ := New([]int{0, 1, 2, 3}, []int{1, 2, 0}, nil)
+	rw, _ := NewRestart(g, 1, []float64{0, 0, 1})
+	for _, u := range rw.Walk(20, r.New(r.NewSource(1))) {
+		if u != 2 {
+			t.Fatalf("walk left the seed for %d\n", u)
+		}
+	}
+	ws, err := rw.Walks(10, 5, WithSeed(1))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for _, w := range ws {
+		if len(w) != 5 || w[0] != 2 {
+			t.Fatalf("walk %v did not start at the seed\n", w)
+		}
+	}
+
+	for name, c := range map[string]struct {
+		alpha float64
+		seeds []float64
+	}{
+		"alpha":    {1.5, []float64{1, 1, 1}},
+		"length":   {0.5, []float64{1, 1}},
+		"zero":     {0.5, []float64{0, 0, 0}},
+		"negative": {0.5, []float64{1, -1, 1}},
+	} {
+		if _, err := NewRestart(g, c.alpha, c.seeds); err == nil {
+			t.Fatalf("%s: expected error\n", name)
+		}
+	}
+	if _, err := rw.Visits(0, 10); err == nil {
+		t.Fatalf("expected error for no walks\n")
+	}
+}
@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	r "math/rand"
+	"slices"
+	"sort"
+)
+
+// Temporal is a directed graph whose edges carry timestamps, for
+// time-respecting walks as used by temporal graph embeddings: a walk that
+// arrived at a node at time t may only leave along an edge stamped after
+// t, chosen among those in proportion to weight.  Each node's edges are
+// kept sorted by time with running weight totals, so a step is a binary
+// search over the edges still open rather than a rebuilt table.  A
+// Temporal is never modified after construction, so it is safe for
+// concurrent walks.
+type Temporal struct {
+	offsets []int
+	targets []int
+	times   []int64
+
+	/* tail[e] is the total weight of the edges of e's node from e on, in
+	 * time order.  Summing from the end, rather than subtracting running
+	 * totals, keeps light late edges from being lost to rounding.
+	 */
+	tail []float64
+}
+
+// NewTemporal returns a temporal graph from CSR arrays, as for New, with
+// times giving each edge's timestamp.  Unlike New it copies the edges, to
+// sort them by time, so the arrays may be reused afterwards.
+func NewTemporal(offsets, targets []int, times []int64, weights []float64) (*Temporal, error) {
+	if len(times) != len(targets) {
+		return nil, fmt.Errorf("graph: %d timestamps for %d edges", len(times), len(targets))
+	}
+	/* New checks the rest of the layout. */
+	if _, err := New(offsets, targets, weights); err != nil {
+		return nil, err
+	}
+
+	tg := &Temporal{
+		offsets: slices.Clone(offsets),
+		targets: make([]int, len(targets)),
+		times:   make([]int64, len(times)),
+		tail:    make([]float64, len(targets)),
+	}
+	order := make([]int, len(targets))
+	for i := range order {
+		order[i] = i
+	}
+	for u := range len(offsets) - 1 {
+		lo, hi := offsets[u], offsets[u+1]
+		es := order[lo:hi]
+		sort.SliceStable(es, func(i, j int) bool { return times[es[i]] < times[es[j]] })
+		for i, e := range es {
+			tg.targets[lo+i], tg.times[lo+i] = targets[e], times[e]
+		}
+		var tot float64
+		for i := len(es) - 1; i >= 0; i-- {
+			if weights != nil {
+				tot += weights[es[i]]
+			} else {
+				tot++
+			}
+			tg.tail[lo+i] = tot
+		}
+	}
+	return tg, nil
+}
+
+// Len returns the number of nodes.
+func (tg *Temporal) Len() int {
+	return len(tg.offsets) - 1
+}
+
+// Step leaves u along an edge stamped after time after, returning the
+// node it leads to and its timestamp, or false if every such edge has
+// zero weight or there is none.
+func (tg *Temporal) Step(u int, after int64, rng *r.Rand) (v int, at int64, ok bool) {
+	lo, hi := tg.offsets[u], tg.offsets[u+1]
+	first := lo + sort.Search(hi-lo, func(i int) bool { return tg.times[lo+i] > after })
+	if first == hi {
+		return 0, 0, false
+	}
+	open := tg.tail[first]
+	if !(open > 0) {
+		return 0, 0, false
+	}
+
+	/* Take the edge whose suffix holds a uniform point y in (0, open]
+	 * but whose successor's doesn't.  That edge's weight is what
+	 * separates the two, so it is never a zero-weight one.
+	 */
+	y := (1 - rng.Float64()) * open
+	e := first + sort.Search(hi-first, func(i int) bool {
+		next := 0.0
+		if first+i+1 < hi {
+			next = tg.tail[first+i+1]
+		}
+		return next < y
+	})
+	return tg.targets[e], tg.times[e], true
+}
+
+// Walk returns a time-respecting walk of up to length nodes beginning at
+// start, using only edges stamped after time after, along with the
+// timestamp of each step, which strictly increase.  The walk is shorter
+// only if it runs out of open edges.
+func (tg *Temporal) Walk(start int, after int64, length int, rng *r.Rand) (nodes []int, times []int64) {
+	if length <= 0 {
+		return nil, nil
+	}
+	nodes = append(make([]int, 0, length), start)
+	times = make([]int64, 0, length-1)
+	for u, t := start, after; len(nodes) < length; {
+		v, at, ok := tg.Step(u, t, rng)
+		if !ok {
+			break
+		}
+		nodes = append(nodes, v)
+		times = append(times, at)
+		u, t = v, at
+	}
+	return nodes, times
+}
+
+// Walks generates a batch of walks as Graph.Walks does, each free to use
+// any edge for its first step.
+func (tg *Temporal) Walks(count, length int, opts ...Option) ([][]int, error) {
+	return walks(tg.Len(), count, length, newConfig(opts), tg.walk)
+}
+
+func (tg *Temporal) walk(dst []int, start int, rng *r.Rand) []int {
+	dst = append(dst, start)
+	for u, t := start, int64(math.MinInt64); len(dst) < cap(dst); {
+		v, at, ok := tg.Step(u, t, rng)
+		if !ok {
+			break
+		}
+		dst = append(dst, v)
+		u, t = v, at
+	}
+	return dst
+}
@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"math"
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestTemporalStep(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		offsets, targets, weights := randomGraph(t)
+		times := rapid.SliceOfN(rapid.Int64Range(0, 10), len(targets), len(targets)).Draw(t, "times")
+		tg, err := NewTemporal(offsets, targets, times, weights)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		u := rapid.IntRange(0, tg.Len()-1).Draw(t, "u")
+		after := rapid.Int64Range(-1, 10).Draw(t, "after")
+
+		/* Only edges stamped after the arrival time count. */
+		want := map[[2]int64]float64{}
+		var tot float64
+		for e := offsets[u]; e < offsets[u+1]; e++ {
+			if times[e] > after {
+				want[[2]int64{int64(targets[e]), times[e]}] += weights[e]
+				tot += weights[e]
+			}
+		}
+		rng := r.New(r.NewSource(1))
+		sz := 20_000
+		got := map[[2]int64]float64{}
+		for range sz {
+			v, at, ok := tg.Step(u, after, rng)
+			if ok != (tot > 0) {
+				t.Fatalf("step from %d after %d ok %v with open weight %v\n", u, after, ok, tot)
+			}
+			if !ok {
+				return
+			}
+			got[[2]int64{int64(v), at}]++
+		}
+		for k, c := range got {
+			p := want[k] / tot
+			if p == 0 {
+				t.Fatalf("stepped from %d after %d along %v, which is closed\n", u, after, k)
+			}
+			if math.Abs(c-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+				t.Fatalf("stepped from %d along %v %v times of %d, want p %v\n", u, k, c, sz, p)
+			}
+		}
+	})
+}
+
+func TestTemporalWalk(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		offsets, targets, weights := randomGraph(t)
+		times := rapid.SliceOfN(rapid.Int64Range(0, 10), len(targets), len(targets)).Draw(t, "times")
+		tg, _ := NewTemporal(offsets, targets, times, weights)
+		has := map[[3]int64]bool{}
+		for u := range tg.Len() {
+			for e := offsets[u]; e < offsets[u+1]; e++ {
+				if weights[e] > 0 {
+					has[[3]int64{int64(u), int64(targets[e]), times[e]}] = true
+				}
+			}
+		}
+
+		rng := r.New(r.NewSource(1))
+		for range 100 {
+			nodes, ts := tg.Walk(rapid.IntRange(0, tg.Len()-1).Draw(t, "start"), -1, 12, rng)
+			if len(ts) != len(nodes)-1 {
+				t.Fatalf("walk %v has timestamps %v\n", nodes, ts)
+			}
+			for i, at := range ts {
+				if i > 0 && at <= ts[i-1] {
+					t.Fatalf("walk %v goes back in time: %v\n", nodes, ts)
+				}
+				if !has[[3]int64{int64(nodes[i]), int64(nodes[i+1]), at}] {
+					t.Fatalf("walk %v uses a missing edge at %d\n", nodes, at)
+				}
+			}
+			last := int64(-1)
+			if len(ts) > 0 {
+				last = ts[len(ts)-1]
+			}
+			if _, _, ok := tg.Step(nodes[len(nodes)-1], last, rng); ok && len(nodes) < 12 {
+				t.Fatalf("walk %v stopped with open edges left\n", nodes)
+			}
+		}
+
+		ws, err := tg.Walks(100, 12, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if len(ws) != 100 {
+			t.Fatalf("got %d walks, want 100\n", len(ws))
+		}
+	})
+}
+
+func TestNewTemporalInvalid(t *testing.T) {
+	if _, err := NewTemporal([]int{0, 1}, []int{0}, nil, nil); err == nil {
+		t.Fatalf("expected error for missing timestamps\n")
+	}
+	if _, err := NewTemporal([]int{0, 2}, []int{0}, []int64{1}, nil); err == nil {
+		t.Fatalf("expected error for bad offsets\n")
+	}
+}
@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"fmt"
+	r "math/rand"
+	"runtime"
+	"sync"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+/* walkChunk is the number of walks each chunk of a batch generates, from
+ * a random source of its own.  The layout depends only on the number of
+ * walks, so a seeded batch comes out the same whatever the number of
+ * workers.
+ */
+const walkChunk = 256
+
+// Option configures a batch of walks.
+type Option func(*config)
+
+type config struct {
+	starts  []float64
+	workers int
+	seed    int64
+	seeded  bool
+}
+
+// WithStarts draws each walk's first node from the given weights, one per
+// node, rather than uniformly.
+func WithStarts(weights []float64) Option {
+	return func(c *config) {
+		c.starts = weights
+	}
+}
+
+// WithWorkers generates walks on up to n goroutines.  The default, or
+// zero or less, is runtime.GOMAXPROCS(0).
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		c.workers = n
+	}
+}
+
+// WithSeed makes a batch of walks reproducible.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed, c.seeded = seed, true
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.workers <= 0 {
+		cfg.workers = runtime.GOMAXPROCS(0)
+	}
+	if !cfg.seeded {
+		cfg.seed = r.Int63()
+	}
+	return cfg
+}
+
+// Walks generates count walks of up to length nodes, as by Walk, with
+// their first nodes drawn uniformly or as given by WithStarts.  The walks
+// share one backing array.
+func (g *Graph) Walks(count, length int, opts ...Option) ([][]int, error) {
+	return walks(g.Len(), count, length, newConfig(opts), g.walk)
+}
+
+/* walks generates a batch of walks over n nodes in parallel, each by
+ * appending to a slice with room for exactly length nodes.
+ */
+func walks(n, count, length int, cfg *config, walk func(dst []int, start int, rng *r.Rand) []int) ([][]int, error) {
+	if count < 0 || length < 0 {
+		return nil, fmt.Errorf("graph: cannot make %d walks of length %d", count, length)
+	}
+	if n == 0 && count > 0 && length > 0 {
+		return nil, fmt.Errorf("graph: no nodes to start from")
+	}
+	var starts *alias_sample.AliasSampler
+	if cfg.starts != nil {
+		if len(cfg.starts) != n {
+			return nil, fmt.Errorf("graph: %d start weights for %d nodes", len(cfg.starts), n)
+		}
+		for _, w := range cfg.starts {
+			if !alias_sample.ValidWeight(w) {
+				return nil, fmt.Errorf("graph: start weight %v", w)
+			}
+		}
+		if !anyPositive(cfg.starts) {
+			return nil, fmt.Errorf("graph: start weights are all zero")
+		}
+		starts, _ = alias_sample.Init(cfg.starts)
+	}
+
+	res := make([][]int, count)
+	if length == 0 {
+		return res, nil
+	}
+	buf := make([]int, count*length)
+	chunks := (count + walkChunk - 1) / walkChunk
+	seeds := make([]int64, chunks)
+	rng := r.New(r.NewSource(cfg.seed))
+	for c := range seeds {
+		seeds[c] = rng.Int63()
+	}
+	run := func(c int) {
+		rng := r.New(r.NewSource(seeds[c]))
+		for i := c * walkChunk; i < min((c+1)*walkChunk, count); i++ {
+			var start int
+			if starts != nil {
+				start = starts.NextFrom(rng)
+			} else {
+				start = rng.Intn(n)
+			}
+			res[i] = walk(buf[i*length:i*length:(i+1)*length], start, rng)
+		}
+	}
+
+	if cfg.workers == 1 || chunks <= 1 {
+		for c := range chunks {
+			run(c)
+		}
+		return res, nil
+	}
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range min(cfg.workers, chunks) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range next {
+				run(c)
+			}
+		}()
+	}
+	for c := range chunks {
+		next <- c
+	}
+	close(next)
+	wg.Wait()
+	return res, nil
+}
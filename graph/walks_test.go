@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestWalks(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		offsets, targets, weights := randomGraph(t)
+		g, err := New(offsets, targets, weights)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		count := rapid.IntRange(0, 2000).Draw(t, "count")
+		length := rapid.IntRange(0, 20).Draw(t, "length")
+		seed := rapid.Int64().Draw(t, "seed")
+
+		ws, err := g.Walks(count, length, WithSeed(seed), WithWorkers(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if len(ws) != count {
+			t.Fatalf("got %d walks, want %d\n", len(ws), count)
+		}
+		for _, w := range ws {
+			if len(w) > length || (length > 0 && len(w) == 0) {
+				t.Fatalf("walk %v is not up to %d long\n", w, length)
+			}
+			for i := 1; i < len(w); i++ {
+				ts, _ := g.Neighbors(w[i-1])
+				if !slices.Contains(ts, w[i]) {
+					t.Fatalf("walk %v steps along a missing edge\n", w)
+				}
+			}
+			if len(w) < length {
+				if _, ok := g.Step(w[len(w)-1], nil); ok {
+					t.Fatalf("walk %v stopped short of a dead end\n", w)
+				}
+			}
+		}
+
+		/* The same seed gives the same walks on any number of workers. */
+		again, _ := g.Walks(count, length, WithSeed(seed), WithWorkers(4))
+		for i := range ws {
+			if !slices.Equal(ws[i], again[i]) {
+				t.Fatalf("walk %d differs with more workers: %v vs %v\n", i, ws[i], again[i])
+			}
+		}
+	})
+}
+
+func TestWalksStarts(t *testing.T) {
+	/* A ring, so every walk is determined by where it starts. */
+	g, _ := New([]int{0, 1, 2, 3}, []int{1, 2, 0}, nil)
+	starts := []float64{1, 0, 3}
+	sz := 20_000
+	ws, err := g.Walks(sz, 2, WithStarts(starts), WithSeed(1))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	counts := make([]float64, 3)
+	for _, w := range ws {
+		counts[w[0]]++
+		if w[1] != (w[0]+1)%3 {
+			t.Fatalf("walk %v left the ring\n", w)
+		}
+	}
+	for i, c := range counts {
+		p := starts[i] / 4
+		if math.Abs(c-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+			t.Fatalf("started at %d %v times of %d, want p %v\n", i, c, sz, p)
+		}
+	}
+
+	for name, s := range map[string][]float64{
+		"length":   {1, 1},
+		"zero":     {0, 0, 0},
+		"negative": {1, -1, 1},
+	} {
+		if _, err := g.Walks(10, 2, WithStarts(s)); err == nil {
+			t.Fatalf("%s: expected error\n", name)
+		}
+	}
+}
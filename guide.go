@@ -0,0 +1,80 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// GuideSampler is a CDFSampler with a guide table: for each of n equal
+// slices of the unit interval, it records the first index whose running sum
+// reaches that slice, so a draw starts its search there instead of
+// bisecting.  Draws take O(1) expected time.  Update is O(n) like
+// CDFSampler's, but it is a single linear pass with no allocation, far
+// cheaper than rebuilding an alias table.
+type GuideSampler struct {
+	CDFSampler
+
+	guide []int
+}
+
+func InitGuide(probs []float64, opts ...Option) (*GuideSampler, error) {
+	cdf, err := InitCDF(probs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &GuideSampler{
+		CDFSampler: *cdf,
+		guide:      make([]int, len(probs)),
+	}
+	s.fillGuide()
+	return s, nil
+}
+
+func (s *GuideSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *GuideSampler) NextFrom(rng *r.Rand) int {
+	u := rng.Float64()
+	i := s.guide[int(u*float64(len(s.guide)))]
+
+	/* The guide entry can only be off by rounding, but check both ways so
+	 * that the result always matches a plain binary search.
+	 */
+	u *= s.total()
+	for i > 0 && s.cumulative[i-1] > u {
+		i--
+	}
+	for i < len(s.cumulative) && s.cumulative[i] <= u {
+		i++
+	}
+	if i == len(s.cumulative) {
+		return s.lastDrawable()
+	}
+	return i
+}
+
+// Update sets the weight of index i to w, then refreshes the guide table.
+func (s *GuideSampler) Update(i int, w float64) error {
+	if err := s.CDFSampler.Update(i, w); err != nil {
+		return err
+	}
+	s.fillGuide()
+	return nil
+}
+
+/* fillGuide points each slice of the guide table at the first index whose
+ * running sum exceeds the start of the slice.
+ */
+func (s *GuideSampler) fillGuide() {
+	m := float64(len(s.guide))
+	tot := s.total()
+	i := 0
+	for j := range s.guide {
+		start := float64(j) / m * tot
+		for i < len(s.cumulative)-1 && s.cumulative[i] <= start {
+			i++
+		}
+		s.guide[j] = i
+	}
+}
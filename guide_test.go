@@ -0,0 +1,36 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestGuideUpdate(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+		s, err := InitGuide(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		i := rapid.IntRange(0, len(probs)-1).Draw(t, "i")
+		w := rapid.Float64Range(0, 5.0).Draw(t, "w")
+		if err := s.Update(i, w); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* After the update the guided search must agree with a plain
+		 * binary search over the same running sums.
+		 */
+		seed := rapid.Int64().Draw(t, "seed")
+		a, b := r.New(r.NewSource(seed)), r.New(r.NewSource(seed))
+		for range 1000 {
+			if got, want := s.NextFrom(a), s.CDFSampler.NextFrom(b); got != want {
+				t.Fatalf("guided draw %d, binary search %d\n", got, want)
+			}
+		}
+	})
+}
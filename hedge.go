@@ -0,0 +1,113 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+)
+
+/* hedgeLevels is how many damped tables a Hedger keeps per table.  By the
+ * last one the damping has flattened the weights about as far as it is
+ * going to, so later attempts share it.
+ */
+const hedgeLevels = 8
+
+// Hedger picks the targets of a request's attempts, the first try and any
+// retries or hedges, from a Dynamic's weights.  Each attempt goes to a
+// target no earlier attempt has used, while one is left.  Later attempts
+// also see damped weights: attempt k draws with each weight w raised to
+// damping^k, so with damping below 1 the weights flatten towards uniform
+// as attempts go on.  That keeps every retry from piling onto the heaviest
+// backends, which are often the reason the first try failed.  A damping
+// of 1 keeps the weights as they are; 0 makes every attempt after the
+// first uniform over the targets with positive weight.
+//
+// A Hedger is safe for concurrent use.  It follows Updates to the Dynamic,
+// building its damped tables lazily for each new set of weights.
+type Hedger struct {
+	d       *Dynamic
+	damping float64
+
+	mu     sync.Mutex
+	base   *AliasSampler   // the table the damped ones were built from
+	damped []*AliasSampler // damped[k-1] serves attempt k
+}
+
+func NewHedger(d *Dynamic, damping float64) (*Hedger, error) {
+	if !(damping >= 0 && damping <= 1) {
+		return nil, &SampleError{"damping must be between 0 and 1"}
+	}
+	return &Hedger{d: d, damping: damping}, nil
+}
+
+// Attempts starts picking targets for one request, drawing from rng.  The
+// result is meant for that request alone, and is not safe for concurrent
+// use.
+func (h *Hedger) Attempts(rng *r.Rand) *Attempts {
+	return &Attempts{h: h, s: h.d.Sampler(), rng: rng, tried: map[int]struct{}{}}
+}
+
+// Attempts hands out the targets for successive attempts at one request.
+// All of them come from the weights in effect when it was created.
+type Attempts struct {
+	h     *Hedger
+	s     *AliasSampler
+	rng   *r.Rand
+	tried map[int]struct{}
+	order []int
+}
+
+// Next returns the target for the next attempt.  It returns an error once
+// every target with positive weight has been tried.
+func (a *Attempts) Next() (int, error) {
+	s, err := a.h.table(a.s, len(a.order))
+	if err != nil {
+		return 0, err
+	}
+	i, err := s.nextExcluding(a.rng, func(i int) bool {
+		_, ok := a.tried[i]
+		return ok
+	})
+	if err != nil {
+		return 0, err
+	}
+	a.tried[i] = struct{}{}
+	a.order = append(a.order, i)
+	return i, nil
+}
+
+// Tried returns the targets handed out so far, in order.
+func (a *Attempts) Tried() []int {
+	return append([]int(nil), a.order...)
+}
+
+/* table returns the table for the given attempt, counting from 0, under
+ * the weights of base.
+ */
+func (h *Hedger) table(base *AliasSampler, attempt int) (*AliasSampler, error) {
+	if attempt == 0 || h.damping == 1 {
+		return base, nil
+	}
+	level := min(attempt, hedgeLevels)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.base != base {
+		h.base, h.damped = base, nil
+	}
+	for len(h.damped) < level {
+		exp := math.Pow(h.damping, float64(len(h.damped)+1))
+		weights := make([]float64, base.n)
+		for i, p := range base.probabilities() {
+			if p > 0 {
+				weights[i] = math.Pow(p, exp)
+			}
+		}
+		s, err := InitInPlace(weights, h.d.opts...)
+		if err != nil {
+			return nil, err
+		}
+		h.damped = append(h.damped, s)
+	}
+	return h.damped[level-1], nil
+}
@@ -0,0 +1,98 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestAttemptsDistinct(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 20).Draw(t, "probs")
+		probs[0] += 0.1
+		d, _ := NewDynamic(probs)
+		h, err := NewHedger(d, rapid.Float64Range(0, 1).Draw(t, "damping"))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		positive := 0
+		for _, p := range probs {
+			if p > 0 {
+				positive++
+			}
+		}
+
+		a := h.Attempts(r.New(r.NewSource(rapid.Int64().Draw(t, "seed"))))
+		seen := map[int]bool{}
+		for range positive {
+			i, err := a.Next()
+			if err != nil {
+				t.Fatalf("attempt %d: got err %v\n", len(seen), err)
+			}
+			if seen[i] || probs[i] == 0 {
+				t.Fatalf("attempt %d went to %d, tried %v\n", len(seen), i, a.Tried())
+			}
+			seen[i] = true
+		}
+		if _, err := a.Next(); err == nil {
+			t.Fatalf("got a target after trying all %d\n", positive)
+		}
+	})
+}
+
+func TestAttemptsDamping(t *testing.T) {
+	probs := []float64{6, 3, 1}
+	d, _ := NewDynamic(probs)
+	rng := r.New(r.NewSource(1))
+	sz := 200_000
+
+	/* Without damping the second attempt follows the weights of what
+	 * the first one left, and with full damping it is uniform over them.
+	 */
+	for _, damping := range []float64{1, 0} {
+		h, _ := NewHedger(d, damping)
+		counts := make([]float64, len(probs))
+		for range sz {
+			a := h.Attempts(rng)
+			a.Next()
+			second, _ := a.Next()
+			counts[second]++
+		}
+		for j := range probs {
+			var want float64
+			for i, p := range probs {
+				if i == j {
+					continue
+				}
+				if damping == 1 {
+					want += p / 10 * probs[j] / (10 - p)
+				} else {
+					want += p / 10 / 2
+				}
+			}
+			if got := counts[j] / float64(sz); math.Abs(got-want) > 0.01 {
+				t.Fatalf("damping %v: second attempt went to %d %v of the time, want %v\n", damping, j, got, want)
+			}
+		}
+	}
+
+	/* New weights are picked up by later requests. */
+	h, _ := NewHedger(d, 0.5)
+	a := h.Attempts(rng)
+	a.Next()
+	a.Next()
+	d.Update([]float64{0, 0, 1})
+	a = h.Attempts(rng)
+	if i, _ := a.Next(); i != 2 {
+		t.Fatalf("first attempt after update went to %d\n", i)
+	}
+	if _, err := a.Next(); err == nil {
+		t.Fatalf("second attempt found a target with only one left\n")
+	}
+
+	if _, err := NewHedger(d, 2); err == nil {
+		t.Fatalf("damping above 1 was accepted\n")
+	}
+}
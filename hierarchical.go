@@ -0,0 +1,239 @@
+package alias_sample
+
+import (
+	"maps"
+	r "math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Group is one named group of a Hierarchical sampler: its weight among the
+// groups, and the weights of its items.
+type Group struct {
+	Name   string
+	Weight float64
+	Items  []float64
+}
+
+// Hierarchical samples from a catalog split into named groups, such as
+// products by category: a draw picks a group by its weight, then an item
+// within it by the item weights.  Each group has its own table, so
+// replacing one group's items rebuilds only that group, and changing
+// group weights rebuilds only the table over groups, keeping a large
+// catalog with churn in a few categories cheap to maintain.
+//
+// Like Dynamic, it swaps in each change atomically: NextFrom is safe to call
+// concurrently with itself and with updates, which are serialized; Next,
+// which uses the sampler's own random source, is not.
+type Hierarchical struct {
+	current atomic.Pointer[hierarchy]
+	rand    *r.Rand
+	opts    []Option
+	mu      sync.Mutex // serializes updates
+}
+
+/* hierarchy is one immutable version of a Hierarchical.  groups is nil
+ * when no group has any weight.
+ */
+type hierarchy struct {
+	names   []string
+	index   map[string]int
+	weights []float64
+	groups  *AliasSampler
+	items   []*AliasSampler
+}
+
+// NewHierarchical builds a sampler over groups, whose names must be
+// distinct.  The options apply to every table it builds, apart from
+// WithBuffers, since every table needs storage of its own.  Groups may be
+// added later, so groups may be empty, but Next fails until some group
+// has weight.
+func NewHierarchical(groups []Group, opts ...Option) (*Hierarchical, error) {
+	cfg := newConfig(opts)
+	h := &Hierarchical{rand: cfg.newRand(), opts: ownBuffers(opts)}
+	next := &hierarchy{index: map[string]int{}}
+	for _, g := range groups {
+		if _, ok := next.index[g.Name]; ok {
+			return nil, &SampleError{"group " + g.Name + " appears twice"}
+		}
+		items, err := h.buildItems(g.Weight, g.Items)
+		if err != nil {
+			return nil, err
+		}
+		next.index[g.Name] = len(next.names)
+		next.names = append(next.names, g.Name)
+		next.weights = append(next.weights, g.Weight)
+		next.items = append(next.items, items)
+	}
+	if err := h.buildGroups(next); err != nil {
+		return nil, err
+	}
+	h.current.Store(next)
+	return h, nil
+}
+
+// SetGroup adds g, or replaces the group with its name.
+func (h *Hierarchical) SetGroup(g Group) error {
+	items, err := h.buildItems(g.Weight, g.Items)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	next := h.current.Load().clone()
+	i, ok := next.index[g.Name]
+	if !ok {
+		i = len(next.names)
+		next.index = maps.Clone(next.index)
+		next.index[g.Name] = i
+		next.names = append(next.names, g.Name)
+		next.weights = append(next.weights, 0)
+		next.items = append(next.items, nil)
+	}
+	next.items[i] = items
+	if !ok || next.weights[i] != g.Weight {
+		next.weights[i] = g.Weight
+		if err := h.buildGroups(next); err != nil {
+			return err
+		}
+	}
+	h.current.Store(next)
+	return nil
+}
+
+// SetItems replaces the item weights of the named group, rebuilding only
+// its table.
+func (h *Hierarchical) SetItems(name string, items []float64) error {
+	s, err := h.buildItems(0, items)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	next := h.current.Load().clone()
+	i, ok := next.index[name]
+	if !ok {
+		return &SampleError{"no group " + name}
+	}
+	next.items[i] = s
+	h.current.Store(next)
+	return nil
+}
+
+// SetWeight changes the weight of the named group, rebuilding only the
+// table over groups.
+func (h *Hierarchical) SetWeight(name string, weight float64) error {
+	if !ValidWeight(weight) {
+		return &SampleError{"group weight must be finite and non-negative"}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	next := h.current.Load().clone()
+	i, ok := next.index[name]
+	if !ok {
+		return &SampleError{"no group " + name}
+	}
+	next.weights[i] = weight
+	if err := h.buildGroups(next); err != nil {
+		return err
+	}
+	h.current.Store(next)
+	return nil
+}
+
+// Remove drops the named group, reporting whether there was one.  If the
+// table over the remaining groups can't be built under the sampler's
+// options, the group is kept and the error returned.
+func (h *Hierarchical) Remove(name string) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cur := h.current.Load()
+	i, ok := cur.index[name]
+	if !ok {
+		return false, nil
+	}
+	next := &hierarchy{
+		names:   slices.Delete(slices.Clone(cur.names), i, i+1),
+		weights: slices.Delete(slices.Clone(cur.weights), i, i+1),
+		items:   slices.Delete(slices.Clone(cur.items), i, i+1),
+		index:   make(map[string]int, len(cur.names)-1),
+	}
+	for j, n := range next.names {
+		next.index[n] = j
+	}
+	if err := h.buildGroups(next); err != nil {
+		return true, err
+	}
+	h.current.Store(next)
+	return true, nil
+}
+
+// Next returns the name of the chosen group and the index of the chosen
+// item within it.
+func (h *Hierarchical) Next() (group string, item int, err error) {
+	return h.NextFrom(h.rand)
+}
+
+func (h *Hierarchical) NextFrom(rng *r.Rand) (group string, item int, err error) {
+	cur := h.current.Load()
+	if cur.groups == nil {
+		return "", 0, &SampleError{"no group has any weight"}
+	}
+	g := cur.groups.NextFrom(rng)
+	return cur.names[g], cur.items[g].NextFrom(rng), nil
+}
+
+// Groups returns the names of the groups, in the order they were added.
+func (h *Hierarchical) Groups() []string {
+	return slices.Clone(h.current.Load().names)
+}
+
+// Prob returns the chance that a draw picks item i of the named group.
+func (h *Hierarchical) Prob(name string, i int) float64 {
+	cur := h.current.Load()
+	g, ok := cur.index[name]
+	if !ok || cur.groups == nil || i < 0 || i >= cur.items[g].Len() {
+		return 0
+	}
+	return cur.groups.Prob(g) * cur.items[g].Prob(i)
+}
+
+/* buildItems checks a group's weight and builds the table over its items. */
+func (h *Hierarchical) buildItems(weight float64, items []float64) (*AliasSampler, error) {
+	if !ValidWeight(weight) {
+		return nil, &SampleError{"group weight must be finite and non-negative"}
+	}
+	if err := checkWeights(items); err != nil {
+		return nil, err
+	}
+	return Init(items, h.opts...)
+}
+
+/* buildGroups rebuilds the table over next's group weights. */
+func (h *Hierarchical) buildGroups(next *hierarchy) error {
+	next.groups = nil
+	if !slices.ContainsFunc(next.weights, func(w float64) bool { return w > 0 }) {
+		return nil
+	}
+	s, err := Init(next.weights, h.opts...)
+	if err != nil {
+		return err
+	}
+	next.groups = s
+	return nil
+}
+
+/* clone copies the slices of a hierarchy, but shares its tables, which
+ * are never modified, and its index, which must be copied before adding
+ * to it.
+ */
+func (c *hierarchy) clone() *hierarchy {
+	return &hierarchy{
+		names:   slices.Clone(c.names),
+		weights: slices.Clone(c.weights),
+		items:   slices.Clone(c.items),
+		groups:  c.groups,
+		index:   c.index,
+	}
+}
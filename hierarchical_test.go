@@ -0,0 +1,145 @@
+package alias_sample
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestHierarchical(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 5).Draw(t, "groups")
+		var groups []Group
+		for i := range n {
+			groups = append(groups, Group{
+				Name:   string(rune('a' + i)),
+				Weight: rapid.Float64Range(0.1, 3).Draw(t, "weight"),
+				Items:  rapid.SliceOfN(rapid.Float64Range(0.1, 3), 1, 6).Draw(t, "items"),
+			})
+		}
+		h, err := NewHierarchical(groups, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Change one group's items, another's weight, and add a group,
+		 * then check draws against the result.
+		 */
+		g := rapid.IntRange(0, n-1).Draw(t, "changed")
+		groups[g].Items = rapid.SliceOfN(rapid.Float64Range(0.1, 3), 1, 6).Draw(t, "new items")
+		if err := h.SetItems(groups[g].Name, groups[g].Items); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		groups[0].Weight = rapid.Float64Range(0, 3).Draw(t, "new weight")
+		if err := h.SetWeight(groups[0].Name, groups[0].Weight); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		added := Group{Name: "new", Weight: 1, Items: []float64{1, 2}}
+		if err := h.SetGroup(added); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		groups = append(groups, added)
+
+		var tot float64
+		for _, g := range groups {
+			tot += g.Weight
+		}
+		want := map[string][]float64{}
+		for _, g := range groups {
+			var itemTot float64
+			for _, w := range g.Items {
+				itemTot += w
+			}
+			for _, w := range g.Items {
+				want[g.Name] = append(want[g.Name], g.Weight/tot*w/itemTot)
+			}
+		}
+		sz := 50_000
+		got := map[string][]float64{}
+		for _, g := range groups {
+			got[g.Name] = make([]float64, len(g.Items))
+		}
+		for range sz {
+			name, item, err := h.Next()
+			if err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+			got[name][item]++
+		}
+		for name, ps := range want {
+			for i, p := range ps {
+				if math.Abs(h.Prob(name, i)-p) > 1e-9 {
+					t.Fatalf("prob of %s/%d is %v, want %v\n", name, i, h.Prob(name, i), p)
+				}
+				c := got[name][i]
+				if math.Abs(c-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+					t.Fatalf("drew %s/%d %v times of %d, want p %v\n", name, i, c, sz, p)
+				}
+			}
+		}
+	})
+}
+
+func TestHierarchicalUpdates(t *testing.T) {
+	h, err := NewHierarchical(nil)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if _, _, err := h.Next(); err == nil {
+		t.Fatalf("expected error drawing from no groups\n")
+	}
+	h.SetGroup(Group{Name: "a", Weight: 0, Items: []float64{1}})
+	if _, _, err := h.Next(); err == nil {
+		t.Fatalf("expected error drawing from groups with no weight\n")
+	}
+	h.SetGroup(Group{Name: "b", Weight: 1, Items: []float64{0, 1}})
+	for range 100 {
+		if name, item, _ := h.Next(); name != "b" || item != 1 {
+			t.Fatalf("drew %s/%d\n", name, item)
+		}
+	}
+	if ok, err := h.Remove("b"); !ok || err != nil {
+		t.Fatalf("removing b: got %v, err %v\n", ok, err)
+	}
+	if ok, _ := h.Remove("b"); ok {
+		t.Fatalf("b was removed twice\n")
+	}
+	if got := h.Groups(); !slices.Equal(got, []string{"a"}) {
+		t.Fatalf("groups are %v after removing b\n", got)
+	}
+
+	if err := h.SetItems("missing", []float64{1}); err == nil {
+		t.Fatalf("expected error for a missing group\n")
+	}
+	if err := h.SetWeight("a", -1); err == nil {
+		t.Fatalf("expected error for a negative weight\n")
+	}
+	if err := h.SetItems("a", []float64{0}); err == nil {
+		t.Fatalf("expected error for all-zero items\n")
+	}
+	if _, err := NewHierarchical([]Group{{Name: "x", Items: []float64{1}}, {Name: "x", Items: []float64{1}}}); err == nil {
+		t.Fatalf("expected error for a repeated name\n")
+	}
+}
+
+func TestHierarchicalRemoveFailure(t *testing.T) {
+	/* A cap of 0.6 can't be met by one group on its own. */
+	h, err := NewHierarchical([]Group{
+		{Name: "a", Weight: 1, Items: []float64{1, 1}},
+		{Name: "b", Weight: 1, Items: []float64{1, 1}},
+	}, WithMaxProb(0.6))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if ok, err := h.Remove("b"); !ok || err == nil {
+		t.Fatalf("removing b: got %v, err %v\n", ok, err)
+	}
+	if got := h.Groups(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("groups are %v after a failed remove\n", got)
+	}
+	if _, _, err := h.Next(); err != nil {
+		t.Fatalf("drawing after a failed remove: got err %v\n", err)
+	}
+}
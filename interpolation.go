@@ -0,0 +1,62 @@
+package alias_sample
+
+import (
+	"math/bits"
+	r "math/rand"
+)
+
+// InterpolationSampler is a CDFSampler that searches the running sums by
+// interpolation rather than bisection.  For near-uniform, slowly varying
+// weights the running sum is almost a straight line, so a draw usually
+// lands on the right index within a step or two: O(log log n) expected
+// for smooth inputs, with no memory beyond CDFSampler's.  If the weights
+// turn out to be too irregular, the search falls back to bisection, so
+// draws never take more than O(log n) steps.
+type InterpolationSampler struct {
+	CDFSampler
+}
+
+func InitInterpolation(probs []float64, opts ...Option) (*InterpolationSampler, error) {
+	cdf, err := InitCDF(probs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &InterpolationSampler{*cdf}, nil
+}
+
+func (s *InterpolationSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *InterpolationSampler) NextFrom(rng *r.Rand) int {
+	u := rng.Float64() * s.total()
+	if s.total() <= u {
+		return s.lastDrawable()
+	}
+
+	/* The answer is the first index whose running sum exceeds u; keep it
+	 * bracketed in [lo, hi], with base the running sum just before lo.
+	 */
+	lo, hi := 0, len(s.cumulative)-1
+	base := 0.0
+	budget := bits.Len(uint(len(s.cumulative)))
+	for lo < hi {
+		var mid int
+		if budget > 0 {
+			budget--
+			frac := (u - base) / (s.cumulative[hi] - base)
+			mid = lo + int(frac*float64(hi-lo+1))
+			mid = min(max(mid, lo), hi)
+		} else {
+			mid = int(uint(lo+hi) >> 1)
+		}
+
+		if s.cumulative[mid] <= u {
+			lo = mid + 1
+			base = s.cumulative[mid]
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
@@ -0,0 +1,54 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestInterpolationSearch(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		/* Mix smooth and wildly irregular weights, so that both the
+		 * interpolation steps and the bisection fallback get exercised.
+		 */
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 200).Draw(t, "probs")
+		if rapid.Bool().Draw(t, "skewed") {
+			for i := range probs {
+				probs[i] *= probs[i] * probs[i] * probs[i]
+			}
+		}
+		probs[0] += 0.001
+		s, err := InitInterpolation(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		seed := rapid.Int64().Draw(t, "seed")
+		a, b := r.New(r.NewSource(seed)), r.New(r.NewSource(seed))
+		for range 1000 {
+			if got, want := s.NextFrom(a), s.CDFSampler.NextFrom(b); got != want {
+				t.Fatalf("interpolated draw %d, binary search %d\n", got, want)
+			}
+		}
+	})
+}
+
+func BenchmarkInterpolation(b *testing.B) {
+	probs := make([]float64, 1_000_000)
+	for i := range probs {
+		probs[i] = 1 + 0.1*float64(i%100)/100
+	}
+	cdf, _ := InitCDF(probs, WithSeed(1))
+	interp, _ := InitInterpolation(probs, WithSeed(1))
+	b.Run("cdf", func(b *testing.B) {
+		for b.Loop() {
+			cdf.Next()
+		}
+	})
+	b.Run("interpolation", func(b *testing.B) {
+		for b.Loop() {
+			interp.Next()
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// IntervalMethod picks how CheckFrequencies builds its binomial confidence
+// intervals.
+type IntervalMethod int
+
+const (
+	// Wilson is the Wilson score interval, which has close to the nominal
+	// coverage even for small counts and proportions near 0 or 1.
+	Wilson IntervalMethod = iota
+	// Jeffreys is the equal-tailed interval of the Beta(k+1/2, n-k+1/2)
+	// posterior, which is a little tighter than Wilson's in the tails.
+	Jeffreys
+)
+
+// FrequencyCheck is the verdict on one category's observed frequency.
+type FrequencyCheck struct {
+	Observed float64 // the fraction of draws that went to the category
+	Target   float64 // the probability it should have
+	Lo, Hi   float64 // the confidence interval around Observed
+	Outside  bool    // whether Target falls outside [Lo, Hi]
+}
+
+// CheckFrequencies compares counts, the number of draws that went to each
+// category, with target, the weights they were drawn with.  For each
+// category it returns a confidence interval, at the given confidence level,
+// for its probability given its count, and whether the target lies outside
+// it.  Each interval covers the true probability with about that
+// confidence on its own; testing many categories at once calls for a
+// correspondingly higher level, such as 1 - alpha/len(counts) (Bonferroni)
+// for an overall false-alarm rate of alpha.
+func CheckFrequencies(counts []uint64, target []float64, method IntervalMethod, confidence float64) ([]FrequencyCheck, error) {
+	if len(counts) != len(target) {
+		return nil, &SampleError{"counts and target have different lengths"}
+	}
+	if err := checkWeights(target); err != nil {
+		return nil, err
+	}
+	if !(confidence > 0 && confidence < 1) {
+		return nil, &SampleError{"confidence must be between 0 and 1"}
+	}
+	var draws uint64
+	for _, c := range counts {
+		draws += c
+	}
+	if draws == 0 {
+		return nil, &SampleError{"no draws to check"}
+	}
+
+	total := sum(target)
+	n := float64(draws)
+	res := make([]FrequencyCheck, len(counts))
+	for i, c := range counts {
+		k := float64(c)
+		var lo, hi float64
+		switch method {
+		case Wilson:
+			lo, hi = wilson(k, n, confidence)
+		case Jeffreys:
+			lo, hi = jeffreys(k, n, confidence)
+		default:
+			return nil, &SampleError{"unknown interval method"}
+		}
+		p := target[i] / total
+		res[i] = FrequencyCheck{Observed: k / n, Target: p, Lo: lo, Hi: hi, Outside: p < lo || p > hi}
+	}
+	return res, nil
+}
+
+// CheckEmpirical runs CheckFrequencies on the draws the sampler has
+// counted, against its own distribution.  It returns an error if the
+// sampler was built without WithTracking.
+func (s *AliasSampler) CheckEmpirical(method IntervalMethod, confidence float64) ([]FrequencyCheck, error) {
+	if s.counts == nil {
+		return nil, &SampleError{"sampler does not track its draws"}
+	}
+	counts := make([]uint64, s.n)
+	for i := range s.counts {
+		counts[i] = s.counts[i].Load()
+	}
+	return CheckFrequencies(counts, s.probabilities(), method, confidence)
+}
+
+/* wilson returns the Wilson score interval for k successes in n trials. */
+func wilson(k, n, confidence float64) (lo, hi float64) {
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	p := k / n
+	denom := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denom
+	half := z / denom * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return max(center-half, 0), min(center+half, 1)
+}
+
+/* jeffreys returns the Jeffreys interval for k successes in n trials.  By
+ * convention the bound at an end is pinned there when every trial, or
+ * none, succeeded.
+ */
+func jeffreys(k, n, confidence float64) (lo, hi float64) {
+	tail := (1 - confidence) / 2
+	a, b := k+0.5, n-k+0.5
+	lo, hi = 0, 1
+	if k > 0 {
+		lo = betaQuantile(tail, a, b)
+	}
+	if k < n {
+		hi = betaQuantile(1-tail, a, b)
+	}
+	return lo, hi
+}
+
+/* betaQuantile inverts the regularized incomplete beta function by
+ * bisection, which is slow but cannot fail to converge.
+ */
+func betaQuantile(q, a, b float64) float64 {
+	lo, hi := 0.0, 1.0
+	for range 100 {
+		mid := (lo + hi) / 2
+		if mid == lo || mid == hi {
+			break
+		}
+		if betaInc(mid, a, b) < q {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+/* betaInc is the regularized incomplete beta function I_x(a, b), by the
+ * continued fraction in Numerical Recipes, using the symmetry
+ * I_x(a, b) = 1 - I_(1-x)(b, a) where the fraction converges slowly.
+ */
+func betaInc(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(x, a, b) / a
+	}
+	return 1 - front*betaFraction(1-x, b, a)/b
+}
+
+/* betaFraction evaluates the incomplete beta continued fraction by the
+ * modified Lentz method.
+ */
+func betaFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1.0; m <= 10000; m++ {
+		m2 := 2 * m
+		num := m * (b - m) * x / ((a + m2 - 1) * (a + m2))
+		d = 1 / clamp(1+num*d)
+		c = clamp(1 + num/c)
+		h *= d * c
+		num = -(a + m) * (a + b + m) * x / ((a + m2) * (a + m2 + 1))
+		d = 1 / clamp(1+num*d)
+		c = clamp(1 + num/c)
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,99 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCheckEmpirical(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
+		method := IntervalMethod(rapid.IntRange(0, 1).Draw(t, "method"))
+		as, _ := Init(probs, WithTracking())
+		draws := make([]int, 100_000)
+		as.NextN(draws)
+
+		/* A correct sampler should essentially never miss at an overall
+		 * level of one in a million.
+		 */
+		checks, err := as.CheckEmpirical(method, 1-1e-6/float64(len(probs)))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		for i, c := range checks {
+			if c.Outside || !(c.Lo <= c.Observed && c.Observed <= c.Hi) {
+				t.Fatalf("index %d: %+v\n", i, c)
+			}
+		}
+	})
+}
+
+func TestCheckFrequencies(t *testing.T) {
+	for _, method := range []IntervalMethod{Wilson, Jeffreys} {
+		checks, err := CheckFrequencies([]uint64{5, 5, 0}, []float64{1, 1, 0}, method, 0.95)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if c := checks[0]; c.Outside || c.Lo < 0.2 || c.Lo > 0.25 || math.Abs(c.Lo+c.Hi-1) > 1e-12 {
+			t.Fatalf("method %d: got %+v for 5 of 10\n", method, c)
+		}
+		if c := checks[2]; c.Outside || c.Lo != 0 {
+			t.Fatalf("method %d: got %+v for 0 of 10\n", method, c)
+		}
+
+		/* A biased count is caught. */
+		checks, _ = CheckFrequencies([]uint64{600, 400}, []float64{1, 1}, method, 0.99)
+		if !checks[0].Outside || !checks[1].Outside {
+			t.Fatalf("method %d: 600 of 1000 passed for 1/2: %+v\n", method, checks)
+		}
+	}
+
+	/* The Wilson interval for 5 of 10 is a textbook value. */
+	checks, _ := CheckFrequencies([]uint64{5, 5}, []float64{1, 1}, Wilson, 0.95)
+	if math.Abs(checks[0].Lo-0.2366) > 1e-4 || math.Abs(checks[0].Hi-0.7634) > 1e-4 {
+		t.Fatalf("got Wilson interval [%v, %v]\n", checks[0].Lo, checks[0].Hi)
+	}
+
+	for _, bad := range []struct {
+		counts     []uint64
+		target     []float64
+		method     IntervalMethod
+		confidence float64
+	}{
+		{[]uint64{1}, []float64{1, 2}, Wilson, 0.95},
+		{[]uint64{0, 0}, []float64{1, 2}, Wilson, 0.95},
+		{[]uint64{1, 1}, []float64{1, 2}, Wilson, 1},
+		{[]uint64{1, 1}, []float64{1, -2}, Wilson, 0.95},
+		{[]uint64{1, 1}, []float64{1, 2}, IntervalMethod(7), 0.95},
+	} {
+		if _, err := CheckFrequencies(bad.counts, bad.target, bad.method, bad.confidence); err == nil {
+			t.Errorf("accepted %+v\n", bad)
+		}
+	}
+	as, _ := Init([]float64{1})
+	if _, err := as.CheckEmpirical(Wilson, 0.95); err == nil {
+		t.Fatalf("untracked sampler was accepted\n")
+	}
+}
+
+func TestBetaQuantile(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		x := rapid.Float64Range(0.001, 0.999).Draw(t, "x")
+		a := rapid.Float64Range(0.5, 1000).Draw(t, "a")
+		b := rapid.Float64Range(0.5, 1000).Draw(t, "b")
+
+		/* I_x(a, 1) = x^a has a closed form. */
+		if got, want := betaInc(x, a, 1), math.Pow(x, a); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("I_%v(%v, 1) = %v, want %v\n", x, a, got, want)
+		}
+		if got := betaInc(x, a, b) + betaInc(1-x, b, a); math.Abs(got-1) > 1e-9 {
+			t.Fatalf("symmetry: got %v\n", got)
+		}
+		q := rapid.Float64Range(0.001, 0.999).Draw(t, "q")
+		if got := betaInc(betaQuantile(q, a, b), a, b); math.Abs(got-q) > 1e-9 {
+			t.Fatalf("quantile %v of Beta(%v, %v) has CDF %v\n", q, a, b, got)
+		}
+	})
+}
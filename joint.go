@@ -0,0 +1,171 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// Sampler2D draws (row, col) pairs from a joint distribution over a
+// matrix of weights, possibly ragged.  Init2D chooses its layout from the
+// matrix's shape:
+//
+//   - a single table over every cell, decoding the drawn index into a
+//     row and column, when padding the rows out to the longest would at
+//     most double the number of cells;
+//   - otherwise, a table over the rows' total weights followed by a table
+//     per row, which takes two draws but no padding.
+type Sampler2D struct {
+	rand *r.Rand
+
+	/* flat is set for the single-table layout, over rows of cols cells. */
+	flat *AliasSampler
+	cols int
+
+	/* Otherwise rows picks a row and cells[row] a column in it; cells is
+	 * nil for rows with no weight.
+	 */
+	rows  *AliasSampler
+	cells []*AliasSampler
+}
+
+// Init2D builds a sampler over the cells of weights, where weights[i][j]
+// is the weight of the pair (i, j).  The weights must be finite and
+// non-negative, with some weight somewhere.  Options are applied to the
+// table or tables as for Init, except that WithMinProb and WithMaxProb
+// bound the probability of each cell of the matrix, not of each table's
+// indices; a ragged matrix's missing cells are never drawn.
+func Init2D(weights [][]float64, opts ...Option) (*Sampler2D, error) {
+	cells, cols := 0, 0
+	for _, row := range weights {
+		cells += len(row)
+		cols = max(cols, len(row))
+		for _, w := range row {
+			if !ValidWeight(w) {
+				return nil, &SampleError{"weights must be finite and non-negative"}
+			}
+		}
+	}
+	if cells == 0 {
+		return nil, &SampleError{"no probabilities provided"}
+	}
+
+	cfg := newConfig(opts)
+	if cfg.minProb != 0 || cfg.maxProb != 1 {
+		clamped, err := clamp2D(weights, cells, cfg)
+		if err != nil {
+			return nil, err
+		}
+		weights = clamped
+		opts = append(opts[:len(opts):len(opts)], WithMinProb(0), WithMaxProb(1))
+	}
+	s := &Sampler2D{rand: cfg.newRand()}
+	if len(weights)*cols <= 2*cells {
+		flat := make([]float64, len(weights)*cols)
+		for i, row := range weights {
+			copy(flat[i*cols:], row)
+		}
+		if err := checkWeights(flat); err != nil {
+			return nil, err
+		}
+		t, err := InitInPlace(flat, opts...)
+		if err != nil {
+			return nil, err
+		}
+		s.flat, s.cols = t, cols
+		return s, nil
+	}
+
+	totals := make([]float64, len(weights))
+	var sets [][]float64
+	for i, row := range weights {
+		for _, w := range row {
+			totals[i] += w
+		}
+		if totals[i] > 0 {
+			sets = append(sets, row)
+		}
+	}
+	if err := checkWeights(totals); err != nil {
+		return nil, err
+	}
+	rows, err := Init(totals, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := BuildMany(sets, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.rows, s.cells = rows, make([]*AliasSampler, len(weights))
+	for i := range weights {
+		if totals[i] > 0 {
+			s.cells[i], tables = tables[0], tables[1:]
+		}
+	}
+	return s, nil
+}
+
+/* clamp2D returns a copy of weights, which has cells cells, with the
+ * configured bounds applied across all of them at once.  Doing it before
+ * either layout is built keeps the flat table's padding and the rows'
+ * totals out of it.
+ */
+func clamp2D(weights [][]float64, cells int, cfg *config) ([][]float64, error) {
+	all := make([]float64, 0, cells)
+	for _, row := range weights {
+		all = append(all, row...)
+	}
+	if err := checkWeights(all); err != nil {
+		return nil, err
+	}
+	if err := clampProbs(all, cfg.minProb, cfg.maxProb); err != nil {
+		return nil, err
+	}
+	res := make([][]float64, len(weights))
+	for i, row := range weights {
+		res[i], all = all[:len(row):len(row)], all[len(row):]
+	}
+	return res, nil
+}
+
+func (s *Sampler2D) Next() (row, col int) {
+	return s.NextFrom(s.rand)
+}
+
+func (s *Sampler2D) NextFrom(rng *r.Rand) (row, col int) {
+	if s.flat != nil {
+		i := s.flat.NextFrom(rng)
+		return i / s.cols, i % s.cols
+	}
+	row = s.rows.NextFrom(rng)
+	return row, s.cells[row].NextFrom(rng)
+}
+
+// Rows returns the number of rows.
+func (s *Sampler2D) Rows() int {
+	if s.flat != nil {
+		return s.flat.Len() / s.cols
+	}
+	return s.rows.Len()
+}
+
+// Prob returns the probability of drawing the pair (row, col).
+func (s *Sampler2D) Prob(row, col int) float64 {
+	if row < 0 || row >= s.Rows() || col < 0 {
+		return 0
+	}
+	if s.flat != nil {
+		if col >= s.cols {
+			return 0
+		}
+		return s.flat.Prob(row*s.cols + col)
+	}
+	if s.cells[row] == nil || col >= s.cells[row].Len() {
+		return 0
+	}
+	return s.rows.Prob(row) * s.cells[row].Prob(col)
+}
+
+// Flat reports whether the sampler uses a single table over every cell.
+func (s *Sampler2D) Flat() bool {
+	return s.flat != nil
+}
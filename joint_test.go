@@ -0,0 +1,143 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestInit2D(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		rows := rapid.IntRange(1, 8).Draw(t, "rows")
+		ragged := rapid.Bool().Draw(t, "ragged")
+		cols := rapid.IntRange(1, 8).Draw(t, "cols")
+		weights := make([][]float64, rows)
+		var tot float64
+		for i := range weights {
+			n := cols
+			if ragged {
+				n = rapid.IntRange(0, 8).Draw(t, "len")
+			}
+			weights[i] = rapid.SliceOfN(rapid.Float64Range(0, 2), n, n).Draw(t, "row")
+			for _, w := range weights[i] {
+				tot += w
+			}
+		}
+		if tot == 0 {
+			if _, err := Init2D(weights); err == nil {
+				t.Fatalf("expected error for no weight\n")
+			}
+			return
+		}
+		s, err := Init2D(weights, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if !ragged && !s.Flat() {
+			t.Fatalf("rectangular weights did not get a flat table\n")
+		}
+		if s.Rows() != rows {
+			t.Fatalf("sampler has %d rows, want %d\n", s.Rows(), rows)
+		}
+
+		sz := 50_000
+		got := make([][]float64, rows)
+		for i := range got {
+			got[i] = make([]float64, len(weights[i]))
+		}
+		for range sz {
+			i, j := s.Next()
+			if j >= len(weights[i]) {
+				t.Fatalf("drew (%d, %d), outside row of %d\n", i, j, len(weights[i]))
+			}
+			got[i][j]++
+		}
+		for i, row := range weights {
+			for j, w := range row {
+				p := w / tot
+				if math.Abs(s.Prob(i, j)-p) > 1e-9 {
+					t.Fatalf("prob of (%d, %d) is %v, want %v\n", i, j, s.Prob(i, j), p)
+				}
+				if math.Abs(got[i][j]-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+					t.Fatalf("drew (%d, %d) %v times of %d, want p %v\n", i, j, got[i][j], sz, p)
+				}
+			}
+		}
+	})
+}
+
+func TestInit2DLayout(t *testing.T) {
+	/* One long row among many short ones would mostly be padding. */
+	weights := [][]float64{make([]float64, 100)}
+	for range 20 {
+		weights = append(weights, []float64{1})
+	}
+	weights[0][99] = 1
+	s, err := Init2D(weights)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if s.Flat() {
+		t.Fatalf("ragged weights got a flat table\n")
+	}
+	if p := s.Prob(0, 99); math.Abs(p-1.0/21) > 1e-12 {
+		t.Fatalf("prob of (0, 99) is %v, want 1/21\n", p)
+	}
+
+	for name, w := range map[string][][]float64{
+		"empty":    {{}, {}},
+		"negative": {{1, -1}},
+		"nan":      {{math.NaN()}},
+	} {
+		if _, err := Init2D(w); err == nil {
+			t.Fatalf("%s: expected error\n", name)
+		}
+	}
+}
+
+func TestInit2DBounds(t *testing.T) {
+	/* The floor covers the real cells of a ragged matrix and nothing else:
+	 * not the padding of the flat table, in the first case, nor a row with
+	 * no cells, in the second.
+	 */
+	long := make([]float64, 30)
+	long[0] = 1
+	for _, weights := range [][][]float64{
+		{{1, 1, 1, 1}, {1, 1, 1}, {1, 1}, {5}},
+		{long, {}, {}, {}, {0, 2}},
+	} {
+		cells := 0
+		for _, row := range weights {
+			cells += len(row)
+		}
+		s, err := Init2D(weights, WithSeed(1), WithMinProb(0.01), WithMaxProb(0.5))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		var tot float64
+		for i, row := range weights {
+			for j := range row {
+				p := s.Prob(i, j)
+				if p < 0.01-1e-12 || p > 0.5+1e-12 {
+					t.Fatalf("prob of (%d, %d) is %v, outside the bounds\n", i, j, p)
+				}
+				tot += p
+			}
+			if p := s.Prob(i, len(row)); p != 0 {
+				t.Fatalf("prob of (%d, %d), past the row's end, is %v\n", i, len(row), p)
+			}
+		}
+		if math.Abs(tot-1) > 1e-9 {
+			t.Fatalf("probabilities of %d cells sum to %v\n", cells, tot)
+		}
+		for range 20_000 {
+			if i, j := s.Next(); j >= len(weights[i]) {
+				t.Fatalf("drew (%d, %d), outside row of %d\n", i, j, len(weights[i]))
+			}
+		}
+	}
+	if _, err := Init2D([][]float64{{1}, {1}}, WithMinProb(0.6)); err == nil {
+		t.Fatalf("impossible bounds were accepted\n")
+	}
+}
@@ -0,0 +1,176 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"slices"
+	"sync/atomic"
+)
+
+// A LazyOption configures InitLazy.  Every Option is also a LazyOption,
+// which applies to the table as for Init.
+type LazyOption interface {
+	applyLazy(c *lazyConfig)
+}
+
+type lazyConfig struct {
+	background bool
+	opts       []Option
+}
+
+type lazyOption func(c *lazyConfig)
+
+func (o lazyOption) applyLazy(c *lazyConfig) {
+	o(c)
+}
+
+func (o Option) applyLazy(c *lazyConfig) {
+	c.opts = append(c.opts, o)
+}
+
+// WithBackgroundBuild makes InitLazy start building the table in its own
+// goroutine straight away, rather than on the first draw.
+func WithBackgroundBuild() LazyOption {
+	return lazyOption(func(c *lazyConfig) {
+		c.background = true
+	})
+}
+
+// LazySampler puts off building its alias table, so that InitLazy costs
+// no more than a copy of the weights.  The table is built by the first
+// draw, or, under WithBackgroundBuild, by a goroutine InitLazy starts.
+// Until it is ready, draws scan the weights for the first running sum past
+// a uniform variate instead, which takes O(n) time but needs no
+// preparation; draws made while another goroutine is building don't wait
+// for it.  Either way they sample the same distribution.
+//
+// NextFrom is safe to call concurrently; Next, which uses the sampler's
+// own random source, is not.
+type LazySampler struct {
+	seed int64
+	rand *r.Rand
+	cfg  *config
+
+	weights []float64 // clamped already, if the options ask for it
+	total   float64
+
+	state atomic.Int32 // lazyIdle, lazyBuilding or lazyDone
+	table atomic.Pointer[AliasSampler]
+	err   error // why the build failed; set before done is closed
+	done  chan struct{}
+}
+
+const (
+	lazyIdle int32 = iota
+	lazyBuilding
+	lazyDone
+)
+
+// InitLazy returns a LazySampler for probs.  Like NewDynamic, it rejects
+// negative and non-finite weights, which the scan can't draw from.
+func InitLazy(probs []float64, opts ...LazyOption) (*LazySampler, error) {
+	var lc lazyConfig
+	for _, opt := range opts {
+		opt.applyLazy(&lc)
+	}
+	cfg := newConfig(lc.opts)
+	if err := checkWeights(probs); err != nil {
+		return nil, err
+	}
+	weights := slices.Clone(probs)
+
+	/* Clamping changes the distribution, so the scan has to see it too;
+	 * the table is then built from weights that are already clamped.
+	 */
+	if cfg.minProb != 0 || cfg.maxProb != 1 {
+		if err := clampProbs(weights, cfg.minProb, cfg.maxProb); err != nil {
+			return nil, err
+		}
+		cfg.minProb, cfg.maxProb = 0, 1
+	}
+
+	l := &LazySampler{
+		seed:    cfg.seed,
+		rand:    cfg.newRand(),
+		cfg:     cfg,
+		weights: weights,
+		total:   sum(weights),
+		done:    make(chan struct{}),
+	}
+	if lc.background && l.state.CompareAndSwap(lazyIdle, lazyBuilding) {
+		go l.build()
+	}
+	return l, nil
+}
+
+/* build makes the table, publishes it, and wakes anyone waiting in
+ * Sampler.  Only the caller that moved the state to lazyBuilding runs it.
+ */
+func (l *LazySampler) build() {
+	s, err := build(slices.Clone(l.weights), l.cfg)
+	if err != nil {
+		l.err = err
+	} else {
+		l.table.Store(s)
+	}
+	l.state.Store(lazyDone)
+	close(l.done)
+}
+
+func (l *LazySampler) Next() int {
+	return l.NextFrom(l.rand)
+}
+
+func (l *LazySampler) NextFrom(rng *r.Rand) int {
+	if s := l.table.Load(); s != nil {
+		return s.NextFrom(rng)
+	}
+	if l.state.CompareAndSwap(lazyIdle, lazyBuilding) {
+		l.build()
+		if s := l.table.Load(); s != nil {
+			return s.NextFrom(rng)
+		}
+	}
+	return l.scan(rng)
+}
+
+/* scan draws by walking the running sum of the weights.  It skips
+ * zero weights, so rounding can't land on one, and falls back to the last
+ * positive weight if the sum falls short of u.
+ */
+func (l *LazySampler) scan(rng *r.Rand) int {
+	u := rng.Float64() * l.total
+	var acc float64
+	last := 0
+	for i, w := range l.weights {
+		if w == 0 {
+			continue
+		}
+		acc += w
+		if u < acc {
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+func (l *LazySampler) Len() int {
+	return len(l.weights)
+}
+
+// Ready reports whether the table has been built, so that draws no longer
+// scan.
+func (l *LazySampler) Ready() bool {
+	return l.table.Load() != nil
+}
+
+// Sampler returns the alias table, building it first if no one has started
+// to, or waiting for the build in progress.  The error is the one Init
+// would have returned; after a failed build, draws keep scanning.
+func (l *LazySampler) Sampler() (*AliasSampler, error) {
+	if l.state.CompareAndSwap(lazyIdle, lazyBuilding) {
+		l.build()
+	}
+	<-l.done
+	return l.table.Load(), l.err
+}
@@ -0,0 +1,106 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestLazySampler(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 3), 1, 40).Draw(t, "probs")
+		probs[len(probs)-1] += 0.5
+		l, err := InitLazy(probs, WithSeed(1), WithMinProb(0.01))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if l.Ready() || l.Len() != len(probs) {
+			t.Fatalf("new lazy sampler is ready or has the wrong length\n")
+		}
+
+		/* The scan has to draw what the table will, clamping included. */
+		want, err := Init(probs, WithMinProb(0.01))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		sz := 50_000
+		got := make([]float64, len(probs))
+		rng := r.New(r.NewSource(2))
+		for range sz {
+			got[l.scan(rng)]++
+		}
+		for i := range probs {
+			p := want.Prob(i)
+			if p == 0 && got[i] > 0 {
+				t.Fatalf("scan drew %d, which has no weight\n", i)
+			}
+			if math.Abs(got[i]-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+				t.Fatalf("scan drew %d %v times of %d, want p %v\n", i, got[i], sz, p)
+			}
+		}
+		if l.Ready() {
+			t.Fatalf("scanning built the table\n")
+		}
+
+		/* The first draw builds the table. */
+		l.Next()
+		if !l.Ready() {
+			t.Fatalf("first draw did not build the table\n")
+		}
+		s, err := l.Sampler()
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		for i := range probs {
+			if math.Abs(s.Prob(i)-want.Prob(i)) > 1e-9 {
+				t.Fatalf("prob %d is %v, want %v\n", i, s.Prob(i), want.Prob(i))
+			}
+		}
+	})
+}
+
+func TestLazyBackground(t *testing.T) {
+	probs := make([]float64, 100_000)
+	for i := range probs {
+		probs[i] = float64(i%7 + 1)
+	}
+	l, err := InitLazy(probs, WithBackgroundBuild())
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	/* Draw from several goroutines while the table builds. */
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := r.New(r.NewSource(int64(w)))
+			for range 1000 {
+				if i := l.NextFrom(rng); i < 0 || i >= len(probs) {
+					t.Errorf("drew %d, out of range\n", i)
+					return
+				}
+			}
+		}()
+	}
+	s, err := l.Sampler()
+	wg.Wait()
+	if err != nil || s == nil || !l.Ready() {
+		t.Fatalf("background build gave %v, %v\n", s, err)
+	}
+}
+
+func TestLazyErrors(t *testing.T) {
+	for _, probs := range [][]float64{nil, {0, 0}, {1, -1}, {1, math.NaN()}} {
+		if _, err := InitLazy(probs); err == nil {
+			t.Fatalf("weights %v were accepted\n", probs)
+		}
+	}
+	if _, err := InitLazy([]float64{1, 2}, WithMinProb(0.9)); err == nil {
+		t.Fatalf("impossible bounds were accepted\n")
+	}
+}
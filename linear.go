@@ -0,0 +1,77 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// LinearSampler draws by scanning the running sum of the weights for the
+// first entry past a uniform variate.  Draws take O(n) time, but they use
+// a single random number and a short, predictable loop, so for tiny
+// distributions (up to about LinearMaxLen entries) it beats the alias
+// table's two random numbers and two dependent memory accesses.
+type LinearSampler struct {
+	seed int64
+	rand *r.Rand
+
+	cumulative []float64 // normalized, so the last drawable entry is 1
+}
+
+// LinearMaxLen is the largest number of indices for which LinearSampler is
+// expected to be faster than AliasSampler.  The crossover depends on the
+// machine; see BenchmarkSmall in the tests.
+const LinearMaxLen = 4
+
+func InitLinear(probs []float64, opts ...Option) (*LinearSampler, error) {
+	cfg := newConfig(opts)
+	if len(probs) == 0 {
+		return nil, &SampleError{"no probabilities provided"}
+	}
+
+	var tot float64
+	last := 0
+	for i, p := range probs {
+		tot += p
+		if p > 0 {
+			last = i
+		}
+	}
+
+	cumulative := make([]float64, len(probs))
+	var acc float64
+	for i, p := range probs {
+		acc += p
+		cumulative[i] = acc / tot
+	}
+
+	/* Pin everything from the last drawable index on to exactly 1, so
+	 * that rounding in the running sum can never leave part of [0, 1)
+	 * uncovered.
+	 */
+	for i := last; i < len(cumulative); i++ {
+		cumulative[i] = 1
+	}
+
+	return &LinearSampler{
+		seed:       cfg.seed,
+		rand:       cfg.newRand(),
+		cumulative: cumulative,
+	}, nil
+}
+
+func (s *LinearSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *LinearSampler) NextFrom(rng *r.Rand) int {
+	u := rng.Float64()
+	for i, c := range s.cumulative {
+		if u < c {
+			return i
+		}
+	}
+	panic("unreachable")
+}
+
+func (s *LinearSampler) Len() int {
+	return len(s.cumulative)
+}
@@ -0,0 +1,40 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// InitFromLogits builds a sampler from unnormalized log-probabilities,
+// applying a softmax at the given temperature: index i is drawn with
+// probability proportional to exp(logits[i] / temperature).  Lower
+// temperatures sharpen the distribution towards the largest logits and
+// higher ones flatten it.  Logits of -Inf are allowed and are never
+// drawn.  Options are applied as for Init.
+func InitFromLogits(logits []float64, temperature float64, opts ...Option) (*AliasSampler, error) {
+	if len(logits) == 0 {
+		return nil, &SampleError{"no logits provided"}
+	}
+	if !(temperature > 0) || math.IsInf(temperature, 1) {
+		return nil, &SampleError{"temperature must be finite and positive"}
+	}
+
+	hi := math.Inf(-1)
+	for _, l := range logits {
+		if math.IsNaN(l) || math.IsInf(l, 1) {
+			return nil, &SampleError{"logits must not be NaN or +Inf"}
+		}
+		hi = max(hi, l)
+	}
+	if math.IsInf(hi, -1) {
+		return nil, &SampleError{"every logit is -Inf"}
+	}
+
+	/* Subtracting the largest logit first keeps every exponent <= 0, so
+	 * nothing overflows and the largest weight is exactly 1.
+	 */
+	weights := make([]float64, len(logits))
+	for i, l := range logits {
+		weights[i] = math.Exp((l - hi) / temperature)
+	}
+	return InitInPlace(weights, opts...)
+}
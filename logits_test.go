@@ -0,0 +1,45 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInitFromLogits(t *testing.T) {
+	logits := []float64{1000, 1001, math.Inf(-1), 999}
+	for _, temp := range []float64{0.5, 1, 10} {
+		as, err := InitFromLogits(logits, temp)
+		if err != nil {
+			t.Fatalf("T=%g: got err %v\n", temp, err)
+		}
+
+		want := make([]float64, len(logits))
+		var tot float64
+		for i, l := range logits {
+			want[i] = math.Exp((l - 1001) / temp)
+			tot += want[i]
+		}
+		for i, p := range tableProbs(as) {
+			if math.Abs(p-want[i]/tot) > 1e-12 {
+				t.Errorf("T=%g, index %d: got %g, want %g\n", temp, i, p, want[i]/tot)
+			}
+		}
+	}
+
+	bad := []struct {
+		logits []float64
+		temp   float64
+	}{
+		{nil, 1},
+		{[]float64{1}, 0},
+		{[]float64{1}, math.Inf(1)},
+		{[]float64{math.NaN()}, 1},
+		{[]float64{math.Inf(1), 0}, 1},
+		{[]float64{math.Inf(-1)}, 1},
+	}
+	for _, b := range bad {
+		if _, err := InitFromLogits(b.logits, b.temp); err == nil {
+			t.Errorf("logits %v at T=%g were accepted\n", b.logits, b.temp)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package alias_sample
+
+// BuildMany builds one sampler per weight set, taking the same options as
+// Init.  It is meant for workloads with thousands of small tables, such as
+// one per graph node, where allocating each table separately dominates:
+// the samplers themselves, and their tables, are carved out of a few
+// contiguous arrays, and the construction scratch space is shared.
+//
+// All of the returned samplers also share a single random source, seeded
+// as for Init, so draws from any of them advance the same stream.  As
+// with a single sampler, they must not be used from several goroutines
+// at once; use NextFrom with a per-goroutine rand.Rand for that.
+func BuildMany(weightSets [][]float64, opts ...Option) ([]*AliasSampler, error) {
+	cfg := newConfig(opts)
+	rand := cfg.newRand()
+
+	total, longest := 0, 0
+	for _, probs := range weightSets {
+		if len(probs) == 0 {
+			return nil, &SampleError{"no probabilities provided"}
+		}
+		total += len(probs)
+		longest = max(longest, len(probs))
+	}
+
+	var probability []float64
+	var probability32 []float32
+	var probability16 []uint16
+	switch cfg.column {
+	case columnFloat32:
+		probability32 = make([]float32, total)
+	case columnFixed16:
+		probability16 = make([]uint16, total)
+	default:
+		probability = make([]float64, total)
+	}
+	var alias []int
+	var alias32 []uint32
+	if cfg.intAliases || uint64(longest) > wideIndices {
+		alias = make([]int, total)
+	} else {
+		alias32 = make([]uint32, total)
+	}
+	scratch := make([]float64, longest)
+	cfg.work = make([]int, longest)
+
+	samplers := make([]AliasSampler, len(weightSets))
+	caches := make([]tableCache, len(weightSets))
+	res := make([]*AliasSampler, len(weightSets))
+	off := 0
+	for i, probs := range weightSets {
+		n := len(probs)
+		probs2 := scratch[:n]
+		copy(probs2, probs)
+
+		/* Cap each window so that a table can never grow into its
+		 * neighbour's.
+		 */
+		c := *cfg
+		c.probability = window(probability, off, n)
+		c.probability32 = window(probability32, off, n)
+		c.probability16 = window(probability16, off, n)
+		c.alias = window(alias, off, n)
+		c.alias32 = window(alias32, off, n)
+
+		s := &samplers[i]
+		s.seed = cfg.seed
+		s.rand = rand
+		s.cache = &caches[i]
+		if err := s.fill(probs2, &c); err != nil {
+			return nil, err
+		}
+		res[i] = s
+		off += n
+	}
+	return res, nil
+}
+
+func window[T any](buf []T, off, n int) []T {
+	if buf == nil {
+		return nil
+	}
+	return buf[off : off+n : off+n]
+}
@@ -0,0 +1,62 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestBuildMany(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		sets := rapid.SliceOfN(
+			rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 20), 1, 50).Draw(t, "sets")
+		for _, probs := range sets {
+			probs[0] += 0.001
+		}
+
+		samplers, err := BuildMany(sets, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if len(samplers) != len(sets) {
+			t.Fatalf("got %d samplers for %d sets\n", len(samplers), len(sets))
+		}
+
+		for i, probs := range sets {
+			var tot float64
+			for _, p := range probs {
+				tot += p
+			}
+			got := tableProbs(samplers[i])
+			for j, p := range probs {
+				if math.Abs(got[j]-p/tot) > 1e-12 {
+					t.Fatalf("set %d index %d: got %g, want %g\n", i, j, got[j], p/tot)
+				}
+			}
+		}
+	})
+
+	if _, err := BuildMany([][]float64{{1}, {}}); err == nil {
+		t.Fatalf("empty weight set was accepted")
+	}
+}
+
+func BenchmarkBuildMany(b *testing.B) {
+	sets := make([][]float64, 10_000)
+	for i := range sets {
+		sets[i] = benchmarkProbs(i%20 + 2)
+	}
+	b.Run("Init", func(b *testing.B) {
+		for b.Loop() {
+			for _, probs := range sets {
+				Init(probs)
+			}
+		}
+	})
+	b.Run("BuildMany", func(b *testing.B) {
+		for b.Loop() {
+			BuildMany(sets)
+		}
+	})
+}
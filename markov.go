@@ -0,0 +1,77 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// MarkovChain samples transitions of a finite Markov chain, with one alias
+// table per row of its transition matrix.  The tables are built with
+// BuildMany, so they share one contiguous allocation and one random
+// source; like a single sampler, a chain must not be stepped from several
+// goroutines at once except through the From variants.
+type MarkovChain struct {
+	rows []*AliasSampler
+	rand *r.Rand
+}
+
+// NewMarkovChain builds a chain from a square transition matrix, where
+// transitions[i][j] is the weight of moving from state i to state j.  Rows
+// need not sum to one, as each is normalized, but every row must have some
+// weight: an absorbing state is one whose only transition is to itself.
+// Options are applied to every row as for Init.
+func NewMarkovChain(transitions [][]float64, opts ...Option) (*MarkovChain, error) {
+	if len(transitions) == 0 {
+		return nil, &SampleError{"no states provided"}
+	}
+	for _, row := range transitions {
+		if len(row) != len(transitions) {
+			return nil, &SampleError{"transition matrix must be square"}
+		}
+		if err := checkWeights(row); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := BuildMany(transitions, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &MarkovChain{rows: rows, rand: rows[0].source()}, nil
+}
+
+// Step returns the state the chain moves to from state.
+func (m *MarkovChain) Step(state int) int {
+	return m.StepFrom(state, m.rand)
+}
+
+func (m *MarkovChain) StepFrom(state int, rng *r.Rand) int {
+	return m.rows[state].NextFrom(rng)
+}
+
+// Walk returns a path of n states beginning at start, so it takes n-1
+// steps.
+func (m *MarkovChain) Walk(start, n int) []int {
+	return m.WalkFrom(start, n, m.rand)
+}
+
+func (m *MarkovChain) WalkFrom(start, n int, rng *r.Rand) []int {
+	if n <= 0 {
+		return nil
+	}
+	path := make([]int, n)
+	path[0] = start
+	for i := 1; i < n; i++ {
+		path[i] = m.StepFrom(path[i-1], rng)
+	}
+	return path
+}
+
+// Len returns the number of states.
+func (m *MarkovChain) Len() int {
+	return len(m.rows)
+}
+
+// Transitions returns a copy of the distribution over next states from
+// state.
+func (m *MarkovChain) Transitions(state int) []float64 {
+	return append([]float64(nil), m.rows[state].probabilities()...)
+}
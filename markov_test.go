@@ -0,0 +1,85 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestMarkovChain(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 6).Draw(t, "n")
+		matrix := make([][]float64, n)
+		for i := range matrix {
+			matrix[i] = rapid.SliceOfN(rapid.Float64Range(0.1, 2), n, n).Draw(t, "row")
+		}
+		m, err := NewMarkovChain(matrix, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if m.Len() != n {
+			t.Fatalf("chain has %d states, want %d\n", m.Len(), n)
+		}
+
+		/* Transition counts out of each state along one long walk should
+		 * follow that state's normalized row.
+		 */
+		sz := 50_000
+		path := m.Walk(rapid.IntRange(0, n-1).Draw(t, "start"), sz)
+		if len(path) != sz {
+			t.Fatalf("walk has %d states, want %d\n", len(path), sz)
+		}
+		counts := make([][]float64, n)
+		visits := make([]float64, n)
+		for i := range counts {
+			counts[i] = make([]float64, n)
+		}
+		for i := 1; i < sz; i++ {
+			counts[path[i-1]][path[i]]++
+			visits[path[i-1]]++
+		}
+		for i, row := range matrix {
+			if visits[i] < 1000 {
+				continue
+			}
+			var tot float64
+			for _, w := range row {
+				tot += w
+			}
+			trans := m.Transitions(i)
+			for j, w := range row {
+				p := w / tot
+				if math.Abs(trans[j]-p) > 1e-9 {
+					t.Fatalf("transition %d->%d is %v, want %v\n", i, j, trans[j], p)
+				}
+				sd := math.Sqrt(visits[i] * p * (1 - p))
+				if math.Abs(counts[i][j]-visits[i]*p) > 6*sd+1 {
+					t.Fatalf("stepped %d->%d %v times of %v, want p %v\n", i, j, counts[i][j], visits[i], p)
+				}
+			}
+		}
+	})
+}
+
+func TestMarkovChainInvalid(t *testing.T) {
+	bad := map[string][][]float64{
+		"empty":      nil,
+		"not square": {{1, 1}, {1}},
+		"zero row":   {{1, 0}, {0, 0}},
+		"negative":   {{1, -1}, {0, 1}},
+	}
+	for name, matrix := range bad {
+		if _, err := NewMarkovChain(matrix); err == nil {
+			t.Fatalf("%s: expected error\n", name)
+		}
+	}
+
+	/* An absorbing state never leaves. */
+	m, _ := NewMarkovChain([][]float64{{0, 1}, {0, 1}})
+	for _, s := range m.Walk(0, 100)[1:] {
+		if s != 1 {
+			t.Fatalf("left absorbing state for %d\n", s)
+		}
+	}
+}
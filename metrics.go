@@ -0,0 +1,26 @@
+package alias_sample
+
+import (
+	"time"
+)
+
+// Metrics receives events from a sampler as they happen, for exporting to
+// a monitoring system.  The aliasprom module has a ready-made Prometheus
+// implementation.  Drew is called from the drawing goroutine, so an
+// implementation used with concurrent NextFrom calls must be safe for
+// concurrent use, and it should be cheap, since it runs on every draw.
+type Metrics interface {
+	// Drew is called with each index returned by Next, NextFrom or NextN.
+	Drew(index int)
+	// Built is called each time a table is built, with how long it took.
+	Built(d time.Duration)
+}
+
+// WithMetrics reports the sampler's draws and builds to m.  Samplers that
+// rebuild themselves, like Schedule, report every rebuild, so the number
+// of Built calls counts rebuilds.
+func WithMetrics(m Metrics) Option {
+	return func(c *config) {
+		c.metrics = m
+	}
+}
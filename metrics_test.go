@@ -0,0 +1,54 @@
+package alias_sample
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingMetrics struct {
+	mu     sync.Mutex
+	draws  map[int]int
+	builds int
+}
+
+func (m *countingMetrics) Drew(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.draws[i]++
+}
+
+func (m *countingMetrics) Built(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.builds++
+}
+
+func TestMetrics(t *testing.T) {
+	m := &countingMetrics{draws: map[int]int{}}
+	as, err := Init([]float64{1, 2, 0}, WithMetrics(m))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if m.builds != 1 {
+		t.Fatalf("got %d builds, want 1\n", m.builds)
+	}
+
+	for range 100 {
+		as.Next()
+	}
+	as.NextN(make([]int, 50))
+	if m.draws[0]+m.draws[1] != 150 || m.draws[2] != 0 {
+		t.Fatalf("got draws %v\n", m.draws)
+	}
+
+	/* Draws on the way to other results aren't reported. */
+	as.NextExcluding(map[int]struct{}{0: {}})
+	if m.draws[0]+m.draws[1] != 150 {
+		t.Fatalf("got draws %v after NextExcluding\n", m.draws)
+	}
+
+	if _, err := Init([]float64{1, 1}, WithMinProb(0.9), WithMetrics(m)); err == nil || m.builds != 1 {
+		t.Fatalf("failed build was reported: builds %d, err %v\n", m.builds, err)
+	}
+}
@@ -0,0 +1,46 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// Mixture draws from one of several component samplers, chosen with
+// probability proportional to its mixing weight.  All of the randomness,
+// both for picking the component and for the component's own draw, comes
+// from the mixture's source (or the rng passed to NextFrom), so a seeded
+// mixture is reproducible no matter how its components were seeded.
+type Mixture struct {
+	components *AliasSampler
+	samplers   []Sampler
+}
+
+// NewMixture returns a mixture of samplers with the given mixing weights.
+// Options are applied to the component table as for Init.
+func NewMixture(samplers []Sampler, mixWeights []float64, opts ...Option) (*Mixture, error) {
+	if len(samplers) != len(mixWeights) {
+		return nil, &SampleError{"need exactly one mixing weight per sampler"}
+	}
+	for _, s := range samplers {
+		if s == nil {
+			return nil, &SampleError{"nil sampler in mixture"}
+		}
+	}
+	components, err := Init(mixWeights, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mixture{components: components, samplers: make([]Sampler, len(samplers))}
+	copy(m.samplers, samplers)
+	return m, nil
+}
+
+// Next returns the index of the chosen component and the value it drew.
+func (m *Mixture) Next() (component, value int) {
+	return m.NextFrom(m.components.source())
+}
+
+func (m *Mixture) NextFrom(rng *r.Rand) (component, value int) {
+	component = m.components.NextFrom(rng)
+	return component, m.samplers[component].NextFrom(rng)
+}
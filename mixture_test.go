@@ -0,0 +1,43 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMixture(t *testing.T) {
+	a, _ := Init([]float64{1, 1})
+	b, _ := InitCDF([]float64{0, 0, 1})
+	mix := func(seed int64) *Mixture {
+		m, err := NewMixture([]Sampler{a, b}, []float64{1, 3}, WithSeed(seed))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		return m
+	}
+
+	m, again := mix(1), mix(1)
+	sz := 100_000
+	counts := [2]int{}
+	for range sz {
+		c, v := m.Next()
+		c2, v2 := again.Next()
+		if c != c2 || v != v2 {
+			t.Fatalf("same seed gave (%d, %d) and (%d, %d)\n", c, v, c2, v2)
+		}
+		if (c == 0 && v > 1) || (c == 1 && v != 2) {
+			t.Fatalf("component %d drew %d\n", c, v)
+		}
+		counts[c]++
+	}
+	if got := float64(counts[1]) / float64(sz); math.Abs(got-0.75) > 0.01 {
+		t.Errorf("P(component 1) = %g, want 0.75\n", got)
+	}
+
+	if _, err := NewMixture([]Sampler{a}, []float64{1, 2}); err == nil {
+		t.Errorf("mismatched weights were accepted\n")
+	}
+	if _, err := NewMixture([]Sampler{nil}, []float64{1}); err == nil {
+		t.Errorf("nil sampler was accepted\n")
+	}
+}
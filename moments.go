@@ -0,0 +1,38 @@
+package alias_sample
+
+// Mean returns the expected value of values[i] when i is drawn from the
+// sampler.  It returns an error if values has the wrong length.
+func (s *AliasSampler) Mean(values []float64) (float64, error) {
+	if len(values) != s.n {
+		return 0, &SampleError{"values length does not match the sampler"}
+	}
+	var mean float64
+	for i, p := range s.probabilities() {
+		if p > 0 {
+			mean += p * values[i]
+		}
+	}
+	return mean, nil
+}
+
+// Variance returns the variance of values[i] when i is drawn from the
+// sampler.  It returns an error if values has the wrong length.
+func (s *AliasSampler) Variance(values []float64) (float64, error) {
+	mean, err := s.Mean(values)
+	if err != nil {
+		return 0, err
+	}
+
+	/* Summing squared deviations from the mean, rather than taking
+	 * E[v^2] - E[v]^2, avoids cancellation when the spread is small
+	 * compared to the mean.
+	 */
+	var variance float64
+	for i, p := range s.probabilities() {
+		if p > 0 {
+			d := values[i] - mean
+			variance += p * d * d
+		}
+	}
+	return variance, nil
+}
@@ -0,0 +1,61 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestMoments(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 20).Draw(t, "probs")
+		probs[0] += 0.001
+		values := rapid.SliceOfN(rapid.Float64Range(-100, 100), len(probs), len(probs)).Draw(t, "values")
+
+		as, err := InitWithSeed(probs, 1)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		mean, err := as.Mean(values)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		variance, err := as.Variance(values)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Check both against the weights themselves, which the table
+		 * only reproduces to within rounding.
+		 */
+		var tot, wantMean, wantVar float64
+		for _, p := range probs {
+			tot += p
+		}
+		for i, p := range probs {
+			wantMean += p / tot * values[i]
+		}
+		for i, p := range probs {
+			d := values[i] - wantMean
+			wantVar += p / tot * d * d
+		}
+		if math.Abs(mean-wantMean) > 1e-9*(1+math.Abs(wantMean)) {
+			t.Fatalf("got mean %g, want %g\n", mean, wantMean)
+		}
+		if variance < 0 || math.Abs(variance-wantVar) > 1e-9*(1+wantVar) {
+			t.Fatalf("got variance %g, want %g\n", variance, wantVar)
+		}
+	})
+
+	as, _ := Init([]float64{1, 3})
+	if mean, _ := as.Mean([]float64{0, 4}); math.Abs(mean-3) > 1e-12 {
+		t.Errorf("got mean %g, want 3\n", mean)
+	}
+	if variance, _ := as.Variance([]float64{0, 4}); math.Abs(variance-3) > 1e-12 {
+		t.Errorf("got variance %g, want 3\n", variance)
+	}
+	if _, err := as.Mean([]float64{1}); err == nil {
+		t.Errorf("short values were accepted\n")
+	}
+}
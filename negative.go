@@ -0,0 +1,78 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+)
+
+// UnigramPower is the exponent word2vec applies to token counts to build
+// its noise distribution, flattening it so rare tokens are drawn as
+// negatives more often than their frequency alone would give.
+const UnigramPower = 0.75
+
+// NegativeSampler draws negative examples for word2vec-style training:
+// token indices from the unigram distribution raised to UnigramPower,
+// never equal to the positive example they are drawn against.  Draws are
+// made in batches, as by NextN, with only the draws that hit the positive
+// redrawn, so avoiding it costs next to nothing unless it dominates the
+// distribution.
+type NegativeSampler struct {
+	s *AliasSampler
+}
+
+// NewNegativeSampler builds the noise distribution from how many times
+// each token occurs.  Tokens that never occur are never drawn.  Options
+// are applied to the table as for Init.
+func NewNegativeSampler(counts []uint64, opts ...Option) (*NegativeSampler, error) {
+	weights := make([]float64, len(counts))
+	for i, c := range counts {
+		weights[i] = math.Pow(float64(c), UnigramPower)
+	}
+	if err := checkWeights(weights); err != nil {
+		return nil, err
+	}
+	s, err := InitInPlace(weights, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &NegativeSampler{s: s}, nil
+}
+
+// Fill fills dst with negatives for the token positive.  A positive
+// outside [0, Len()) excludes nothing.  It returns an error, leaving dst
+// unspecified, if positive is the only token that can be drawn.
+func (ns *NegativeSampler) Fill(dst []int, positive int) error {
+	return ns.FillFrom(dst, positive, ns.s.source())
+}
+
+// FillFrom is Fill drawing from rng, so that training goroutines can each
+// use their own source.
+func (ns *NegativeSampler) FillFrom(dst []int, positive int, rng *r.Rand) error {
+	s := ns.s
+	s.nextNFrom(dst, rng)
+	excluded := func(i int) bool {
+		return i == positive
+	}
+	for k, i := range dst {
+		if i != positive {
+			continue
+		}
+		j, err := s.nextExcluding(rng, excluded)
+		if err != nil {
+			return err
+		}
+		dst[k] = j
+	}
+	s.record(dst)
+	return nil
+}
+
+// Len returns the number of tokens.
+func (ns *NegativeSampler) Len() int {
+	return ns.s.Len()
+}
+
+// Prob returns the chance that an unconditioned draw is token i.
+func (ns *NegativeSampler) Prob(i int) float64 {
+	return ns.s.Prob(i)
+}
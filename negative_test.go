@@ -0,0 +1,63 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestNegativeSampler(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		counts := rapid.SliceOfN(rapid.Uint64Range(0, 1000), 2, 20).Draw(t, "counts")
+		counts[0], counts[1] = counts[0]+1, counts[1]+1
+		positive := rapid.IntRange(-1, len(counts)-1).Draw(t, "positive")
+		ns, err := NewNegativeSampler(counts, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Draws follow counts^0.75, conditioned on missing the positive. */
+		want := make([]float64, len(counts))
+		var tot float64
+		for i, c := range counts {
+			if i != positive {
+				want[i] = math.Pow(float64(c), 0.75)
+				tot += want[i]
+			}
+		}
+		sz := 50_000
+		dst := make([]int, 50)
+		got := make([]float64, len(counts))
+		for range sz / len(dst) {
+			if err := ns.Fill(dst, positive); err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+			for _, i := range dst {
+				if i == positive {
+					t.Fatalf("drew the positive %d\n", i)
+				}
+				got[i]++
+			}
+		}
+		for i, c := range got {
+			p := want[i] / tot
+			if math.Abs(c-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+				t.Fatalf("drew %d %v times of %d, want p %v\n", i, c, sz, p)
+			}
+		}
+	})
+}
+
+func TestNegativeSamplerOnlyPositive(t *testing.T) {
+	ns, err := NewNegativeSampler([]uint64{0, 5, 0})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if err := ns.Fill(make([]int, 4), 1); err == nil {
+		t.Fatalf("expected error when only the positive can be drawn\n")
+	}
+	if _, err := NewNegativeSampler([]uint64{0, 0}); err == nil {
+		t.Fatalf("expected error for all-zero counts\n")
+	}
+}
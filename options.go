@@ -0,0 +1,159 @@
+package alias_sample
+
+import (
+	"log/slog"
+	r "math/rand"
+)
+
+// An Option adjusts how a sampler is constructed.  Options are applied in
+// order, so later options override earlier ones.
+type Option func(*config)
+
+type config struct {
+	name    string
+	seed    int64
+	seeded  bool
+	workers int
+	column  columnKind
+	squared bool
+
+	intAliases bool // store the alias column as []int even if it fits uint32
+
+	minProb, maxProb float64
+	track            bool
+	metrics          Metrics
+	logger           *slog.Logger
+
+	/* storage to build into instead of allocating */
+	probability   []float64
+	probability32 []float32
+	probability16 []uint16
+	alias         []int
+	alias32       []uint32
+	work          []int
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{workers: 1, maxProb: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	// only read the entropy source if WithSeed didn't set one
+	if !cfg.seeded {
+		cfg.seed = newSeed()
+	}
+	return cfg
+}
+
+// WithSeed seeds the sampler's random source, making its draws
+// reproducible.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed, c.seeded = seed, true
+	}
+}
+
+// WithBuffers lets the sampler use the caller's slices to hold its table
+// instead of allocating new ones.  A buffer is only used if its capacity is
+// at least the number of probabilities; otherwise a fresh slice is
+// allocated in its place.  Either buffer may be nil.  The probability
+// buffer is ignored under WithFloat32 and WithFixed16.  An alias buffer
+// that is big enough is used even though the indices would fit in uint32.
+// The sampler owns the buffers once it is built, so the caller must not
+// modify them afterwards.
+func WithBuffers(probability []float64, alias []int) Option {
+	return func(c *config) {
+		c.probability = probability
+		c.alias = alias
+	}
+}
+
+// WithoutBuffers cancels any WithBuffers earlier in the options, so that
+// the table gets storage of its own.  Types that build tables again and
+// again from the same options, such as Dynamic, add it themselves, since
+// each new table would otherwise overwrite ones still in use.
+func WithoutBuffers() Option {
+	return func(c *config) {
+		c.probability = nil
+		c.alias = nil
+	}
+}
+
+/* ownBuffers returns opts with WithoutBuffers added, leaving opts itself
+ * alone.
+ */
+func ownBuffers(opts []Option) []Option {
+	return append(opts[:len(opts):len(opts)], WithoutBuffers())
+}
+
+func (c *config) newRand() *r.Rand {
+	return r.New(r.NewSource(c.seed))
+}
+
+/* reuse returns buf resliced to length n if it is big enough, and a new
+ * slice otherwise.
+ */
+func reuse[T any](buf []T, n int) []T {
+	if cap(buf) >= n {
+		return buf[:n]
+	}
+	return make([]T, n)
+}
+
+/* columnKind selects how the probability column is stored. */
+type columnKind int
+
+const (
+	columnFloat64 columnKind = iota
+	columnFloat32
+	columnFixed16
+)
+
+// WithFloat32 stores the table's probability column as float32 rather than
+// float64, halving its memory.  Each entry then carries a relative error
+// of about 6e-8, which is far below what can be detected from samples.
+func WithFloat32() Option {
+	return func(c *config) {
+		c.column = columnFloat32
+	}
+}
+
+// WithIntAliases stores the table's alias column as int.  By default it is
+// stored as uint32 whenever the indices fit in an int32, which halves its
+// memory on 64-bit platforms, and together with WithFloat32 roughly
+// quarters the whole table.  The draws are the same either way; the
+// option is mostly for comparing the two layouts.
+func WithIntAliases() Option {
+	return func(c *config) {
+		c.intAliases = true
+	}
+}
+
+// WithFixed16 stores the table's probability column as 16-bit fixed point,
+// a quarter of the memory of float64.  Each column's probability is
+// rounded to a multiple of 1/65535, so it is off by at most 1/131070.
+// Since a column's rounding error only moves mass between the column and
+// its alias, for n entries:
+//
+//   - the total variation distance from the exact distribution is at most
+//     1/131070 (about 7.6e-6), and
+//   - index i is off by at most (1+k)/(131070*n), where k is the number of
+//     columns whose alias is i.
+//
+// In particular, an index whose normalized probability is below about
+// 1/(131070*n) may never be drawn.
+func WithFixed16() Option {
+	return func(c *config) {
+		c.column = columnFixed16
+	}
+}
+
+const fixed16One = 1<<16 - 1
+
+func toFixed16(p float64) uint16 {
+	return uint16(min(max(p, 0), 1)*fixed16One + 0.5)
+}
+
+func fromFixed16(q uint16) float64 {
+	return float64(q) / fixed16One
+}
@@ -0,0 +1,137 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestFloat32(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
+		as, err := Init(probs, WithFloat32())
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if as.probability != nil {
+			t.Fatalf("float64 column was allocated")
+		}
+
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		for i, p := range tableProbs(as) {
+			want := probs[i] / tot
+			if math.Abs(p-want) > 1e-6*want {
+				t.Fatalf("index %d: got %g, want %g\n", i, p, want)
+			}
+		}
+	})
+}
+
+func TestFixed16(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 100).Draw(t, "probs")
+		as, err := Init(probs, WithFloat32(), WithFixed16())
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if as.probability != nil || as.probability32 != nil {
+			t.Fatalf("wrong column was allocated")
+		}
+
+		n := float64(len(probs))
+		aliased := make([]int, len(probs))
+		for i := range len(probs) {
+			if a := as.aliasOf(i); a != i {
+				aliased[a]++
+			}
+		}
+
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		var tv float64
+		for i, p := range tableProbs(as) {
+			want := probs[i] / tot
+			tv += math.Abs(p-want) / 2
+			bound := float64(1+aliased[i]) / (2 * fixed16One * n)
+			if math.Abs(p-want) > bound+1e-15 {
+				t.Fatalf("index %d: got %g, want %g, bound %g\n", i, p, want, bound)
+			}
+		}
+		if tv > 1.0/(2*fixed16One)+1e-15 {
+			t.Fatalf("total variation %g exceeds bound\n", tv)
+		}
+	})
+}
+
+func TestIntAliases(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), tinyLen+1, 100).Draw(t, "probs")
+		probs[0] += 1
+		seed := rapid.Int64().Draw(t, "seed")
+
+		narrow, err := InitWithSeed(probs, seed)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		wide, err := InitWithSeed(probs, seed, WithIntAliases())
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if narrow.alias32 == nil || narrow.alias != nil || wide.alias == nil || wide.alias32 != nil {
+			t.Fatalf("alias columns were stored the wrong way\n")
+		}
+		for i := range len(probs) {
+			if int(narrow.alias32[i]) != wide.alias[i] {
+				t.Fatalf("alias %d is %d, want %d\n", i, narrow.alias32[i], wide.alias[i])
+			}
+		}
+
+		/* A decoded table, and one from BuildMany, store them the same
+		 * way, and every path draws the same indices.
+		 */
+		data, err := wide.MarshalBinary()
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		loaded, err := Load(data)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		many, err := BuildMany([][]float64{probs}, WithSeed(seed))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if loaded.alias32 == nil || many[0].alias32 == nil {
+			t.Fatalf("decoded or batch-built table stored int aliases\n")
+		}
+		a, b, c := make([]int, 50), make([]int, 50), make([]int, 50)
+		narrow.NextN(a)
+		wide.NextN(b)
+		many[0].NextN(c)
+		for i := range a {
+			if a[i] != b[i] || a[i] != c[i] {
+				t.Fatalf("batched draws %d are %d, %d and %d\n", i, a[i], b[i], c[i])
+			}
+		}
+		for range 50 {
+			x, y, z := narrow.Next(), wide.Next(), many[0].Next()
+			if x != y || x != z {
+				t.Fatalf("drew %d, %d and %d from the same table\n", x, y, z)
+			}
+		}
+		loaded.nextNFrom(a, r.New(r.NewSource(1)))
+		wide.nextNFrom(b, r.New(r.NewSource(1)))
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("decoded draw %d is %d, want %d\n", i, a[i], b[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package alias_sample
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelChunk is the number of entries each worker handles at a time
+// when building in parallel.  The chunk layout depends only on n, never
+// on the number of workers, so a given seed and input always produce the
+// same table.
+const parallelChunk = 1 << 20
+
+// WithParallelism builds the table using up to workers goroutines.  A
+// value of zero or less means runtime.GOMAXPROCS(0).  Inputs with fewer
+// than two chunks' worth of entries are always built sequentially.
+//
+// A parallel build samples from the same distribution as a sequential one,
+// but the table (and so the sequence of draws for a given seed) differs
+// slightly, since the pairing happens in a different order.
+func WithParallelism(workers int) Option {
+	return func(c *config) {
+		c.workers = workers
+	}
+}
+
+/* buildParallel splits the index space into fixed-size chunks and runs the
+ * pairing loop on each chunk independently, using the global average.  Any
+ * pairing of a small entry with a large one is valid, so the only thing
+ * lost is that each chunk may end up with unpaired entries left over.
+ * Those are gathered up and paired sequentially at the end.
+ */
+func (v *vose) buildParallel(workers int) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	n := len(v.probs2)
+	chunks := (n + parallelChunk - 1) / parallelChunk
+	bounds := func(c int) (int, int) {
+		return c * parallelChunk, min((c+1)*parallelChunk, n)
+	}
+
+	run := func(f func(c int)) {
+		var wg sync.WaitGroup
+		next := make(chan int)
+		for range min(workers, chunks) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := range next {
+					f(c)
+				}
+			}()
+		}
+		for c := range chunks {
+			next <- c
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	/* Sum each chunk separately, then combine the partial sums in chunk
+	 * order so that the total doesn't depend on scheduling.
+	 */
+	partial := make([]float64, chunks)
+	run(func(c int) {
+		partial[c] = v.total(bounds(c))
+	})
+	var tot float64
+	for _, p := range partial {
+		tot += p
+	}
+	v.diag.total = tot
+	run(func(c int) {
+		lo, hi := bounds(c)
+		v.normalize(lo, hi, tot)
+	})
+
+	work := make([]int, n)
+	nSmall := make([]int, chunks)
+	nLarge := make([]int, chunks)
+	initSmall := make([]int, chunks)
+	run(func(c int) {
+		lo, hi := bounds(c)
+		ns, nl := v.classify(lo, work[lo:hi])
+		initSmall[c] = ns
+		nSmall[c], nLarge[c] = v.pair(work[lo:hi], ns, nl)
+	})
+	for _, ns := range initSmall {
+		v.diag.small += ns
+	}
+	v.diag.large = n - v.diag.small
+
+	/* Stitch the leftovers of every chunk into one pair of stacks. */
+	var totSmall, totLarge int
+	for c := range chunks {
+		totSmall += nSmall[c]
+		totLarge += nLarge[c]
+	}
+	rest := make([]int, totSmall+totLarge)
+	s, l := 0, len(rest)
+	for c := range chunks {
+		lo, hi := bounds(c)
+		s += copy(rest[s:], work[lo:lo+nSmall[c]])
+		l -= nLarge[c]
+		copy(rest[l:], work[hi-nLarge[c]:hi])
+	}
+
+	ns, nl := v.pair(rest, totSmall, totLarge)
+	v.finish(rest, ns, nl)
+}
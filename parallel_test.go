@@ -0,0 +1,36 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"slices"
+	"testing"
+)
+
+func TestParallelBuild(t *testing.T) {
+	rng := r.New(r.NewSource(1))
+	probs := make([]float64, 2*parallelChunk+12345)
+	var tot float64
+	for i := range probs {
+		probs[i] = rng.ExpFloat64()
+		tot += probs[i]
+	}
+
+	a, err := InitWithSeed(probs, 7, WithParallelism(2))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	b, err := InitWithSeed(probs, 7, WithParallelism(5))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if !slices.Equal(a.probability, b.probability) || !slices.Equal(a.alias32, b.alias32) {
+		t.Fatalf("table depends on the number of workers")
+	}
+
+	for i, p := range tableProbs(a) {
+		if math.Abs(p-probs[i]/tot) > 1e-12 {
+			t.Fatalf("index %d: got %g, want %g\n", i, p, probs[i]/tot)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package picker chooses among weighted endpoints, such as the backends of
+// a service: Picker for each request, skipping the ones that are
+// unhealthy, and Sticky for keys that should keep going to the same one.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	r "math/rand"
+	"sync"
+	"sync/atomic"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// ErrNoEndpoints is returned by Pick when no endpoint is both healthy and
+// given a positive weight.
+var ErrNoEndpoints = errors.New("picker: no healthy endpoints")
+
+// Endpoint is one thing to pick and its weight.  Weights need not sum to
+// anything in particular; a weight of zero keeps the endpoint in the set
+// without ever picking it.
+type Endpoint[T comparable] struct {
+	Value  T
+	Weight float64
+}
+
+// Picker picks among endpoints with probability proportional to their
+// weights, leaving out any marked unhealthy.  Every change of weights or
+// health builds a new alias table off to the side and swaps it in
+// atomically, so Pick never waits on a change and never takes a lock.
+// All methods are safe for concurrent use.
+type Picker[T comparable] struct {
+	current atomic.Pointer[table[T]]
+	rands   sync.Pool
+	opts    []alias_sample.Option
+
+	mu        sync.Mutex // serializes changes
+	endpoints []Endpoint[T]
+	unhealthy map[T]bool
+}
+
+/* table is an immutable snapshot of what can be picked: the healthy
+ * endpoints with positive weight and a sampler over them, which is nil if
+ * there are none.
+ */
+type table[T comparable] struct {
+	values []T
+	s      *alias_sample.AliasSampler
+}
+
+// New returns a Picker over endpoints, all of which start out healthy.
+// The set may be empty, and filled in later with Update.  The options apply
+// to every table the Picker builds, apart from alias_sample.WithBuffers,
+// since every table needs storage of its own.
+func New[T comparable](endpoints []Endpoint[T], opts ...alias_sample.Option) (*Picker[T], error) {
+	opts = append(opts[:len(opts):len(opts)], alias_sample.WithoutBuffers())
+	p := &Picker[T]{opts: opts, unhealthy: map[T]bool{}}
+	p.rands.New = func() any {
+		return r.New(r.NewSource(r.Int63()))
+	}
+	if err := p.Update(endpoints); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Pick returns a healthy endpoint's value, or ErrNoEndpoints.
+func (p *Picker[T]) Pick() (T, error) {
+	rng := p.rands.Get().(*r.Rand)
+	v, err := p.PickFrom(rng)
+	p.rands.Put(rng)
+	return v, err
+}
+
+// PickFrom is Pick using rng, for reproducible picks.  rng must not be
+// used concurrently.
+func (p *Picker[T]) PickFrom(rng *r.Rand) (T, error) {
+	t := p.current.Load()
+	if t.s == nil {
+		var zero T
+		return zero, ErrNoEndpoints
+	}
+	return t.values[t.s.NextFrom(rng)], nil
+}
+
+// Update replaces the endpoints and their weights.  Endpoints that were
+// already present keep their health; new ones start out healthy.  If a
+// value appears twice, or a weight is negative or not finite, the old
+// endpoints stay in use and an error is returned.
+func (p *Picker[T]) Update(endpoints []Endpoint[T]) error {
+	seen := make(map[T]bool, len(endpoints))
+	for _, e := range endpoints {
+		if seen[e.Value] {
+			return fmt.Errorf("picker: endpoint %v appears twice", e.Value)
+		}
+		seen[e.Value] = true
+		if !alias_sample.ValidWeight(e.Weight) {
+			return fmt.Errorf("picker: endpoint %v has weight %v", e.Value, e.Weight)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	unhealthy := make(map[T]bool, len(p.unhealthy))
+	for v := range p.unhealthy {
+		if seen[v] {
+			unhealthy[v] = true
+		}
+	}
+	return p.swap(append([]Endpoint[T](nil), endpoints...), unhealthy)
+}
+
+// SetHealthy marks the endpoint with value v healthy or not, and reports
+// whether there is such an endpoint.  It returns an error, and leaves the
+// endpoint's health as it was, if the options the Picker was built with
+// can't be met by the endpoints that would then be healthy, such as
+// alias_sample.WithMaxProb with only one left.
+func (p *Picker[T]) SetHealthy(v T, healthy bool) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	known := false
+	for _, e := range p.endpoints {
+		known = known || e.Value == v
+	}
+	if !known || p.unhealthy[v] == !healthy {
+		return known, nil
+	}
+
+	unhealthy := make(map[T]bool, len(p.unhealthy)+1)
+	for u := range p.unhealthy {
+		unhealthy[u] = true
+	}
+	if healthy {
+		delete(unhealthy, v)
+	} else {
+		unhealthy[v] = true
+	}
+	return true, p.swap(p.endpoints, unhealthy)
+}
+
+// Endpoints returns the endpoints and their weights, healthy or not.
+func (p *Picker[T]) Endpoints() []Endpoint[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Endpoint[T](nil), p.endpoints...)
+}
+
+// Healthy returns the values of the endpoints that Pick can return: those
+// that are healthy and have a positive weight.
+func (p *Picker[T]) Healthy() []T {
+	return append([]T(nil), p.current.Load().values...)
+}
+
+/* swap builds the table for endpoints and unhealthy, and makes them
+ * current.  p.mu must be held.
+ */
+func (p *Picker[T]) swap(endpoints []Endpoint[T], unhealthy map[T]bool) error {
+	t := &table[T]{}
+	var weights []float64
+	for _, e := range endpoints {
+		if e.Weight > 0 && !unhealthy[e.Value] {
+			t.values = append(t.values, e.Value)
+			weights = append(weights, e.Weight)
+		}
+	}
+	if len(weights) > 0 {
+		s, err := alias_sample.Init(weights, p.opts...)
+		if err != nil {
+			return err
+		}
+		t.s = s
+	}
+	p.endpoints, p.unhealthy = endpoints, unhealthy
+	p.current.Store(t)
+	return nil
+}
@@ -0,0 +1,144 @@
+package picker
+
+import (
+	"errors"
+	"math"
+	r "math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func TestPick(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 20).Draw(t, "weights")
+		endpoints := make([]Endpoint[int], len(weights))
+		for i, w := range weights {
+			endpoints[i] = Endpoint[int]{Value: 100 + i, Weight: w}
+		}
+		p, err := New(endpoints)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		down := rapid.SliceOfDistinct(rapid.IntRange(0, len(weights)-1), rapid.ID[int]).Draw(t, "down")
+		for _, i := range down {
+			if known, err := p.SetHealthy(100+i, false); !known || err != nil {
+				t.Fatalf("endpoint %d: known %v, err %v\n", 100+i, known, err)
+			}
+		}
+
+		var total float64
+		for i, w := range weights {
+			if !slices.Contains(down, i) {
+				total += w
+			}
+		}
+		rng := r.New(r.NewSource(rapid.Int64().Draw(t, "seed")))
+		sz := 100_000
+		counts := map[int]int{}
+		for range sz {
+			v, err := p.PickFrom(rng)
+			if len(down) == len(weights) {
+				if !errors.Is(err, ErrNoEndpoints) {
+					t.Fatalf("picked %d, err %v, with every endpoint down\n", v, err)
+				}
+				return
+			}
+			counts[v]++
+		}
+		for i, w := range weights {
+			want := w / total
+			if slices.Contains(down, i) {
+				want = 0
+			}
+			if got := float64(counts[100+i]) / float64(sz); math.Abs(got-want) > 0.01 {
+				t.Fatalf("endpoint %d: picked %v of the time, want %v\n", 100+i, got, want)
+			}
+		}
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	p, err := New[string](nil)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if _, err := p.Pick(); !errors.Is(err, ErrNoEndpoints) {
+		t.Fatalf("empty picker: got err %v\n", err)
+	}
+	p.Update([]Endpoint[string]{{"a", 1}, {"b", 1}, {"c", 0}})
+	p.SetHealthy("a", false)
+
+	/* b keeps its health, a stays down, and d starts healthy. */
+	if err := p.Update([]Endpoint[string]{{"a", 2}, {"b", 2}, {"d", 1}}); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if got := p.Healthy(); !slices.Equal(got, []string{"b", "d"}) {
+		t.Fatalf("healthy: got %v\n", got)
+	}
+	p.SetHealthy("a", true)
+	if got := p.Healthy(); !slices.Equal(got, []string{"a", "b", "d"}) {
+		t.Fatalf("healthy: got %v\n", got)
+	}
+	if known, _ := p.SetHealthy("c", false); known {
+		t.Fatalf("removed endpoint c is still known\n")
+	}
+
+	for _, bad := range [][]Endpoint[string]{
+		{{"a", 1}, {"a", 2}},
+		{{"a", -1}},
+		{{"a", math.NaN()}},
+		{{"a", math.Inf(1)}},
+	} {
+		if err := p.Update(bad); err == nil {
+			t.Fatalf("accepted %v\n", bad)
+		}
+	}
+	if got := p.Endpoints(); len(got) != 3 || got[2] != (Endpoint[string]{"d", 1}) {
+		t.Fatalf("failed update changed endpoints to %v\n", got)
+	}
+}
+
+func TestSetHealthyInvalid(t *testing.T) {
+	/* A cap of 0.6 can't be met once only one endpoint is left. */
+	p, err := New([]Endpoint[string]{{"a", 1}, {"b", 1}}, alias_sample.WithMaxProb(0.6))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if known, err := p.SetHealthy("a", false); !known || err == nil {
+		t.Fatalf("known %v, err %v\n", known, err)
+	}
+	if got := p.Healthy(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("healthy after a failed change: got %v\n", got)
+	}
+	if known, err := p.SetHealthy("a", false); !known || err == nil {
+		t.Fatalf("second try: known %v, err %v\n", known, err)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	p, _ := New([]Endpoint[int]{{1, 1}, {2, 1}})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10_000 {
+				if v, err := p.Pick(); err != nil || (v != 1 && v != 2 && v != 3) {
+					t.Errorf("picked %d, err %v\n", v, err)
+					return
+				}
+			}
+		}()
+	}
+	for i := range 100 {
+		p.Update([]Endpoint[int]{{1, 1}, {2, 1}, {3, float64(i)}})
+		p.SetHealthy(1, i%2 == 0)
+	}
+	wg.Wait()
+}
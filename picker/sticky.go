@@ -0,0 +1,189 @@
+package picker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Sticky assigns keys, such as session IDs, to weighted shards and keeps
+// them there.  The first time a key is seen it is given a random order of
+// the shards, drawn by weight without replacement: the first shard is its
+// home, with probability proportional to its weight, and the rest are its
+// fallbacks.  The assignment is kept for the TTL, and a key whose home is
+// removed moves to the first of its fallbacks still present, so changes
+// to the shard set only move the keys they have to.  Invalidate forces a
+// fresh draw.  All methods are safe for concurrent use.
+type Sticky[K comparable, T comparable] struct {
+	ttl  time.Duration
+	now  func() time.Time
+	opts []alias_sample.Option
+
+	mu        sync.Mutex
+	shards    []Endpoint[T]
+	present   map[T]bool
+	w         *alias_sample.Weighted // over shards; restored after each draw
+	keys      map[K]*assignment[T]
+	puts      int // assignments since the last sweep
+	nextSweep int // how many puts to allow before the next one
+}
+
+type assignment[T comparable] struct {
+	order   []T
+	expires time.Time // zero if it never does
+}
+
+// NewSticky returns a Sticky over shards.  A ttl of zero keeps assignments
+// until they are invalidated.  The options are applied as for
+// alias_sample.NewWeighted; only WithSeed matters.
+func NewSticky[K comparable, T comparable](shards []Endpoint[T], ttl time.Duration, opts ...alias_sample.Option) (*Sticky[K, T], error) {
+	if ttl < 0 {
+		return nil, fmt.Errorf("picker: negative TTL %v", ttl)
+	}
+	s := &Sticky[K, T]{ttl: ttl, now: time.Now, opts: opts, keys: map[K]*assignment[T]{}}
+	if err := s.setShards(shards); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns key's shard, assigning it one if it has none, or
+// ErrNoEndpoints if every shard has weight zero.
+func (s *Sticky[K, T]) Get(key K) (T, error) {
+	order, err := s.Fallbacks(key)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return order[0], nil
+}
+
+// Fallbacks returns key's shards in the order to try them: its home, then
+// the others.  Shards added since the key was assigned are left out until
+// it is reassigned.
+func (s *Sticky[K, T]) Fallbacks(key K) ([]T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if a, ok := s.keys[key]; ok && (a.expires.IsZero() || now.Before(a.expires)) {
+		var order []T
+		for _, v := range a.order {
+			if s.present[v] {
+				order = append(order, v)
+			}
+		}
+		if len(order) > 0 {
+			return order, nil
+		}
+	}
+
+	a := &assignment[T]{order: s.draw()}
+	if len(a.order) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	if s.ttl > 0 {
+		a.expires = now.Add(s.ttl)
+	}
+	s.keys[key] = a
+	s.sweep(now)
+	return append([]T(nil), a.order...), nil
+}
+
+// Invalidate forgets key's assignment, so that the next Get draws a new
+// one.
+func (s *Sticky[K, T]) Invalidate(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+}
+
+// SetShards replaces the shards.  Keys keep their assignments; those whose
+// home is gone move to their next fallback.  New weights only affect keys
+// assigned from now on.  On error the old shards stay in use.
+func (s *Sticky[K, T]) SetShards(shards []Endpoint[T]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.setShards(shards)
+}
+
+// Len returns the number of keys with an assignment, including expired
+// ones not yet cleared out.
+func (s *Sticky[K, T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.keys)
+}
+
+/* setShards validates shards and builds the tree over them.  s.mu must be
+ * held, except during construction.
+ */
+func (s *Sticky[K, T]) setShards(shards []Endpoint[T]) error {
+	present := make(map[T]bool, len(shards))
+	weights := make([]float64, len(shards))
+	for i, e := range shards {
+		if present[e.Value] {
+			return fmt.Errorf("picker: shard %v appears twice", e.Value)
+		}
+		present[e.Value] = true
+		if !alias_sample.ValidWeight(e.Weight) {
+			return fmt.Errorf("picker: shard %v has weight %v", e.Value, e.Weight)
+		}
+		weights[i] = e.Weight
+	}
+	if len(shards) == 0 {
+		weights = []float64{0}
+	}
+
+	if s.w == nil || s.w.Len() != len(weights) {
+		w, err := alias_sample.NewWeighted(weights, s.opts...)
+		if err != nil {
+			return err
+		}
+		s.w = w
+	} else if err := s.w.ReweightAll(weights); err != nil {
+		return err
+	}
+	s.shards = append([]Endpoint[T](nil), shards...)
+	s.present = present
+	return nil
+}
+
+/* draw takes every shard with positive weight out of the tree in weighted
+ * random order, then puts them back.  s.mu must be held.
+ */
+func (s *Sticky[K, T]) draw() []T {
+	var order []T
+	for {
+		i, ok := s.w.Take()
+		if !ok {
+			break
+		}
+		order = append(order, s.shards[i].Value)
+	}
+	for i, e := range s.shards {
+		s.w.Reweight(i, e.Weight)
+	}
+	return order
+}
+
+/* sweepMin is the fewest assignments made between sweeps. */
+const sweepMin = 64
+
+/* sweep clears out expired assignments once there have been as many new
+ * ones as there were keys left by the last sweep, which keeps the cost per
+ * assignment constant.  s.mu must be held.
+ */
+func (s *Sticky[K, T]) sweep(now time.Time) {
+	s.puts++
+	if s.ttl == 0 || s.puts < max(s.nextSweep, sweepMin) {
+		return
+	}
+	for key, a := range s.keys {
+		if !now.Before(a.expires) {
+			delete(s.keys, key)
+		}
+	}
+	s.puts, s.nextSweep = 0, len(s.keys)
+}
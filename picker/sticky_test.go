@@ -0,0 +1,95 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+	"pgregory.net/rapid"
+)
+
+func TestSticky(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := rapid.SliceOfN(rapid.Float64Range(0.1, 5.0), 1, 10).Draw(t, "weights")
+		shards := make([]Endpoint[string], len(weights))
+		var total float64
+		for i, w := range weights {
+			shards[i] = Endpoint[string]{Value: fmt.Sprint("shard", i), Weight: w}
+			total += w
+		}
+		s, err := NewSticky[int](shards, 0, alias_sample.WithSeed(rapid.Int64().Draw(t, "seed")))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		sz := 20_000
+		counts := map[string]float64{}
+		for key := range sz {
+			home, _ := s.Get(key)
+			counts[home]++
+			order, _ := s.Fallbacks(key)
+			if order[0] != home || len(order) != len(shards) {
+				t.Fatalf("key %d: home %s, fallbacks %v\n", key, home, order)
+			}
+		}
+		for _, e := range shards {
+			if got := counts[e.Value] / float64(sz); math.Abs(got-e.Weight/total) > 0.02 {
+				t.Fatalf("%s is home to %v of keys, want %v\n", e.Value, got, e.Weight/total)
+			}
+		}
+	})
+}
+
+func TestStickyChanges(t *testing.T) {
+	now := time.Unix(0, 0)
+	shards := []Endpoint[string]{{"a", 1}, {"b", 1}, {"c", 1}}
+	s, _ := NewSticky[int](shards, time.Minute, alias_sample.WithSeed(3))
+	s.now = func() time.Time { return now }
+
+	before := map[int][]string{}
+	for key := range 300 {
+		before[key], _ = s.Fallbacks(key)
+	}
+
+	/* Removing b moves only its keys, each to its first fallback. */
+	s.SetShards([]Endpoint[string]{{"a", 1}, {"c", 5}})
+	for key, order := range before {
+		got, _ := s.Get(key)
+		want := order[0]
+		if want == "b" {
+			want = order[1]
+		}
+		if got != want {
+			t.Fatalf("key %d with order %v moved to %s\n", key, order, got)
+		}
+	}
+
+	/* Invalidating or expiring an assignment draws a new one. */
+	s.Invalidate(0)
+	if order, _ := s.Fallbacks(0); slices.Contains(order, "b") || len(order) != 2 {
+		t.Fatalf("reassigned key has order %v\n", order)
+	}
+	now = now.Add(2 * time.Minute)
+	for key := range 1000 {
+		s.Get(1000 + key)
+	}
+	if s.Len() >= 1300 {
+		t.Fatalf("%d assignments kept after expiry\n", s.Len())
+	}
+
+	s.SetShards([]Endpoint[string]{{"a", 0}})
+	s.Invalidate(1)
+	if _, err := s.Get(1); !errors.Is(err, ErrNoEndpoints) {
+		t.Fatalf("all-zero shards: got err %v\n", err)
+	}
+	if err := s.SetShards([]Endpoint[string]{{"a", 1}, {"a", 1}}); err == nil {
+		t.Fatalf("duplicate shards were accepted\n")
+	}
+	if _, err := NewSticky[int](shards, -time.Second); err == nil {
+		t.Fatalf("negative TTL was accepted\n")
+	}
+}
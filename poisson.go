@@ -0,0 +1,147 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+)
+
+/* poissonMinPTRS is the smallest rate served by PTRS rather than a table. */
+const poissonMinPTRS = 10
+
+// PoissonSampler draws Poisson variates with rate lambda.
+//
+// For lambda below 10 it samples an alias table of the probability mass
+// function over 0..K, with one extra column for the tail beyond K; a draw
+// that lands in the tail is finished by inverting the tail's conditional
+// distribution, so the result is exact.  For larger lambda it uses
+// Hörmann's PTRS transformed rejection method ("The transformed rejection
+// method for generating Poisson random variables", Insurance: Mathematics
+// and Economics 12(1), 1993).  Either way draws come from the same seeded
+// stream as the rest of the package.
+type PoissonSampler struct {
+	seed int64
+	rand *r.Rand
+
+	lambda float64
+	table  *AliasSampler // nil for large lambda
+	k      int           // the tail column of the table
+	tail   float64       // the mass beyond k
+
+	/* PTRS parameters */
+	a, b, invAlpha, vr, logLambda float64
+}
+
+// NewPoisson returns a Poisson sampler with rate lambda, which must be
+// finite and non-negative.  Further options are applied to the table, if
+// one is used, as for Init.
+func NewPoisson(lambda float64, seed int64, opts ...Option) (*PoissonSampler, error) {
+	if !(lambda >= 0) || math.IsInf(lambda, 1) {
+		return nil, &SampleError{"poisson: lambda must be finite and non-negative"}
+	}
+
+	s := &PoissonSampler{seed: seed, rand: r.New(r.NewSource(seed)), lambda: lambda}
+	if lambda >= poissonMinPTRS {
+		slam := math.Sqrt(lambda)
+		s.logLambda = math.Log(lambda)
+		s.b = 0.931 + 2.53*slam
+		s.a = -0.059 + 0.02483*s.b
+		s.invAlpha = 1.1239 + 1.1328/(s.b-3.4)
+		s.vr = 0.9277 - 3.6224/(s.b-2)
+		return s, nil
+	}
+
+	/* Going ten standard deviations past the mean leaves a tail too small
+	 * to matter for speed; it is still handled exactly.
+	 */
+	k := int(math.Ceil(lambda + 10*math.Sqrt(lambda) + 10))
+	if err := s.buildTable(k, opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/* buildTable sets s up to sample from a table over 0..k-1 plus a tail
+ * column.
+ */
+func (s *PoissonSampler) buildTable(k int, opts []Option) error {
+	s.k = k
+	weights := make([]float64, k+1)
+	pk := math.Exp(-s.lambda)
+	for i := range k {
+		weights[i] = pk
+		pk *= s.lambda / float64(i+1)
+	}
+
+	/* Sum the tail directly rather than taking 1 - head, which would
+	 * cancel away to noise.  Past the mean the terms fall off faster
+	 * than geometrically, so this stops quickly.
+	 */
+	s.tail = 0
+	for i := k; pk > 0 && (float64(i) <= s.lambda || pk > s.tail*0x1p-60); i++ {
+		s.tail += pk
+		pk *= s.lambda / float64(i+1)
+	}
+	weights[k] = s.tail
+
+	table, err := InitInPlace(weights, append(opts[:len(opts):len(opts)], WithSeed(s.seed))...)
+	if err != nil {
+		return err
+	}
+	s.table = table
+	s.rand = table.source()
+	return nil
+}
+
+func (s *PoissonSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *PoissonSampler) NextFrom(rng *r.Rand) int {
+	if s.table == nil {
+		return s.ptrs(rng)
+	}
+
+	k := s.table.NextFrom(rng)
+	if k < s.k {
+		return k
+	}
+
+	/* Invert the conditional distribution of the tail by walking the mass
+	 * function up from its start.
+	 */
+	pk := math.Exp(-s.lambda + float64(k)*math.Log(s.lambda) - lgamma(float64(k)+1))
+	u := rng.Float64() * s.tail
+	for {
+		u -= pk
+		if u < 0 || pk == 0 {
+			return k
+		}
+		k++
+		pk *= s.lambda / float64(k)
+	}
+}
+
+func (s *PoissonSampler) ptrs(rng *r.Rand) int {
+	for {
+		u := rng.Float64() - 0.5
+		v := rng.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*s.a/us+s.b)*u + s.lambda + 0.43)
+
+		if us >= 0.07 && v <= s.vr {
+			return int(k)
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		if math.Log(v)+math.Log(s.invAlpha)-math.Log(s.a/(us*us)+s.b) <=
+			-s.lambda+k*s.logLambda-lgamma(k+1) {
+			return int(k)
+		}
+	}
+}
+
+func lgamma(x float64) float64 {
+	lg, _ := math.Lgamma(x)
+	return lg
+}
@@ -0,0 +1,77 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPoisson(t *testing.T) {
+	for _, lambda := range []float64{0.5, 3, 9.9, 10, 250, 1e6} {
+		s, err := NewPoisson(lambda, 1)
+		if err != nil {
+			t.Fatalf("lambda=%g: got err %v\n", lambda, err)
+		}
+
+		sz := 100_000
+		counts := map[int]int{}
+		var sum, sumSq float64
+		for range sz {
+			k := s.Next()
+			if k < 0 {
+				t.Fatalf("lambda=%g: drew %d\n", lambda, k)
+			}
+			counts[k]++
+			sum += float64(k)
+			sumSq += float64(k) * float64(k)
+		}
+
+		mean := sum / float64(sz)
+		variance := sumSq/float64(sz) - mean*mean
+		if math.Abs(mean-lambda) > 5*math.Sqrt(lambda/float64(sz)) {
+			t.Errorf("lambda=%g: mean %g\n", lambda, mean)
+		}
+		if math.Abs(variance-lambda) > 0.03*lambda {
+			t.Errorf("lambda=%g: variance %g\n", lambda, variance)
+		}
+
+		mode := int(lambda)
+		for k := max(mode-2, 0); k <= mode+2; k++ {
+			want := math.Exp(-lambda + float64(k)*math.Log(lambda) - lgamma(float64(k)+1))
+			got := float64(counts[k]) / float64(sz)
+			if math.Abs(got-want) > 5*math.Sqrt(want/float64(sz))+1e-4 {
+				t.Errorf("lambda=%g: P(%d) = %g, want %g\n", lambda, k, got, want)
+			}
+		}
+	}
+
+	/* Shrink the table so that a good share of draws go through the
+	 * tail column.
+	 */
+	s, _ := NewPoisson(3, 1)
+	if err := s.buildTable(2, nil); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	sz := 100_000
+	counts := make([]int, 8)
+	for range sz {
+		if k := s.Next(); k < len(counts) {
+			counts[k]++
+		}
+	}
+	for k, c := range counts {
+		want := math.Exp(-3 + float64(k)*math.Log(3) - lgamma(float64(k)+1))
+		if got := float64(c) / float64(sz); math.Abs(got-want) > 5*math.Sqrt(want/float64(sz)) {
+			t.Errorf("short table: P(%d) = %g, want %g\n", k, got, want)
+		}
+	}
+
+	z, err := NewPoisson(0, 1)
+	if err != nil || z.Next() != 0 {
+		t.Errorf("lambda=0 drew a nonzero value or failed: %v\n", err)
+	}
+	for _, bad := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if _, err := NewPoisson(bad, 1); err == nil {
+			t.Errorf("lambda=%g was accepted\n", bad)
+		}
+	}
+}
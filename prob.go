@@ -0,0 +1,82 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"slices"
+	"sync/atomic"
+)
+
+// Prob returns the normalized probability of drawing index i, as implied
+// by the table, or 0 if i is out of range.  The first call recovers the
+// whole distribution from the table, in O(n), and keeps it.  Like
+// NextFrom, it is safe to call concurrently.
+func (s *AliasSampler) Prob(i int) float64 {
+	if i < 0 || i >= s.n {
+		return 0
+	}
+	return s.probabilities()[i]
+}
+
+// Probabilities returns a copy of the normalized distribution the sampler
+// draws from.  It is recovered from the table, as Prob's is, so it agrees
+// with Prob exactly, and with the normalized weights up to the rounding of
+// the build; see Stats.Residual.
+func (s *AliasSampler) Probabilities() []float64 {
+	return slices.Clone(s.probabilities())
+}
+
+// NextP draws an index as Next does, and returns it with its normalized
+// probability, for importance weighting.  As with Prob, the first call
+// recovers the whole distribution and keeps it.
+func (s *AliasSampler) NextP() (index int, p float64) {
+	return s.NextPFrom(s.source())
+}
+
+// NextPFrom is NextP drawing from rng.  Like NextFrom, it may be called
+// concurrently as long as each goroutine uses its own rng.
+func (s *AliasSampler) NextPFrom(rng *r.Rand) (index int, p float64) {
+	dist := s.probabilities()
+	i := s.NextFrom(rng)
+	return i, dist[i]
+}
+
+// MassOf returns the probability of drawing any of indices.  indices is
+// treated as a set, so repeated indices count once, and indices out of
+// range count for nothing.
+func (s *AliasSampler) MassOf(indices []int) float64 {
+	set := slices.Clone(indices)
+	slices.Sort(set)
+	set = slices.Compact(set)
+
+	var mass float64
+	for _, i := range set {
+		mass += s.Prob(i)
+	}
+	return min(mass, 1)
+}
+
+/* tableCache holds what a sampler works out from its table on first use.
+ * The table never changes, so neither does anything derived from it, and
+ * samplers sharing a table can share its cache.  Goroutines racing to fill
+ * an entry each compute it, and the first to finish wins.  It sits behind
+ * a pointer so that the sampler itself stays copyable.
+ */
+type tableCache struct {
+	dist atomic.Pointer[[]float64]  // the distribution; see probabilities
+	sums atomic.Pointer[CDFSampler] // running sums; see runningSums
+}
+
+/* probabilities returns the table's distribution, recovering it the first
+ * time it is asked for.  Callers must not modify the result.
+ */
+func (s *AliasSampler) probabilities() []float64 {
+	if s.cache == nil {
+		return s.distribution()
+	}
+	if dist := s.cache.dist.Load(); dist != nil {
+		return *dist
+	}
+	dist := s.distribution()
+	s.cache.dist.CompareAndSwap(nil, &dist)
+	return *s.cache.dist.Load()
+}
@@ -0,0 +1,120 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestProb(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+		set := rapid.SliceOf(rapid.IntRange(-2, len(probs)+2)).Draw(t, "set")
+
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		as, _ := Init(probs)
+		for i, p := range probs {
+			if got := as.Prob(i); math.Abs(got-p/tot) > 1e-9 {
+				t.Fatalf("Prob(%d): got %g, want %g\n", i, got, p/tot)
+			}
+		}
+		if as.Prob(-1) != 0 || as.Prob(len(probs)) != 0 {
+			t.Fatalf("out of range indices have nonzero probability\n")
+		}
+
+		seen := map[int]bool{}
+		var want float64
+		for _, i := range set {
+			if i >= 0 && i < len(probs) && !seen[i] {
+				seen[i] = true
+				want += probs[i] / tot
+			}
+		}
+		if got := as.MassOf(set); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("MassOf(%v): got %g, want %g\n", set, got, want)
+		}
+	})
+}
+
+func TestNextP(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+		seed := rapid.Int64().Draw(t, "seed")
+		as, _ := InitWithSeed(probs, seed)
+		plain, _ := InitWithSeed(probs, seed)
+		for range 100 {
+			i, p := as.NextP()
+			if want := plain.Next(); i != want {
+				t.Fatalf("NextP drew %d, want %d\n", i, want)
+			}
+			if p != as.Prob(i) || p == 0 {
+				t.Fatalf("NextP gave p %g for %d, want %g\n", p, i, as.Prob(i))
+			}
+		}
+	})
+}
+
+func TestProbabilities(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+		tot := sum(probs)
+		as, _ := Init(probs)
+
+		got := as.Probabilities()
+		if len(got) != len(probs) {
+			t.Fatalf("got %d probabilities, want %d\n", len(got), len(probs))
+		}
+		var mass float64
+		for i, p := range got {
+			if p != as.Prob(i) || math.Abs(p-probs[i]/tot) > 1e-9 {
+				t.Fatalf("probability %d is %g, want %g\n", i, p, probs[i]/tot)
+			}
+			mass += p
+		}
+		if math.Abs(mass-1) > 1e-9 {
+			t.Fatalf("probabilities sum to %g\n", mass)
+		}
+
+		/* The result is the caller's to change. */
+		got[0] = -1
+		if as.Prob(0) < 0 || as.Probabilities()[0] < 0 || as.Freeze().Probabilities()[0] < 0 {
+			t.Fatalf("changing the result changed the sampler\n")
+		}
+	})
+}
+
+func TestProbConcurrent(t *testing.T) {
+	/* The caches are filled on first use; run with -race. */
+	as, err := InitWithSeed([]float64{1, 2, 3, 4, 5}, 1)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	want := []float64{1.0 / 15, 2.0 / 15, 3.0 / 15, 4.0 / 15, 5.0 / 15}
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := r.New(r.NewSource(int64(g)))
+			for i := range want {
+				if got := as.Prob(i); math.Abs(got-want[i]) > 1e-12 {
+					t.Errorf("Prob(%d) = %g, want %g\n", i, got, want[i])
+				}
+			}
+			as.Quantile(0.5)
+			as.Entropy()
+			as.NextPFrom(rng)
+			as.MassOf([]int{0, 1})
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,23 @@
+package alias_sample
+
+// ProfileLabel is the pprof label key under which named samplers tag
+// their work.
+const ProfileLabel = "alias_sample"
+
+/* profiledBatch is the smallest NextN batch that is worth labelling; below
+ * it, setting up the labels costs more than the draws.
+ */
+const profiledBatch = 1 << 12
+
+// WithName names the sampler, so that CPU profiles of programs with many
+// samplers can tell them apart.  A named sampler builds its table, and
+// makes large NextN batches, with the pprof label ProfileLabel set to name,
+// inside an execution trace region.  Goroutines started by a parallel build
+// inherit the label.  The name also appears in the sampler's log output.
+// Builds for TinyGo, or with the alias_sample_lite tag, leave out the
+// labels and regions but keep the name.
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
@@ -0,0 +1,10 @@
+//go:build tinygo || alias_sample_lite
+
+package alias_sample
+
+/* profiled just runs f: TinyGo has no pprof labels or execution traces,
+ * and lite builds drop them to keep the binary small.
+ */
+func profiled(name, region string, f func()) {
+	f()
+}
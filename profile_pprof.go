@@ -0,0 +1,19 @@
+//go:build !tinygo && !alias_sample_lite
+
+package alias_sample
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+/* profiled runs f with the pprof label for name set, inside a trace region
+ * called region.
+ */
+func profiled(name, region string, f func()) {
+	pprof.Do(context.Background(), pprof.Labels(ProfileLabel, name), func(ctx context.Context) {
+		defer trace.StartRegion(ctx, region).End()
+		f()
+	})
+}
@@ -0,0 +1,50 @@
+//go:build !tinygo && !alias_sample_lite
+
+package alias_sample
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+/* profileMetrics snapshots the goroutine profile when a build finishes, so
+ * the test can see the labels the build ran with.
+ */
+type profileMetrics struct {
+	profile bytes.Buffer
+}
+
+func (m *profileMetrics) Drew(int) {}
+
+func (m *profileMetrics) Built(time.Duration) {
+	pprof.Lookup("goroutine").WriteTo(&m.profile, 1)
+}
+
+func TestWithName(t *testing.T) {
+	m := &profileMetrics{}
+	as, err := Init([]float64{1, 2, 3}, WithName("backends"), WithMetrics(m))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if got := m.profile.String(); !strings.Contains(got, `"alias_sample":"backends"`) {
+		t.Fatalf("build didn't run with the label; profile:\n%s\n", got)
+	}
+
+	var b bytes.Buffer
+	slog.New(slog.NewTextHandler(&b, nil)).Info("built", "sampler", as)
+	if got := b.String(); !strings.Contains(got, "sampler.name=backends sampler.n=3") {
+		t.Errorf("slog: got %q\n", got)
+	}
+
+	dst := make([]int, profiledBatch)
+	as.NextN(dst)
+	for _, i := range dst {
+		if i < 0 || i > 2 {
+			t.Fatalf("drew %d\n", i)
+		}
+	}
+}
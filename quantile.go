@@ -0,0 +1,39 @@
+package alias_sample
+
+// Quantile returns the smallest index i such that CDF(i) >= q, as for
+// CDFSampler.Quantile.  Like NextInRange, it builds the running sums of
+// the distribution on first use and keeps them; it is safe to call
+// concurrently.
+func (s *AliasSampler) Quantile(q float64) int {
+	return s.runningSums().Quantile(q)
+}
+
+// CDF returns the probability of drawing an index <= i.
+func (s *AliasSampler) CDF(i int) float64 {
+	return s.runningSums().CDF(i)
+}
+
+/* runningSums returns a CDFSampler over the table's distribution, building
+ * it the first time it is asked for.  The table never changes, so neither
+ * do the sums.
+ */
+func (s *AliasSampler) runningSums() *CDFSampler {
+	if s.cache != nil {
+		if sums := s.cache.sums.Load(); sums != nil {
+			return sums
+		}
+	}
+	cumulative := s.distribution()
+	for i := 1; i < len(cumulative); i++ {
+		cumulative[i] += cumulative[i-1]
+	}
+	/* The sums are only ever drawn from with an explicit rng, so they get
+	 * no source of their own.
+	 */
+	sums := &CDFSampler{seed: s.seed, cumulative: cumulative}
+	if s.cache == nil {
+		return sums
+	}
+	s.cache.sums.CompareAndSwap(nil, sums)
+	return s.cache.sums.Load()
+}
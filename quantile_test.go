@@ -0,0 +1,37 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestAliasQuantile(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+		q := rapid.Float64Range(0, 1).Draw(t, "q")
+
+		as, _ := Init(probs)
+		dist := tableProbs(as)
+		var acc float64
+		for i, p := range dist {
+			acc += p
+			if got := as.CDF(i); math.Abs(got-acc) > 1e-9 {
+				t.Fatalf("CDF(%d): got %g, want %g\n", i, got, acc)
+			}
+		}
+		if as.CDF(-1) != 0 || as.CDF(len(probs)) != 1 {
+			t.Fatalf("CDF out of range: got %g and %g\n", as.CDF(-1), as.CDF(len(probs)))
+		}
+
+		i := as.Quantile(q)
+		if as.CDF(i) < q-1e-9 {
+			t.Fatalf("Quantile(%g) = %d, but CDF(%d) = %g\n", q, i, i, as.CDF(i))
+		}
+		if i > 0 && as.CDF(i-1) >= q+1e-9 && dist[i] > 0 {
+			t.Fatalf("Quantile(%g) = %d, but CDF(%d) = %g already\n", q, i, i-1, as.CDF(i-1))
+		}
+	})
+}
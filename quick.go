@@ -0,0 +1,60 @@
+//go:build !alias_sample_lite
+
+package alias_sample
+
+import (
+	r "math/rand"
+	"reflect"
+)
+
+// Generator draws from a weighted set of values, for feeding property
+// tests realistic, skewed inputs.  It implements quick.Generator, but since
+// testing/quick finds generators through the zero value of an argument's
+// type, the easiest way to use it is through Values:
+//
+//	g, _ := NewGenerator([]int{0, 1, 1 << 20}, []float64{10, 5, 1})
+//	quick.Check(f, &quick.Config{Values: g.Values})
+//
+// A type of your own can also implement quick.Generator by delegating to a
+// package-level Generator.  The package doesn't import testing/quick, which
+// would register its flags in every program using alias_sample.
+// Generator needs reflect, so lite builds (the alias_sample_lite tag) leave
+// it out.
+type Generator[T any] struct {
+	values []T
+	s      *AliasSampler
+}
+
+// NewGenerator returns a Generator that draws values[i] with probability
+// proportional to weights[i].  Options are applied as for Init, but draws
+// use the random source testing/quick passes in.
+func NewGenerator[T any](values []T, weights []float64, opts ...Option) (*Generator[T], error) {
+	if len(values) != len(weights) {
+		return nil, &SampleError{"values and weights have different lengths"}
+	}
+	s, err := Init(weights, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator[T]{values: append([]T(nil), values...), s: s}, nil
+}
+
+// Next draws a value using rng.
+func (g *Generator[T]) Next(rng *r.Rand) T {
+	return g.values[g.s.NextFrom(rng)]
+}
+
+// Generate implements quick.Generator.  size is ignored, since the set of
+// values is fixed.
+func (g *Generator[T]) Generate(rng *r.Rand, size int) reflect.Value {
+	return reflect.ValueOf(g.Next(rng))
+}
+
+// Values fills every argument with a draw; it has the signature of
+// quick.Config.Values, so it suits functions whose arguments all have
+// type T.
+func (g *Generator[T]) Values(args []reflect.Value, rng *r.Rand) {
+	for i := range args {
+		args[i] = g.Generate(rng, 0)
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !alias_sample_lite
+
+package alias_sample
+
+import (
+	r "math/rand"
+	"testing"
+	"testing/quick"
+)
+
+var _ quick.Generator = (*Generator[int])(nil)
+
+func TestGenerator(t *testing.T) {
+	g, err := NewGenerator([]string{"common", "rare", "never"}, []float64{99, 1, 0})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	counts := map[string]int{}
+	f := func(a, b string) bool {
+		counts[a]++
+		counts[b]++
+		return a != "never" && b != "never"
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 5000, Values: g.Values}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if counts["common"] < 9500 || counts["rare"] == 0 {
+		t.Fatalf("got draws %v\n", counts)
+	}
+
+	v := g.Generate(r.New(r.NewSource(1)), 10)
+	if s, ok := v.Interface().(string); !ok || s == "never" {
+		t.Fatalf("Generate gave %v\n", v)
+	}
+
+	if _, err := NewGenerator([]int{1, 2}, []float64{1}); err == nil {
+		t.Fatalf("mismatched lengths were accepted\n")
+	}
+}
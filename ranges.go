@@ -0,0 +1,60 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// NextInRange draws an index in [lo, hi) with probability proportional to
+// its weight, as if the sampler had been built from just that range.  It
+// searches the running sums in O(log n), restricted to the range.  The
+// mass of the range is found as a difference of running sums, so ranges
+// holding a tiny fraction of the total (below about 1e-12) lose precision.
+// It returns an error if the range is empty, out of bounds, or has no
+// mass.
+func (s *CDFSampler) NextInRange(lo, hi int) (int, error) {
+	return s.nextInRange(s.rand, lo, hi)
+}
+
+func (s *CDFSampler) nextInRange(rng *r.Rand, lo, hi int) (int, error) {
+	if lo < 0 || hi > len(s.cumulative) || lo >= hi {
+		return 0, &SampleError{"invalid index range"}
+	}
+	base := 0.0
+	if lo > 0 {
+		base = s.cumulative[lo-1]
+	}
+	mass := s.cumulative[hi-1] - base
+	if !(mass > 0) {
+		return 0, &SampleError{"index range has no mass"}
+	}
+
+	/* Find the first index in the range whose running sum exceeds u. */
+	u := base + rng.Float64()*mass
+	a, b := lo, hi
+	for a < b {
+		mid := int(uint(a+b) >> 1)
+		if s.cumulative[mid] <= u {
+			a = mid + 1
+		} else {
+			b = mid
+		}
+	}
+	if a == hi {
+		/* Rounding put u at the very top of the range; step back to the
+		 * last index in it with any weight.
+		 */
+		a = hi - 1
+		for a > lo && s.weight(a) == 0 {
+			a--
+		}
+	}
+	return a, nil
+}
+
+// NextInRange draws an index in [lo, hi) with probability proportional to
+// its weight.  The first call builds the running sums of the distribution
+// (one float64 per index), which are kept for later calls; see
+// CDFSampler.NextInRange for the details.
+func (s *AliasSampler) NextInRange(lo, hi int) (int, error) {
+	return s.runningSums().nextInRange(s.source(), lo, hi)
+}
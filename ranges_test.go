@@ -0,0 +1,55 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestNextInRange(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		lo := rapid.IntRange(0, len(probs)-1).Draw(t, "lo")
+		hi := rapid.IntRange(lo+1, len(probs)).Draw(t, "hi")
+		probs[lo] += 0.001
+		probs[0] += 0.001
+
+		var tot float64
+		for _, p := range probs[lo:hi] {
+			tot += p
+		}
+
+		as, _ := Init(probs)
+		cdf, _ := InitCDF(probs)
+		for name, next := range map[string]func(int, int) (int, error){
+			"alias": as.NextInRange,
+			"cdf":   cdf.NextInRange,
+		} {
+			sz := 20_000
+			counts := make([]int, len(probs))
+			for range sz {
+				i, err := next(lo, hi)
+				if err != nil {
+					t.Fatalf("%s: got err %v\n", name, err)
+				}
+				if i < lo || i >= hi {
+					t.Fatalf("%s: drew %d outside [%d, %d)\n", name, i, lo, hi)
+				}
+				counts[i]++
+			}
+			for i := lo; i < hi; i++ {
+				if got := float64(counts[i]) / float64(sz); math.Abs(got-probs[i]/tot) > 0.02 {
+					t.Fatalf("%s: index %d: got %g, want %g\n", name, i, got, probs[i]/tot)
+				}
+			}
+		}
+	})
+
+	cdf, _ := InitCDF([]float64{1, 0, 0, 1})
+	for _, bad := range [][2]int{{-1, 2}, {0, 5}, {2, 2}, {1, 3}} {
+		if _, err := cdf.NextInRange(bad[0], bad[1]); err == nil {
+			t.Errorf("range %v was accepted\n", bad)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+/* recentRetries is how many candidates a Recent draw considers before it
+ * gives up on rejection and settles for the least suppressed of them.
+ */
+const recentRetries = 64
+
+// Recent wraps a Sampler so that indices it has just returned are less
+// likely to come up again, as in a playlist shuffle.  decay[j] is the
+// factor applied to the weight of the index returned j+1 draws ago, so
+// decay has one entry for each of the last len(decay) draws; a factor of 0
+// excludes the index outright, and an index returned more than once in the
+// window gets the product of its factors.  Weights recover by themselves as
+// draws fall out of the window, and the wrapped sampler is never modified,
+// so any backend works.
+//
+// Draws are made by rejection against the wrapped sampler and are exact,
+// unless recentRetries candidates in a row are rejected, in which case the
+// least suppressed of them is returned.  A Recent keeps a history, so it is
+// not safe for concurrent use, even through NextFrom.
+type Recent struct {
+	s       Sampler
+	rand    *r.Rand
+	decay   []float64
+	history []int // ring buffer of the last len(decay) draws
+	pos     int   // where the next draw goes in history
+	filled  int   // how much of history is in use
+}
+
+func NewRecent(s Sampler, decay []float64, opts ...Option) (*Recent, error) {
+	cfg := newConfig(opts)
+	for _, f := range decay {
+		if !(f >= 0 && f <= 1) {
+			return nil, &SampleError{"decay factors must be between 0 and 1"}
+		}
+	}
+	return &Recent{
+		s:       s,
+		rand:    cfg.newRand(),
+		decay:   append([]float64(nil), decay...),
+		history: make([]int, len(decay)),
+	}, nil
+}
+
+// LinearDecay returns a decay schedule for NewRecent over the last k draws
+// that excludes the most recent index and lets the weight recover in even
+// steps from there: the index returned j draws ago keeps (j-1)/k of its
+// weight.
+func LinearDecay(k int) []float64 {
+	decay := make([]float64, max(k, 0))
+	for j := range decay {
+		decay[j] = float64(j) / float64(k)
+	}
+	return decay
+}
+
+func (w *Recent) Next() int {
+	return w.NextFrom(w.rand)
+}
+
+func (w *Recent) NextFrom(rng *r.Rand) int {
+	best, bestFactor := -1, -1.0
+	for range recentRetries {
+		i := w.s.NextFrom(rng)
+		f := w.factor(i)
+		if f == 1 || (f > 0 && rng.Float64() < f) {
+			best = i
+			break
+		}
+		if f > bestFactor {
+			best, bestFactor = i, f
+		}
+	}
+	w.remember(best)
+	return best
+}
+
+func (w *Recent) Len() int {
+	return w.s.Len()
+}
+
+// Reset forgets the history, restoring every index to its full weight.
+func (w *Recent) Reset() {
+	w.pos, w.filled = 0, 0
+}
+
+/* factor returns how much of its weight i currently keeps. */
+func (w *Recent) factor(i int) float64 {
+	f := 1.0
+	for j := range w.filled {
+		/* j draws before the latest one. */
+		k := (w.pos - 1 - j + len(w.history)) % len(w.history)
+		if w.history[k] == i {
+			f *= w.decay[j]
+		}
+	}
+	return f
+}
+
+func (w *Recent) remember(i int) {
+	if len(w.history) == 0 {
+		return
+	}
+	w.history[w.pos] = i
+	w.pos = (w.pos + 1) % len(w.history)
+	w.filled = min(w.filled+1, len(w.history))
+}
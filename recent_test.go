@@ -0,0 +1,74 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestRecent(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(1, 2), 3, 10).Draw(t, "probs")
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		as, _ := Init(probs)
+		w, err := NewRecent(as, []float64{0})
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* With the last draw excluded, each draw should follow the
+		 * distribution conditioned on not repeating the one before.
+		 */
+		sz := 50_000
+		trans := make([][]int, len(probs))
+		for i := range trans {
+			trans[i] = make([]int, len(probs))
+		}
+		prev := w.Next()
+		for range sz {
+			i := w.Next()
+			if i == prev {
+				t.Fatalf("drew %d twice in a row\n", i)
+			}
+			trans[prev][i]++
+			prev = i
+		}
+		for i, row := range trans {
+			var seen int
+			for _, c := range row {
+				seen += c
+			}
+			for j, c := range row {
+				if j == i {
+					continue
+				}
+				want := probs[j] / (tot - probs[i])
+				if got := float64(c) / float64(seen); math.Abs(got-want) > 0.05 {
+					t.Fatalf("after %d, drew %d with frequency %g, want %g\n", i, j, got, want)
+				}
+			}
+		}
+	})
+}
+
+func TestRecentRecovers(t *testing.T) {
+	as, _ := Init([]float64{1, 1})
+	w, _ := NewRecent(as, LinearDecay(3), WithSeed(1))
+	if got := LinearDecay(3); got[0] != 0 || got[2] != 2.0/3.0 {
+		t.Fatalf("got schedule %v\n", got)
+	}
+	for range 100 {
+		w.Next()
+	}
+	w.Reset()
+	if f := w.factor(0) * w.factor(1); f != 1 {
+		t.Fatalf("factors after Reset multiply to %g\n", f)
+	}
+	if _, err := NewRecent(as, []float64{1.5}); err == nil {
+		t.Fatalf("decay factor above 1 was accepted\n")
+	}
+}
@@ -0,0 +1,101 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// RejectionSampler draws a uniform index and accepts it with probability
+// w/wmax, retrying until it accepts.  It needs no table beyond the weights
+// themselves and supports O(1) updates, and a draw takes n*wmax/sum(w)
+// tries on average, so it is only competitive when the weights are not
+// very skewed.
+type RejectionSampler struct {
+	seed int64
+	rand *r.Rand
+
+	weights  []float64
+	bound    float64 // an upper bound on the weights, used as wmax
+	positive int     // how many weights are nonzero
+}
+
+func InitRejection(probs []float64, opts ...Option) (*RejectionSampler, error) {
+	cfg := newConfig(opts)
+	if len(probs) == 0 {
+		return nil, &SampleError{"no probabilities provided"}
+	}
+
+	s := &RejectionSampler{
+		seed:    cfg.seed,
+		rand:    cfg.newRand(),
+		weights: make([]float64, len(probs)),
+	}
+	for i, p := range probs {
+		if !ValidWeight(p) {
+			return nil, &SampleError{"weights must be finite and non-negative"}
+		}
+		s.weights[i] = p
+		s.bound = max(s.bound, p)
+		if p > 0 {
+			s.positive++
+		}
+	}
+	if s.positive == 0 {
+		return nil, &SampleError{"all weights are zero"}
+	}
+	return s, nil
+}
+
+func (s *RejectionSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *RejectionSampler) NextFrom(rng *r.Rand) int {
+	for {
+		i := rng.Intn(len(s.weights))
+		if rng.Float64()*s.bound < s.weights[i] {
+			return i
+		}
+	}
+}
+
+func (s *RejectionSampler) Len() int {
+	return len(s.weights)
+}
+
+// Update sets the weight of index i to w in O(1) time.  Raising a weight
+// above the current maximum raises the acceptance bound with it, but
+// lowering the maximum leaves the bound where it was, which is still
+// correct but makes draws slower; call Tighten to recompute it.
+func (s *RejectionSampler) Update(i int, w float64) error {
+	if i < 0 || i >= len(s.weights) {
+		return &SampleError{"index out of range"}
+	}
+	if !ValidWeight(w) {
+		return &SampleError{"weights must be finite and non-negative"}
+	}
+
+	positive := s.positive
+	if s.weights[i] > 0 {
+		positive--
+	}
+	if w > 0 {
+		positive++
+	}
+	if positive == 0 {
+		return &SampleError{"all weights are zero"}
+	}
+
+	s.positive = positive
+	s.weights[i] = w
+	s.bound = max(s.bound, w)
+	return nil
+}
+
+// Tighten recomputes the acceptance bound from the current weights, in
+// O(n) time.
+func (s *RejectionSampler) Tighten() {
+	s.bound = 0
+	for _, w := range s.weights {
+		s.bound = max(s.bound, w)
+	}
+}
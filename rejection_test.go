@@ -0,0 +1,48 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRejectionUpdate(t *testing.T) {
+	s, err := InitRejection([]float64{1, 0, 3})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if _, err := InitRejection([]float64{0, 0}); err == nil {
+		t.Fatalf("all-zero weights were accepted")
+	}
+
+	if err := s.Update(2, 0); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if s.bound != 3 {
+		t.Fatalf("bound dropped to %g on update\n", s.bound)
+	}
+	s.Tighten()
+	if s.bound != 1 {
+		t.Fatalf("got bound %g after Tighten, want 1\n", s.bound)
+	}
+	for range 1000 {
+		if got := s.Next(); got != 0 {
+			t.Fatalf("drew %d, only index 0 has weight\n", got)
+		}
+	}
+
+	if err := s.Update(0, 0); err == nil {
+		t.Fatalf("update zeroing every weight was accepted")
+	}
+	if err := s.Update(1, 5); err != nil || s.bound != 5 {
+		t.Fatalf("got err %v, bound %g\n", err, s.bound)
+	}
+
+	for _, w := range []float64{math.NaN(), math.Inf(1), -1} {
+		if _, err := InitRejection([]float64{1, w}); err == nil {
+			t.Errorf("weight %g was accepted by InitRejection\n", w)
+		}
+		if err := s.Update(0, w); err == nil {
+			t.Errorf("weight %g was accepted by Update\n", w)
+		}
+	}
+}
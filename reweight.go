@@ -0,0 +1,32 @@
+package alias_sample
+
+// Reweight returns the posterior of the sampler's distribution given
+// evidence: index i is drawn with probability proportional to its current
+// probability times likelihood[i].  The likelihoods need not be normalized.
+// The new sampler has the same seed as s unless opts say otherwise, and s
+// itself is left alone, so a filter can keep stepping by calling Reweight
+// on each result in turn.  It returns an error if likelihood has the wrong
+// length, holds a negative or non-finite value, or rules out every index.
+func (s *AliasSampler) Reweight(likelihood []float64, opts ...Option) (*AliasSampler, error) {
+	if len(likelihood) != s.n {
+		return nil, &SampleError{"likelihood length does not match the sampler"}
+	}
+	if err := checkWeights(likelihood); err != nil {
+		return nil, err
+	}
+
+	posterior := s.distribution()
+	for i, l := range likelihood {
+		posterior[i] *= l
+	}
+	if err := checkWeights(posterior); err != nil {
+		return nil, &SampleError{"the evidence rules out every index"}
+	}
+
+	res, err := InitInPlace(posterior, append([]Option{WithSeed(s.seed)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	res.parent = s.parent
+	return res, nil
+}
@@ -0,0 +1,44 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestReweight(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 1, 50).Draw(t, "probs")
+		like := rapid.SliceOfN(rapid.Float64Range(0, 10.0), len(probs), len(probs)).Draw(t, "likelihood")
+		like[0] += 0.1
+
+		as, _ := Init(probs)
+		post, err := as.Reweight(like)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		var tot float64
+		for i := range probs {
+			tot += probs[i] * like[i]
+		}
+		got := tableProbs(post)
+		for i := range probs {
+			if want := probs[i] * like[i] / tot; math.Abs(got[i]-want) > 1e-9 {
+				t.Fatalf("index %d: got %g, want %g\n", i, got[i], want)
+			}
+		}
+	})
+
+	as, _ := Init([]float64{1, 0, 1})
+	if _, err := as.Reweight([]float64{1, 1}); err == nil {
+		t.Errorf("short likelihood was accepted\n")
+	}
+	if _, err := as.Reweight([]float64{1, -1, 1}); err == nil {
+		t.Errorf("negative likelihood was accepted\n")
+	}
+	if _, err := as.Reweight([]float64{0, 1, 0}); err == nil {
+		t.Errorf("evidence ruling out every index was accepted\n")
+	}
+}
@@ -0,0 +1,118 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"slices"
+)
+
+// Run is Count consecutive indices that all have the same Weight.
+type Run struct {
+	Weight float64
+	Count  int
+}
+
+// Runs compresses probs into runs of equal weights.
+func Runs(probs []float64) []Run {
+	var runs []Run
+	for _, p := range probs {
+		if k := len(runs) - 1; k >= 0 && runs[k].Weight == p {
+			runs[k].Count++
+			continue
+		}
+		runs = append(runs, Run{Weight: p, Count: 1})
+	}
+	return runs
+}
+
+// RunSampler draws from weights given as runs of equal weight, for inputs
+// like tiered priorities where a few distinct weights cover a great many
+// indices.  It keeps an alias table over the runs, weighted by each run's
+// total, and draws an offset within the chosen run uniformly, so it takes
+// memory in proportion to the number of runs rather than of indices, and
+// draws in O(1).
+type RunSampler struct {
+	seed int64
+	rand *r.Rand
+
+	n      int
+	starts []int // the first index of each run
+	counts []int
+	table  *AliasSampler
+}
+
+// InitRunLength builds a RunSampler over runs, whose weights must be
+// finite and non-negative, with some weight in all, and whose counts must
+// be positive.  The options apply to the table over runs, so WithMinProb
+// and WithMaxProb bound each run's share rather than each index's.
+func InitRunLength(runs []Run, opts ...Option) (*RunSampler, error) {
+	cfg := newConfig(opts)
+	if len(runs) == 0 {
+		return nil, &SampleError{"no runs provided"}
+	}
+
+	s := &RunSampler{
+		seed:   cfg.seed,
+		rand:   cfg.newRand(),
+		starts: make([]int, len(runs)),
+		counts: make([]int, len(runs)),
+	}
+	masses := make([]float64, len(runs))
+	for k, run := range runs {
+		if run.Count <= 0 {
+			return nil, &SampleError{"run counts must be positive"}
+		}
+		if s.n > math.MaxInt-run.Count {
+			return nil, &SampleError{"runs cover too many indices"}
+		}
+		s.starts[k] = s.n
+		s.counts[k] = run.Count
+		s.n += run.Count
+		masses[k] = run.Weight * float64(run.Count)
+	}
+	if err := checkWeights(masses); err != nil {
+		return nil, err
+	}
+
+	table, err := build(masses, cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.table = table
+	return s, nil
+}
+
+func (s *RunSampler) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *RunSampler) NextFrom(rng *r.Rand) int {
+	k := s.table.NextFrom(rng)
+	if s.counts[k] == 1 {
+		return s.starts[k]
+	}
+	return s.starts[k] + rng.Intn(s.counts[k])
+}
+
+func (s *RunSampler) Len() int {
+	return s.n
+}
+
+// NumRuns returns the number of runs the sampler stores.
+func (s *RunSampler) NumRuns() int {
+	return len(s.starts)
+}
+
+// Prob returns the normalized probability of drawing index i, or 0 if i is
+// out of range.  Like AliasSampler.Prob, the first call recovers the
+// distribution over runs and keeps it.
+func (s *RunSampler) Prob(i int) float64 {
+	if i < 0 || i >= s.n {
+		return 0
+	}
+	k, found := slices.BinarySearch(s.starts, i)
+	if !found {
+		k--
+	}
+	return s.table.Prob(k) / float64(s.counts[k])
+}
@@ -0,0 +1,109 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestRuns(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.SampledFrom([]float64{0, 0.5, 1, 2}), 0, 50).Draw(t, "probs")
+		runs := Runs(probs)
+		var flat []float64
+		for k, run := range runs {
+			if run.Count <= 0 || (k > 0 && runs[k-1].Weight == run.Weight) {
+				t.Fatalf("runs %v are not maximal\n", runs)
+			}
+			for range run.Count {
+				flat = append(flat, run.Weight)
+			}
+		}
+		if len(flat) != len(probs) {
+			t.Fatalf("runs cover %d indices, want %d\n", len(flat), len(probs))
+		}
+		for i := range probs {
+			if flat[i] != probs[i] {
+				t.Fatalf("runs give weight %v at %d, want %v\n", flat[i], i, probs[i])
+			}
+		}
+	})
+}
+
+func TestRunSampler(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		runs := rapid.SliceOfN(rapid.Custom(func(t *rapid.T) Run {
+			return Run{
+				Weight: rapid.Float64Range(0, 3).Draw(t, "weight"),
+				Count:  rapid.IntRange(1, 20).Draw(t, "count"),
+			}
+		}), 1, 10).Draw(t, "runs")
+		runs[0].Weight += 0.5
+
+		var probs []float64
+		for _, run := range runs {
+			for range run.Count {
+				probs = append(probs, run.Weight)
+			}
+		}
+		tot := sum(probs)
+
+		s, err := InitRunLength(runs, WithSeed(1))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if s.Len() != len(probs) || s.NumRuns() != len(runs) {
+			t.Fatalf("sampler has %d indices in %d runs, want %d in %d\n",
+				s.Len(), s.NumRuns(), len(probs), len(runs))
+		}
+
+		sz := 50_000
+		got := make([]float64, len(probs))
+		for range sz {
+			got[s.Next()]++
+		}
+		for i, w := range probs {
+			p := w / tot
+			if math.Abs(s.Prob(i)-p) > 1e-9 {
+				t.Fatalf("prob %d is %v, want %v\n", i, s.Prob(i), p)
+			}
+			if p == 0 && got[i] > 0 {
+				t.Fatalf("drew %d, which has no weight\n", i)
+			}
+			if math.Abs(got[i]-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+				t.Fatalf("drew %d %v times of %d, want p %v\n", i, got[i], sz, p)
+			}
+		}
+		if s.Prob(-1) != 0 || s.Prob(len(probs)) != 0 {
+			t.Fatalf("out of range indices have probability\n")
+		}
+	})
+}
+
+func TestRunSamplerHuge(t *testing.T) {
+	/* A billion indices in three runs take a three-entry table. */
+	s, err := InitRunLength([]Run{{1, 1 << 29}, {0, 1 << 28}, {2, 1 << 28}})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if st := s.table.Stats(); st.TableBytes > 100 {
+		t.Fatalf("table takes %d bytes\n", st.TableBytes)
+	}
+	for range 1000 {
+		if i := s.Next(); i >= 1<<29 && i < 3<<28 {
+			t.Fatalf("drew %d, which has no weight\n", i)
+		}
+	}
+	if p := s.Prob(3<<28 + 5); math.Abs(p-0.5/(1<<28)) > 1e-20 {
+		t.Fatalf("prob is %v, want %v\n", p, 0.5/(1<<28))
+	}
+}
+
+func TestRunSamplerErrors(t *testing.T) {
+	for _, runs := range [][]Run{nil, {{1, 0}}, {{1, -1}}, {{0, 3}}, {{-1, 2}}, {{1, math.MaxInt}, {1, 1}}} {
+		if _, err := InitRunLength(runs); err == nil {
+			t.Fatalf("runs %v were accepted\n", runs)
+		}
+	}
+}
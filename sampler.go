@@ -0,0 +1,46 @@
+package alias_sample
+
+import (
+	r "math/rand"
+)
+
+// A Sampler draws indices 0..Len()-1 from a fixed discrete distribution.
+// Each backend makes different tradeoffs between construction cost, draw
+// cost and memory, but they all sample the same distribution for the same
+// weights, so code written against Sampler can switch between them freely.
+type Sampler interface {
+	// Next draws an index using the sampler's own random source.
+	Next() int
+	// NextFrom draws an index using rng instead of the sampler's source.
+	NextFrom(rng *r.Rand) int
+	// Len returns the number of indices.
+	Len() int
+}
+
+var (
+	_ Sampler = (*AliasSampler)(nil)
+	_ Sampler = (*LinearSampler)(nil)
+	_ Sampler = (*CDFSampler)(nil)
+	_ Sampler = (*GuideSampler)(nil)
+	_ Sampler = (*InterpolationSampler)(nil)
+	_ Sampler = (*RejectionSampler)(nil)
+	_ Sampler = (*Recent)(nil)
+	_ Sampler = (*Schedule)(nil)
+	_ Sampler = (*Dynamic)(nil)
+	_ Sampler = (*EpsilonGreedy)(nil)
+	_ Sampler = (*BucketedSampler)(nil)
+	_ Sampler = (*Frozen)(nil)
+	_ Sampler = (*LazySampler)(nil)
+	_ Sampler = (*RunSampler)(nil)
+	_ Sampler = (*CoinSampler)(nil)
+)
+
+/* asSampler converts a constructor's result to a Sampler, making sure that
+ * a failed construction yields a nil interface rather than a typed nil.
+ */
+func asSampler[S Sampler](s S, err error) (Sampler, error) {
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,92 @@
+package alias_sample
+
+import (
+	"fmt"
+	"math"
+	r "math/rand"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+/* backends constructs every Sampler implementation over the same weights. */
+var backends = map[string]func([]float64, ...Option) (Sampler, error){
+	"alias": func(p []float64, o ...Option) (Sampler, error) {
+		return Init(p, o...)
+	},
+	"squared": func(p []float64, o ...Option) (Sampler, error) {
+		return Init(p, append(o, WithSquaredHistogram())...)
+	},
+	"linear": func(p []float64, o ...Option) (Sampler, error) {
+		return InitLinear(p, o...)
+	},
+	"cdf": func(p []float64, o ...Option) (Sampler, error) {
+		return InitCDF(p, o...)
+	},
+	"guide": func(p []float64, o ...Option) (Sampler, error) {
+		return InitGuide(p, o...)
+	},
+	"interpolation": func(p []float64, o ...Option) (Sampler, error) {
+		return InitInterpolation(p, o...)
+	},
+	"rejection": func(p []float64, o ...Option) (Sampler, error) {
+		return InitRejection(p, o...)
+	},
+}
+
+func TestBackends(t *testing.T) {
+	for name, init := range backends {
+		t.Run(name, func(t *testing.T) {
+			rapid.Check(t, func(t *rapid.T) {
+				probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+				probs[0] += 0.001
+				s, err := init(probs)
+				if err != nil {
+					t.Fatalf("got err %v\n", err)
+				}
+				if s.Len() != len(probs) {
+					t.Fatalf("got Len %d, want %d\n", s.Len(), len(probs))
+				}
+
+				sz := 200_000
+				rng := r.New(r.NewSource(1))
+				res := make([]int, len(probs))
+				for i := range sz {
+					if i%2 == 0 {
+						res[s.Next()]++
+					} else {
+						res[s.NextFrom(rng)]++
+					}
+				}
+
+				var tot float64
+				for _, p := range probs {
+					tot += p
+				}
+				for i, c := range res {
+					p := float64(c) / float64(sz)
+					if math.Abs(p-probs[i]/tot) > 0.01 {
+						t.Fatalf("failed: index %d, %f, %f, %v\n", i, p, probs[i]/tot, res)
+					}
+					if probs[i] == 0 && c != 0 {
+						t.Fatalf("index %d has zero weight but was drawn %d times\n", i, c)
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkSmall(b *testing.B) {
+	for _, n := range []int{2, 4, 8, 16, 32} {
+		probs := benchmarkProbs(n)
+		for _, name := range []string{"alias", "linear", "cdf"} {
+			s, _ := backends[name](probs, WithSeed(1))
+			b.Run(fmt.Sprintf("%s/n=%d", name, n), func(b *testing.B) {
+				for b.Loop() {
+					s.Next()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"time"
+)
+
+// An Easing maps the fraction of a Schedule's window that has elapsed, in
+// [0, 1], to how far the weights have moved from start to end, also in
+// [0, 1].
+type Easing func(t float64) float64
+
+// Linear moves the weights at a constant rate.
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseInOut starts and finishes the ramp slowly (smoothstep).
+func EaseInOut(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// A ScheduleOption configures NewSchedule.  Every Option is also a
+// ScheduleOption, which applies to the tables the Schedule builds.
+type ScheduleOption interface {
+	applySchedule(c *scheduleConfig)
+}
+
+type scheduleConfig struct {
+	easing     Easing
+	resolution time.Duration
+	opts       []Option
+}
+
+type scheduleOption func(c *scheduleConfig)
+
+func (o scheduleOption) applySchedule(c *scheduleConfig) {
+	o(c)
+}
+
+func (o Option) applySchedule(c *scheduleConfig) {
+	c.opts = append(c.opts, o)
+}
+
+// WithEasing sets the shape of a Schedule's ramp.  The default is Linear.
+func WithEasing(easing Easing) ScheduleOption {
+	return scheduleOption(func(c *scheduleConfig) {
+		c.easing = easing
+	})
+}
+
+// WithResolution sets how often a Schedule rebuilds its table during the
+// ramp: the weights move in steps of the given duration.  The default is a
+// thousandth of the window.
+func WithResolution(d time.Duration) ScheduleOption {
+	return scheduleOption(func(c *scheduleConfig) {
+		c.resolution = d
+	})
+}
+
+// Schedule ramps between two weight vectors over a wall-clock window, so
+// gradual rollouts (0% to 50% over an hour, say) don't need anything
+// outside the process rebuilding samplers.  Before from it draws from
+// start, after to it draws from end, and in between from the blend given
+// by its Easing.  The table is rebuilt lazily, on the first draw in each
+// step of the ramp, and other options are passed through to those builds,
+// apart from WithBuffers, since earlier tables have to stay valid.  If a
+// step's table can't be built under those options, draws keep coming from
+// the last one that could.
+// A Schedule is not safe for concurrent use.
+type Schedule struct {
+	start, end []float64
+	from       time.Time
+	window     time.Duration
+	easing     Easing
+	resolution time.Duration
+	opts       []Option
+
+	rand    *r.Rand
+	now     func() time.Time
+	step    int64 // the step current was built for
+	current *AliasSampler
+}
+
+// NewSchedule returns a Schedule ramping from start to end between from and
+// to.  Both weight vectors must be valid, have the same length, and satisfy
+// the options given.
+func NewSchedule(start, end []float64, from, to time.Time, opts ...ScheduleOption) (*Schedule, error) {
+	var sc scheduleConfig
+	for _, opt := range opts {
+		opt.applySchedule(&sc)
+	}
+	cfg := newConfig(sc.opts)
+	if len(start) != len(end) {
+		return nil, &SampleError{"start and end weights have different lengths"}
+	}
+	if err := checkWeights(start); err != nil {
+		return nil, err
+	}
+	if err := checkWeights(end); err != nil {
+		return nil, err
+	}
+	if !to.After(from) {
+		return nil, &SampleError{"schedule must end after it starts"}
+	}
+
+	s := &Schedule{
+		start:      append([]float64(nil), start...),
+		end:        append([]float64(nil), end...),
+		from:       from,
+		window:     to.Sub(from),
+		easing:     sc.easing,
+		resolution: sc.resolution,
+		opts:       ownBuffers(sc.opts),
+		rand:       cfg.newRand(),
+		now:        time.Now,
+		step:       -1,
+	}
+	if s.easing == nil {
+		s.easing = Linear
+	}
+	if s.resolution <= 0 {
+		s.resolution = max(s.window/1000, 1)
+	}
+
+	/* Build the tables for both ends now, so that options the weights
+	 * can't satisfy are reported here rather than on some later draw.
+	 */
+	if _, err := s.At(to); err != nil {
+		return nil, err
+	}
+	if _, err := s.At(from); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// At returns the sampler for the weights in effect at t.  The result is
+// an ordinary AliasSampler and stays valid after the schedule moves on.
+func (s *Schedule) At(t time.Time) (*AliasSampler, error) {
+	/* Weights only change at step boundaries, so the table built for the
+	 * last step asked about can be reused until the next one.
+	 */
+	steps := int64((s.window + s.resolution - 1) / s.resolution)
+	step := min(max(int64(t.Sub(s.from)/s.resolution), 0), steps)
+	if step == s.step {
+		return s.current, nil
+	}
+
+	e := s.easing(min(float64(time.Duration(step)*s.resolution)/float64(s.window), 1))
+	if !(e >= 0) {
+		e = 0
+	}
+	e = min(e, 1)
+	weights := make([]float64, len(s.start))
+	for i := range weights {
+		weights[i] = (1-e)*s.start[i] + e*s.end[i]
+	}
+	current, err := InitInPlace(weights, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.step, s.current = step, current
+	return current, nil
+}
+
+func (s *Schedule) Next() int {
+	return s.NextFrom(s.rand)
+}
+
+func (s *Schedule) NextFrom(rng *r.Rand) int {
+	current, err := s.At(s.now())
+	if err != nil {
+		/* Both ends were built, and a blend of them is always a valid
+		 * weight vector, so this takes options that accept both ends but
+		 * reject something in between.  Keep drawing from the last table
+		 * that did build.
+		 */
+		current = s.current
+	}
+	return current.NextFrom(rng)
+}
+
+func (s *Schedule) Len() int {
+	return len(s.start)
+}
@@ -0,0 +1,135 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+)
+
+func TestSchedule(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(1, 20).Draw(t, "n")
+		start := rapid.SliceOfN(rapid.Float64Range(0, 5.0), n, n).Draw(t, "start")
+		end := rapid.SliceOfN(rapid.Float64Range(0, 5.0), n, n).Draw(t, "end")
+		start[0] += 0.001
+		end[n-1] += 0.001
+		frac := rapid.Float64Range(-0.5, 1.5).Draw(t, "frac")
+		easeIn := rapid.Bool().Draw(t, "easeInOut")
+
+		from := time.Unix(1_000_000, 0)
+		to := from.Add(time.Hour)
+		opts := []ScheduleOption{WithResolution(time.Minute)}
+		easing := Linear
+		if easeIn {
+			opts = append(opts, WithEasing(EaseInOut))
+			easing = EaseInOut
+		}
+		sch, err := NewSchedule(start, end, from, to, opts...)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		at := from.Add(time.Duration(frac * float64(time.Hour)))
+		as, err := sch.At(at)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* Weights move in whole minutes. */
+		minutes := math.Floor(frac * 60)
+		e := easing(min(max(minutes/60, 0), 1))
+		var tot float64
+		want := make([]float64, n)
+		for i := range want {
+			want[i] = (1-e)*start[i] + e*end[i]
+			tot += want[i]
+		}
+		got := tableProbs(as)
+		for i := range want {
+			if math.Abs(got[i]-want[i]/tot) > 1e-9 {
+				t.Fatalf("index %d: got %g, want %g\n", i, got[i], want[i]/tot)
+			}
+		}
+	})
+}
+
+func TestScheduleLazy(t *testing.T) {
+	from := time.Unix(1_000_000, 0)
+	sch, err := NewSchedule([]float64{1, 0}, []float64{0, 1}, from, from.Add(time.Hour), WithResolution(time.Minute))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	now := from.Add(90 * time.Second)
+	sch.now = func() time.Time { return now }
+
+	sch.Next()
+	first := sch.current
+	now = now.Add(20 * time.Second)
+	sch.Next()
+	if sch.current != first {
+		t.Errorf("rebuilt within a step\n")
+	}
+	now = from.Add(2 * time.Hour)
+	if i := sch.Next(); i != 1 || sch.current == first {
+		t.Errorf("after the window drew %d\n", i)
+	}
+
+	if _, err := NewSchedule([]float64{1}, []float64{1, 1}, from, from.Add(time.Hour)); err == nil {
+		t.Errorf("mismatched lengths were accepted\n")
+	}
+	if _, err := NewSchedule([]float64{1}, []float64{1}, from, from); err == nil {
+		t.Errorf("empty window was accepted\n")
+	}
+	if _, err := NewSchedule([]float64{1, 1, 1, 1}, []float64{1, 0, 0, 0}, from, from.Add(time.Hour), WithMaxProb(0.5)); err == nil {
+		t.Errorf("end weights the cap can't be met for were accepted\n")
+	}
+}
+
+func TestScheduleBuildFailure(t *testing.T) {
+	from := time.Unix(1_000_000, 0)
+	sch, err := NewSchedule([]float64{1, 0}, []float64{0, 1}, from, from.Add(time.Hour), WithResolution(time.Minute))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	now := from
+	sch.now = func() time.Time { return now }
+	if i := sch.Next(); i != 0 {
+		t.Fatalf("at the start drew %d\n", i)
+	}
+
+	/* No later table can be built, so draws stay with the first. */
+	sch.opts = append(sch.opts, WithMaxProb(0.1))
+	now = from.Add(2 * time.Hour)
+	for range 100 {
+		if i := sch.Next(); i != 0 {
+			t.Fatalf("after a failed build drew %d\n", i)
+		}
+	}
+}
+
+func TestScheduleBuffers(t *testing.T) {
+	from := time.Unix(0, 0)
+	to := from.Add(time.Hour)
+	s, err := NewSchedule([]float64{1, 2, 3, 4, 5}, []float64{5, 4, 3, 2, 1}, from, to,
+		WithBuffers(make([]float64, 16), make([]int, 16)))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	first, err := s.At(from)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	want := first.distribution()
+
+	/* Later steps must leave the earlier table as it was. */
+	if _, err := s.At(to); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	for i, p := range first.distribution() {
+		if p != want[i] {
+			t.Fatalf("earlier table changed: index %d has %v, want %v\n", i, p, want[i])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package alias_sample
+
+import (
+	"math"
+	r "math/rand"
+	"sync"
+	"time"
+)
+
+/* schedulerRebase bounds how many half-lives past a scheduler's origin a
+ * task may be enqueued before the stored weights are rescaled, keeping
+ * them well clear of underflow.
+ */
+const schedulerRebase = 512
+
+/* schedulerMaxWeight caps stored weights when rescaling, so that tasks
+ * left waiting for thousands of half-lives can't overflow the total.
+ */
+const schedulerMaxWeight = 0x1p900
+
+// PriorityScheduler hands out tasks in random order, each with
+// probability proportional to its effective weight: its priority, doubled
+// for every halfLife it has spent waiting.  High-priority tasks usually go
+// first, but a low-priority task's weight keeps growing until it is
+// picked, so it cannot starve.
+//
+// Since every waiting task's weight grows at the same exponential rate,
+// their ratios never change as time passes, and each task's weight is
+// stored once, scaled by its enqueue time, in a tree of partial sums.  Enqueue
+// and Dequeue take O(log n).  A PriorityScheduler is safe for concurrent
+// use.
+type PriorityScheduler[T any] struct {
+	halfLife time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	rand   *r.Rand
+	tasks  *bag[T]
+	origin time.Time // when a stored weight equals the priority
+}
+
+// NewPriorityScheduler returns an empty PriorityScheduler.  A halfLife of
+// zero turns aging off, so that tasks are picked by priority alone.  The
+// options are applied as for Init; only WithSeed matters.
+func NewPriorityScheduler[T any](halfLife time.Duration, opts ...Option) (*PriorityScheduler[T], error) {
+	cfg := newConfig(opts)
+	if halfLife < 0 {
+		return nil, &SampleError{"half-life must not be negative"}
+	}
+	return &PriorityScheduler[T]{
+		halfLife: halfLife,
+		now:      time.Now,
+		rand:     cfg.newRand(),
+		tasks:    newBag[T](),
+		origin:   time.Now(),
+	}, nil
+}
+
+// Enqueue adds task with the given priority, which must be positive and
+// finite.
+func (s *PriorityScheduler[T]) Enqueue(task T, priority float64) error {
+	if !(priority > 0) || math.IsInf(priority, 1) {
+		return &SampleError{"priority must be positive and finite"}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	weight := priority
+	if s.halfLife > 0 {
+		age := s.age(s.now())
+		if age > schedulerRebase {
+			s.rebase(age)
+			age = s.age(s.now())
+		}
+		weight = priority * math.Exp2(-age)
+	}
+	s.tasks.add(task, max(weight, math.SmallestNonzeroFloat64))
+	return nil
+}
+
+// Dequeue removes and returns a task, or reports false if there are none.
+func (s *PriorityScheduler[T]) Dequeue() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tasks.take(s.rand)
+}
+
+// Len returns the number of tasks waiting.
+func (s *PriorityScheduler[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tasks.count
+}
+
+/* age returns how many half-lives t is past the origin. */
+func (s *PriorityScheduler[T]) age(t time.Time) float64 {
+	return float64(t.Sub(s.origin)) / float64(s.halfLife)
+}
+
+/* rebase moves the origin forward by a whole number of half-lives,
+ * scaling the stored weights up to match.  s.mu must be held.
+ */
+func (s *PriorityScheduler[T]) rebase(age float64) {
+	shift := math.Floor(age)
+	s.origin = s.origin.Add(time.Duration(shift * float64(s.halfLife)))
+	s.tasks.rescale(func(w float64) float64 {
+		return min(w*math.Exp2(shift), schedulerMaxWeight)
+	})
+}
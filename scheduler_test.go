@@ -0,0 +1,97 @@
+package alias_sample
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+)
+
+func TestPrioritySchedulerDrain(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		priorities := rapid.SliceOfN(rapid.Float64Range(0.001, 1000), 0, 200).Draw(t, "priorities")
+		s, err := NewPriorityScheduler[int](time.Duration(rapid.Int64Range(0, int64(time.Hour)).Draw(t, "halfLife")))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		for i, p := range priorities {
+			if err := s.Enqueue(i, p); err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+		}
+		if s.Len() != len(priorities) {
+			t.Fatalf("Len is %d after %d enqueues\n", s.Len(), len(priorities))
+		}
+		var got []int
+		for {
+			task, ok := s.Dequeue()
+			if !ok {
+				break
+			}
+			got = append(got, task)
+		}
+		slices.Sort(got)
+		for i, task := range got {
+			if task != i {
+				t.Fatalf("dequeued %v from %d tasks\n", got, len(priorities))
+			}
+		}
+		if len(got) != len(priorities) || s.Len() != 0 {
+			t.Fatalf("dequeued %d of %d tasks\n", len(got), len(priorities))
+		}
+	})
+}
+
+/* firstShare enqueues an old task, then after wait a new one, and
+ * returns how often the old one is dequeued first.
+ */
+func firstShare(t *testing.T, halfLife, wait time.Duration, oldPriority, newPriority float64) float64 {
+	sz := 20_000
+	first := 0
+	for seed := range sz {
+		s, _ := NewPriorityScheduler[string](halfLife, WithSeed(int64(seed)))
+		now := time.Unix(0, 0)
+		s.now, s.origin = func() time.Time { return now }, now
+		s.Enqueue("old", oldPriority)
+		now = now.Add(wait)
+		s.Enqueue("new", newPriority)
+		if task, _ := s.Dequeue(); task == "old" {
+			first++
+		}
+	}
+	return float64(first) / float64(sz)
+}
+
+func TestPrioritySchedulerAging(t *testing.T) {
+	/* Without aging, priority alone decides. */
+	if got := firstShare(t, 0, time.Hour, 1, 3); math.Abs(got-0.25) > 0.02 {
+		t.Fatalf("no aging: old task first %v of the time, want 0.25\n", got)
+	}
+
+	/* After ten half-lives the old task's weight is 1024. */
+	if got, want := firstShare(t, time.Minute, 10*time.Minute, 1, 100), 1024.0/1124; math.Abs(got-want) > 0.02 {
+		t.Fatalf("aging: old task first %v of the time, want %v\n", got, want)
+	}
+
+	/* Waiting long enough to rebase the stored weights leaves the
+	 * newer task's weight intact, and the old one dominating.
+	 */
+	if got := firstShare(t, time.Second, 600*time.Second, 1, 100); got != 1 {
+		t.Fatalf("rebase: old task first %v of the time\n", got)
+	}
+	if got := firstShare(t, time.Second, 600*time.Second, 0x1p-600, 1); math.Abs(got-0.5) > 0.02 {
+		t.Fatalf("rebase: old task first %v of the time, want 0.5\n", got)
+	}
+
+	s, _ := NewPriorityScheduler[int](time.Second)
+	for _, bad := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		if err := s.Enqueue(1, bad); err == nil {
+			t.Errorf("accepted priority %v\n", bad)
+		}
+	}
+	if _, err := NewPriorityScheduler[int](-time.Second); err == nil {
+		t.Fatalf("negative half-life was accepted\n")
+	}
+}
@@ -0,0 +1,38 @@
+package alias_sample
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+)
+
+/* fallbackState is the splitmix64 state behind fallbackSeed.  It is shared
+ * by every sampler in the process, so that two samplers built in the same
+ * instant still get different seeds.
+ */
+var fallbackState atomic.Uint64
+
+/* newSeed picks the seed for a sampler built without WithSeed.  It reads the
+ * OS entropy source where there is one; targets without one (TinyGo on bare
+ * metal, some wasm hosts) fall back to fallbackSeed, which is unpredictable
+ * enough for simulation but must not be mistaken for real entropy.
+ */
+func newSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return fallbackSeed()
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]) >> 1)
+}
+
+/* fallbackSeed steps a splitmix64 generator whose state is mixed with the
+ * clock on every call.  On a target whose clock never advances it is still
+ * a fixed, distinct sequence of seeds, which is the best that can be done.
+ */
+func fallbackSeed() int64 {
+	z := fallbackState.Add(0x9e3779b97f4a7c15 ^ uint64(time.Now().UnixNano()))
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64((z ^ z>>31) >> 1)
+}
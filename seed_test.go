@@ -0,0 +1,34 @@
+package alias_sample
+
+import (
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestFallbackSeed(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(2, 1000).Draw(t, "n")
+		seen := make(map[int64]bool, n)
+		for range n {
+			seed := fallbackSeed()
+			if seed < 0 {
+				t.Fatalf("got negative seed %d\n", seed)
+			}
+			if seen[seed] {
+				t.Fatalf("seed %d repeated within %d calls\n", seed, n)
+			}
+			seen[seed] = true
+		}
+	})
+}
+
+func TestNewSeed(t *testing.T) {
+	a, b := newSeed(), newSeed()
+	if a < 0 || b < 0 {
+		t.Fatalf("got negative seeds %d, %d\n", a, b)
+	}
+	if a == b {
+		t.Fatalf("two seeds were both %d\n", a)
+	}
+}
@@ -0,0 +1,352 @@
+package alias_sample
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+)
+
+/* The binary form of a table, all little-endian:
+ *
+ *	magic      "ALIAS" and a version byte
+ *	mode       1 byte: tableMode
+ *	column     1 byte: columnKind
+ *	aliasWidth 1 byte: 4 or 8, the size of each alias entry
+ *	seed       8 bytes
+ *	n          8 bytes
+ *	only       8 bytes, the index under modeConstant
+ *	parents    8 bytes, the length of the Subset mapping (0 or n)
+ *	probability, alias and parent columns, under modeTable (parent
+ *	           regardless), each entry in its own width
+ *	checksum   4 bytes, CRC-32 (IEEE) of everything before it
+ */
+const (
+	binaryMagic   = "ALIAS\x01"
+	binaryHeader  = len(binaryMagic) + 3 + 4*8
+	binaryTrailer = 4
+)
+
+var _ interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+} = (*AliasSampler)(nil)
+
+// MarshalBinary encodes the table, with its seed and any Subset mapping,
+// so that it can be stored, embedded with go:embed, and loaded again with
+// Load without rebuilding it.  Nothing built on demand, such as the
+// running sums or draw counts, is included, nor are options like WithName
+// and WithMetrics, which describe how the sampler is used rather than the
+// table.
+func (s *AliasSampler) MarshalBinary() ([]byte, error) {
+	return s.appendBinary(make([]byte, 0, s.binarySize())), nil
+}
+
+// UnmarshalBinary replaces s with the sampler encoded in data by
+// MarshalBinary.  Its random source is freshly seeded with the stored
+// seed, so draws start the sequence over.
+func (s *AliasSampler) UnmarshalBinary(data []byte) error {
+	dec, err := decodeBinary(data)
+	if err != nil {
+		return err
+	}
+	*s = *dec
+	s.inline()
+	return nil
+}
+
+// Load decodes a sampler encoded by MarshalBinary.
+func Load(data []byte) (*AliasSampler, error) {
+	return decodeBinary(data)
+}
+
+func (s *AliasSampler) column() columnKind {
+	switch {
+	case s.probability32 != nil:
+		return columnFloat32
+	case s.probability16 != nil:
+		return columnFixed16
+	}
+	return columnFloat64
+}
+
+/* aliasWidth is the number of bytes each alias entry takes: 4 whenever the
+ * indices fit, which is nearly always.
+ */
+func (s *AliasSampler) aliasWidth() int {
+	if uint64(s.n) <= math.MaxUint32 {
+		return 4
+	}
+	return 8
+}
+
+func (s *AliasSampler) binarySize() int {
+	size := binaryHeader + binaryTrailer + 8*len(s.parent)
+	if s.mode == modeTable {
+		size += s.n * s.aliasWidth()
+		switch s.column() {
+		case columnFloat32:
+			size += 4 * s.n
+		case columnFixed16:
+			size += 2 * s.n
+		default:
+			size += 8 * s.n
+		}
+	}
+	return size
+}
+
+func (s *AliasSampler) appendBinary(b []byte) []byte {
+	start := len(b)
+	b = s.appendHeader(b)
+	if s.mode == modeTable {
+		b = s.appendProbabilities(b, 0, s.n)
+		b = s.appendAliases(b, 0, s.n)
+	}
+	b = s.appendParents(b, 0, len(s.parent))
+	return binary.LittleEndian.AppendUint32(b, crc32.ChecksumIEEE(b[start:]))
+}
+
+func (s *AliasSampler) appendHeader(b []byte) []byte {
+	le := binary.LittleEndian
+	b = append(b, binaryMagic...)
+	b = append(b, byte(s.mode), byte(s.column()), byte(s.aliasWidth()))
+	b = le.AppendUint64(b, uint64(s.seed))
+	b = le.AppendUint64(b, uint64(s.n))
+	b = le.AppendUint64(b, uint64(s.only))
+	return le.AppendUint64(b, uint64(len(s.parent)))
+}
+
+/* appendProbabilities, appendAliases and appendParents encode entries lo
+ * to hi of their column, so that a stream can write them a chunk at a
+ * time.
+ */
+func (s *AliasSampler) appendProbabilities(b []byte, lo, hi int) []byte {
+	le := binary.LittleEndian
+	switch s.column() {
+	case columnFloat32:
+		for _, p := range s.probability32[lo:hi] {
+			b = le.AppendUint32(b, math.Float32bits(p))
+		}
+	case columnFixed16:
+		for _, p := range s.probability16[lo:hi] {
+			b = le.AppendUint16(b, p)
+		}
+	default:
+		for _, p := range s.probability[lo:hi] {
+			b = le.AppendUint64(b, math.Float64bits(p))
+		}
+	}
+	return b
+}
+
+func (s *AliasSampler) appendAliases(b []byte, lo, hi int) []byte {
+	le := binary.LittleEndian
+	for i := lo; i < hi; i++ {
+		a := s.aliasOf(i)
+		if s.aliasWidth() == 4 {
+			b = le.AppendUint32(b, uint32(a))
+		} else {
+			b = le.AppendUint64(b, uint64(a))
+		}
+	}
+	return b
+}
+
+func (s *AliasSampler) appendParents(b []byte, lo, hi int) []byte {
+	for _, p := range s.parent[lo:hi] {
+		b = binary.LittleEndian.AppendUint64(b, uint64(p))
+	}
+	return b
+}
+
+/* binaryFields is what the fixed-size header of an encoded table says. */
+type binaryFields struct {
+	mode             tableMode
+	column           columnKind
+	width            int
+	seed             int64
+	n, only, parents uint64
+}
+
+/* parseHeader reads and checks the header at the start of data, which must
+ * be at least binaryHeader bytes long.
+ */
+func parseHeader(data []byte) (binaryFields, error) {
+	le := binary.LittleEndian
+	if string(data[:len(binaryMagic)]) != binaryMagic {
+		return binaryFields{}, &SampleError{"not an encoded alias table"}
+	}
+	h := data[len(binaryMagic):]
+	f := binaryFields{
+		mode:    tableMode(h[0]),
+		column:  columnKind(h[1]),
+		width:   int(h[2]),
+		seed:    int64(le.Uint64(h[3:])),
+		n:       le.Uint64(h[11:]),
+		only:    le.Uint64(h[19:]),
+		parents: le.Uint64(h[27:]),
+	}
+	if f.n == 0 || f.n > math.MaxInt || f.mode > modeConstant || f.column > columnFixed16 ||
+		(f.width != 4 && f.width != 8) || (f.parents != 0 && f.parents != f.n) ||
+		(f.mode == modeConstant && f.only >= f.n) {
+		return binaryFields{}, &SampleError{"encoded alias table has a bad header"}
+	}
+	return f, nil
+}
+
+/* probWidth is the number of bytes each probability entry takes. */
+func (f binaryFields) probWidth() uint64 {
+	switch f.column {
+	case columnFloat32:
+		return 4
+	case columnFixed16:
+		return 2
+	}
+	return 8
+}
+
+/* bodySize is the number of bytes between the header and the checksum.
+ * It overflows only for n beyond anything that could be stored, which
+ * callers rule out first.
+ */
+func (f binaryFields) bodySize() uint64 {
+	need := 8 * f.parents
+	if f.mode == modeTable {
+		need += f.n * (uint64(f.width) + f.probWidth())
+	}
+	return need
+}
+
+/* newSampler returns the sampler f describes, with its columns still to be
+ * filled in.
+ */
+func (f binaryFields) newSampler() *AliasSampler {
+	return &AliasSampler{seed: f.seed, n: int(f.n), mode: f.mode, only: int(f.only), cache: &tableCache{}}
+}
+
+func decodeBinary(data []byte) (*AliasSampler, error) {
+	le := binary.LittleEndian
+	if len(data) < binaryHeader+binaryTrailer || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, &SampleError{"not an encoded alias table"}
+	}
+	body, sum := data[:len(data)-binaryTrailer], le.Uint32(data[len(data)-binaryTrailer:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return nil, &SampleError{"encoded alias table is corrupt"}
+	}
+	f, err := parseHeader(body)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := body[binaryHeader:]
+	if (f.mode == modeTable || f.parents > 0) && f.n > uint64(len(rest)) {
+		/* Every column entry takes at least a byte; checking this first
+		 * keeps the size computation below from overflowing.
+		 */
+		return nil, &SampleError{"encoded alias table has the wrong length"}
+	}
+	if uint64(len(rest)) != f.bodySize() {
+		return nil, &SampleError{"encoded alias table has the wrong length"}
+	}
+
+	s := f.newSampler()
+	n := s.n
+	if f.mode == modeTable {
+		s.allocColumns(f, n)
+		rest = s.decodeProbabilities(rest, f, 0, n)
+		if rest, err = s.decodeAliases(rest, f, 0, n); err != nil {
+			return nil, err
+		}
+	}
+	if f.parents > 0 {
+		s.parent = make([]int, n)
+		if _, err = s.decodeParents(rest, 0, n); err != nil {
+			return nil, err
+		}
+	}
+	s.inline()
+	return s, nil
+}
+
+/* allocColumns makes s's probability and alias columns, with room for n
+ * entries.
+ */
+func (s *AliasSampler) allocColumns(f binaryFields, n int) {
+	switch f.column {
+	case columnFloat32:
+		s.probability32 = make([]float32, n)
+	case columnFixed16:
+		s.probability16 = make([]uint16, n)
+	default:
+		s.probability = make([]float64, n)
+	}
+	if f.n > wideIndices {
+		s.alias = make([]int, n)
+	} else {
+		s.alias32 = make([]uint32, n)
+	}
+}
+
+/* decodeProbabilities, decodeAliases and decodeParents fill entries lo to
+ * hi of their column, which must already be that long, from the start of
+ * data, and return what follows.  They check each entry, as the checksum
+ * alone can't rule out a table that was written wrong.
+ */
+func (s *AliasSampler) decodeProbabilities(data []byte, f binaryFields, lo, hi int) []byte {
+	le := binary.LittleEndian
+	switch f.column {
+	case columnFloat32:
+		for i := lo; i < hi; i++ {
+			s.probability32[i] = math.Float32frombits(le.Uint32(data))
+			data = data[4:]
+		}
+	case columnFixed16:
+		for i := lo; i < hi; i++ {
+			s.probability16[i] = le.Uint16(data)
+			data = data[2:]
+		}
+	default:
+		for i := lo; i < hi; i++ {
+			s.probability[i] = math.Float64frombits(le.Uint64(data))
+			data = data[8:]
+		}
+	}
+	return data
+}
+
+func (s *AliasSampler) decodeAliases(data []byte, f binaryFields, lo, hi int) ([]byte, error) {
+	le := binary.LittleEndian
+	for i := lo; i < hi; i++ {
+		var a uint64
+		if f.width == 4 {
+			a, data = uint64(le.Uint32(data)), data[4:]
+		} else {
+			a, data = le.Uint64(data), data[8:]
+		}
+		if a >= f.n {
+			return nil, &SampleError{"encoded alias table has an alias out of range"}
+		}
+		if s.alias32 != nil {
+			s.alias32[i] = uint32(a)
+		} else {
+			s.alias[i] = int(a)
+		}
+		/* Check the probability now that both columns are in. */
+		if p := s.prob(i); !(p >= 0 && p <= 1) {
+			return nil, &SampleError{"encoded alias table has a probability out of range"}
+		}
+	}
+	return data, nil
+}
+
+func (s *AliasSampler) decodeParents(data []byte, lo, hi int) ([]byte, error) {
+	for i := lo; i < hi; i++ {
+		p := binary.LittleEndian.Uint64(data)
+		data = data[8:]
+		if p > math.MaxInt {
+			return nil, &SampleError{"encoded alias table has a parent index out of range"}
+		}
+		s.parent[i] = int(p)
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+package alias_sample
+
+import (
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+		opts := []Option{WithSeed(rapid.Int64().Draw(t, "seed"))}
+		switch rapid.IntRange(0, 2).Draw(t, "column") {
+		case 1:
+			opts = append(opts, WithFloat32())
+		case 2:
+			opts = append(opts, WithFixed16())
+		}
+		as, _ := Init(probs, opts...)
+		if rapid.Bool().Draw(t, "subset") && len(probs) > 1 {
+			as, _ = as.Subset([]int{len(probs) - 1, 0})
+		}
+
+		data, err := as.MarshalBinary()
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if len(data) != as.binarySize() {
+			t.Fatalf("encoded %d bytes, expected %d\n", len(data), as.binarySize())
+		}
+		loaded, err := Load(data)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* The same table with the same seed draws the same sequence. */
+		fresh, _ := Load(data)
+		want := make([]int, 100)
+		got := make([]int, 100)
+		for i := range want {
+			want[i] = fresh.Next()
+			got[i] = loaded.Next()
+		}
+		if !slices.Equal(got, want) || loaded.Len() != as.Len() || loaded.mode != as.mode {
+			t.Fatalf("loaded sampler differs\n")
+		}
+		for i := range as.Len() {
+			if loaded.prob(i) != as.prob(i) || loaded.aliasOf(i) != as.aliasOf(i) || loaded.ParentIndex(i) != as.ParentIndex(i) {
+				t.Fatalf("column %d differs after loading\n", i)
+			}
+		}
+
+		/* Any single corrupted byte is caught. */
+		bad := slices.Clone(data)
+		bad[rapid.IntRange(0, len(bad)-1).Draw(t, "corrupt")] ^= 0x10
+		if _, err := Load(bad); err == nil {
+			t.Fatalf("corrupted table was accepted\n")
+		}
+	})
+
+	var s AliasSampler
+	if err := s.UnmarshalBinary([]byte("nonsense")); err == nil {
+		t.Errorf("garbage was accepted\n")
+	}
+	as, _ := Init([]float64{1, 1, 1}, WithSeed(3))
+	data, _ := as.MarshalBinary()
+	if err := s.UnmarshalBinary(data); err != nil || s.mode != modeUniform || s.Len() != 3 {
+		t.Errorf("uniform sampler: got err %v, sampler %v\n", err, &s)
+	}
+}
@@ -0,0 +1,83 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	r "math/rand"
+	"sync"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// Local is a Shard held in this process, and what a worker serving a
+// shard over RPC would wrap.
+type Local struct {
+	s     *alias_sample.AliasSampler
+	mass  float64
+	rands sync.Pool
+}
+
+// NewLocal builds a shard over weights, with options applied to its table
+// as for alias_sample.Init.
+func NewLocal(weights []float64, opts ...alias_sample.Option) (*Local, error) {
+	var mass float64
+	for _, w := range weights {
+		if !alias_sample.ValidWeight(w) {
+			return nil, fmt.Errorf("shard: weight %v", w)
+		}
+		mass += w
+	}
+	s, err := alias_sample.Init(weights, opts...)
+	if err != nil {
+		return nil, err
+	}
+	l := &Local{s: s, mass: mass}
+	l.rands.New = func() any {
+		return r.New(r.NewSource(r.Int63()))
+	}
+	return l, nil
+}
+
+// Draw returns k draws from the shard's table.
+func (l *Local) Draw(ctx context.Context, k int) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rng := l.rands.Get().(*r.Rand)
+	res := make([]int, k)
+	for i := range res {
+		res[i] = l.s.NextFrom(rng)
+	}
+	l.rands.Put(rng)
+	return res, nil
+}
+
+// Mass returns the total of the shard's weights, for its Spec.
+func (l *Local) Mass() float64 {
+	return l.mass
+}
+
+// Len returns the number of indices the shard holds.
+func (l *Local) Len() int {
+	return l.s.Len()
+}
+
+// Split divides weights into up to shards contiguous Local shards of
+// nearly equal length, returning their Specs, for running a sharded
+// distribution in one process or testing one.
+func Split(weights []float64, shards int, opts ...alias_sample.Option) ([]Spec, error) {
+	if shards < 1 {
+		return nil, fmt.Errorf("shard: cannot split into %d shards", shards)
+	}
+	shards = min(shards, len(weights))
+	var specs []Spec
+	for i := range shards {
+		lo, hi := i*len(weights)/shards, (i+1)*len(weights)/shards
+		l, err := NewLocal(weights[lo:hi], opts...)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, Spec{Shard: l, Mass: l.Mass(), Offset: lo})
+	}
+	return specs, nil
+}
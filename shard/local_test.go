@@ -0,0 +1,66 @@
+package shard
+
+import (
+	"context"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestSplit(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := rapid.SliceOfN(rapid.Float64Range(0, 3), 1, 40).Draw(t, "weights")
+		shards := rapid.IntRange(1, 50).Draw(t, "shards")
+		specs, err := Split(weights, shards)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		if len(specs) != min(shards, len(weights)) {
+			t.Fatalf("got %d shards, want %d\n", len(specs), min(shards, len(weights)))
+		}
+
+		/* The shards tile the weights in order, each with its mass. */
+		next := 0
+		for _, s := range specs {
+			l := s.Shard.(*Local)
+			if s.Offset != next {
+				t.Fatalf("shard starts at %d, want %d\n", s.Offset, next)
+			}
+			var mass float64
+			for _, w := range weights[next : next+l.Len()] {
+				mass += w
+			}
+			if s.Mass != mass {
+				t.Fatalf("shard mass %v, want %v\n", s.Mass, mass)
+			}
+			next += l.Len()
+		}
+		if next != len(weights) {
+			t.Fatalf("shards cover %d of %d weights\n", next, len(weights))
+		}
+	})
+}
+
+func TestLocal(t *testing.T) {
+	l, err := NewLocal([]float64{0, 1, 0})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	res, _ := l.Draw(context.Background(), 100)
+	for _, i := range res {
+		if i != 1 {
+			t.Fatalf("drew %d\n", i)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Draw(ctx, 1); err == nil {
+		t.Fatalf("expected error from a cancelled context\n")
+	}
+	if _, err := NewLocal([]float64{1, -1}); err == nil {
+		t.Fatalf("expected error for a negative weight\n")
+	}
+	if _, err := Split([]float64{1}, 0); err == nil {
+		t.Fatalf("expected error for no shards\n")
+	}
+}
@@ -0,0 +1,211 @@
+// Package shard splits a distribution too large for one machine into
+// shards held by separate workers, with a coordinator that routes each
+// draw to a shard by the shard's share of the total weight.
+//
+// A draw picks a shard from an alias table over the shards' masses and
+// asks that shard for an index from its own table, which is exact as long
+// as each shard's mass is the total weight of the indices it holds.
+// Batches are split by shard, with one call to each shard involved made
+// in parallel, and the answers put back in the order the shards were
+// picked, so a batch is as random as the same number of single draws.
+package shard
+
+import (
+	"context"
+	"fmt"
+	r "math/rand"
+	"sync"
+	"sync/atomic"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+// A Shard draws indices from its part of the distribution, numbered from
+// zero within the shard.  It is the hook for shards held elsewhere:
+// implement Draw with an RPC to the worker holding the shard.
+type Shard interface {
+	// Draw returns k independent draws.  It must be safe for concurrent
+	// use.
+	Draw(ctx context.Context, k int) ([]int, error)
+}
+
+// ShardFunc adapts a function to a Shard.
+type ShardFunc func(ctx context.Context, k int) ([]int, error)
+
+func (f ShardFunc) Draw(ctx context.Context, k int) ([]int, error) {
+	return f(ctx, k)
+}
+
+// Spec describes one shard to a Coordinator.
+type Spec struct {
+	Shard Shard
+	// Mass is the total weight of the shard's indices, on the same scale
+	// as every other shard's.
+	Mass float64
+	// Offset is added to the shard's indices to give indices in the whole
+	// distribution.
+	Offset int
+}
+
+// Coordinator routes draws to shards.  All methods are safe for concurrent
+// use; SetMass swaps in a new routing table atomically, so draws in flight
+// finish with the masses they started with.
+type Coordinator struct {
+	current atomic.Pointer[routing]
+	rands   sync.Pool
+	mu      sync.Mutex // serializes SetMass
+}
+
+/* routing is one immutable version of the routing table. */
+type routing struct {
+	specs []Spec
+	top   *alias_sample.AliasSampler
+}
+
+// New returns a coordinator over shards.
+func New(shards []Spec) (*Coordinator, error) {
+	rt, err := newRouting(append([]Spec(nil), shards...))
+	if err != nil {
+		return nil, err
+	}
+	c := &Coordinator{}
+	c.rands.New = func() any {
+		return r.New(r.NewSource(r.Int63()))
+	}
+	c.current.Store(rt)
+	return c, nil
+}
+
+func newRouting(specs []Spec) (*routing, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("shard: no shards")
+	}
+	masses := make([]float64, len(specs))
+	positive := false
+	for i, s := range specs {
+		if s.Shard == nil {
+			return nil, fmt.Errorf("shard: shard %d is nil", i)
+		}
+		if !alias_sample.ValidWeight(s.Mass) {
+			return nil, fmt.Errorf("shard: shard %d has mass %v", i, s.Mass)
+		}
+		masses[i] = s.Mass
+		positive = positive || s.Mass > 0
+	}
+	if !positive {
+		return nil, fmt.Errorf("shard: every shard has zero mass")
+	}
+	top, _ := alias_sample.InitInPlace(masses)
+	return &routing{specs: specs, top: top}, nil
+}
+
+// SetMass changes shard i's mass, as after its weights change.  The
+// shard's own table and its mass should be updated together: until both
+// are, draws are off by the difference.
+func (c *Coordinator) SetMass(i int, mass float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cur := c.current.Load()
+	if i < 0 || i >= len(cur.specs) {
+		return fmt.Errorf("shard: no shard %d", i)
+	}
+	specs := append([]Spec(nil), cur.specs...)
+	specs[i].Mass = mass
+	rt, err := newRouting(specs)
+	if err != nil {
+		return err
+	}
+	c.current.Store(rt)
+	return nil
+}
+
+// Draw returns one index from the whole distribution.
+func (c *Coordinator) Draw(ctx context.Context) (int, error) {
+	res, err := c.DrawN(ctx, 1)
+	if err != nil {
+		return 0, err
+	}
+	return res[0], nil
+}
+
+// DrawN returns n indices from the whole distribution.  If any shard
+// fails, the others' calls are cancelled and the first error returned.
+func (c *Coordinator) DrawN(ctx context.Context, n int) ([]int, error) {
+	rng := c.rands.Get().(*r.Rand)
+	res, err := c.DrawNFrom(ctx, n, rng)
+	c.rands.Put(rng)
+	return res, err
+}
+
+// DrawNFrom is DrawN picking shards with rng, which must not be used
+// concurrently.  The draws are only reproducible if the shards' are.
+func (c *Coordinator) DrawNFrom(ctx context.Context, n int, rng *r.Rand) ([]int, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	rt := c.current.Load()
+	picks := make([]int, n)
+	counts := make([]int, len(rt.specs))
+	for i := range picks {
+		picks[i] = rt.top.NextFrom(rng)
+		counts[picks[i]]++
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	answers := make([][]int, len(rt.specs))
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for i, k := range counts {
+		if k == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := rt.specs[i].Shard.Draw(ctx, k)
+			if err == nil && len(got) != k {
+				err = fmt.Errorf("returned %d draws, not %d", len(got), k)
+			}
+			if err != nil {
+				/* Later errors are likely just the cancellation. */
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("shard: shard %d: %w", i, err)
+				}
+				errMu.Unlock()
+				cancel()
+				return
+			}
+			answers[i] = got
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	/* Hand each shard's draws out to the slots that picked it, in order.
+	 * The picks were independent, so this is too.
+	 */
+	res := make([]int, n)
+	next := make([]int, len(rt.specs))
+	for i, s := range picks {
+		res[i] = rt.specs[s].Offset + answers[s][next[s]]
+		next[s]++
+	}
+	return res, nil
+}
+
+// Masses returns each shard's current mass.
+func (c *Coordinator) Masses() []float64 {
+	rt := c.current.Load()
+	res := make([]float64, len(rt.specs))
+	for i, s := range rt.specs {
+		res[i] = s.Mass
+	}
+	return res
+}
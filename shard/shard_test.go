@@ -0,0 +1,134 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestCoordinator(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := rapid.SliceOfN(rapid.Float64Range(0, 3), 1, 40).Draw(t, "weights")
+		weights[0] += 0.5
+		specs, err := Split(weights, rapid.IntRange(1, 8).Draw(t, "shards"))
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		c, err := New(specs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		/* The sharded draws follow the whole distribution. */
+		var tot float64
+		for _, w := range weights {
+			tot += w
+		}
+		sz := 50_000
+		got := make([]float64, len(weights))
+		for done := 0; done < sz; {
+			k := rapid.IntRange(1, 5000).Draw(t, "batch")
+			k = min(k, sz-done)
+			res, err := c.DrawN(context.Background(), k)
+			if err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+			if len(res) != k {
+				t.Fatalf("got %d draws, want %d\n", len(res), k)
+			}
+			for _, i := range res {
+				got[i]++
+			}
+			done += k
+		}
+		for i, w := range weights {
+			p := w / tot
+			if math.Abs(got[i]-float64(sz)*p) > 6*math.Sqrt(float64(sz)*p*(1-p))+1 {
+				t.Fatalf("drew %d %v times of %d, want p %v\n", i, got[i], sz, p)
+			}
+		}
+	})
+}
+
+func TestCoordinatorBatchOrder(t *testing.T) {
+	/* Two equal shards: within a batch, the shard of each draw should
+	 * be independent of the one before, not grouped.
+	 */
+	specs, _ := Split([]float64{1, 1}, 2)
+	c, _ := New(specs)
+	res, _ := c.DrawN(context.Background(), 10_000)
+	same := 0
+	for i := 1; i < len(res); i++ {
+		if res[i] == res[i-1] {
+			same++
+		}
+	}
+	if math.Abs(float64(same)-5000) > 6*50 {
+		t.Fatalf("%d of 9999 neighbours drew the same shard\n", same)
+	}
+}
+
+func TestSetMass(t *testing.T) {
+	specs, _ := Split([]float64{1, 1, 1}, 3)
+	c, _ := New(specs)
+	if err := c.SetMass(1, 0); err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	res, _ := c.DrawN(context.Background(), 1000)
+	for _, i := range res {
+		if i == 1 {
+			t.Fatalf("drew from a shard with no mass\n")
+		}
+	}
+	if m := c.Masses(); m[0] != 1 || m[1] != 0 || m[2] != 1 {
+		t.Fatalf("masses are %v\n", m)
+	}
+	if err := c.SetMass(3, 1); err == nil {
+		t.Fatalf("expected error for a missing shard\n")
+	}
+	c.SetMass(0, 0)
+	if err := c.SetMass(2, 0); err == nil {
+		t.Fatalf("expected error for all shards at zero mass\n")
+	}
+}
+
+func TestCoordinatorErrors(t *testing.T) {
+	failure := errors.New("worker down")
+	cancelled := make(chan struct{})
+	specs := []Spec{
+		{Shard: ShardFunc(func(ctx context.Context, k int) ([]int, error) {
+			return nil, failure
+		}), Mass: 1},
+		{Shard: ShardFunc(func(ctx context.Context, k int) ([]int, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		}), Mass: 1, Offset: 10},
+	}
+	c, _ := New(specs)
+	if _, err := c.DrawN(context.Background(), 1000); !errors.Is(err, failure) {
+		t.Fatalf("got err %v, want the shard's failure\n", err)
+	}
+	<-cancelled
+
+	short, _ := New([]Spec{{Shard: ShardFunc(func(ctx context.Context, k int) ([]int, error) {
+		return make([]int, k-1), nil
+	}), Mass: 1}})
+	if _, err := short.Draw(context.Background()); err == nil {
+		t.Fatalf("expected error for a shard returning too few draws\n")
+	}
+
+	for name, specs := range map[string][]Spec{
+		"none":     nil,
+		"nil":      {{Mass: 1}},
+		"negative": {{Shard: specs[0].Shard, Mass: -1}},
+		"zero":     {{Shard: specs[0].Shard, Mass: 0}},
+	} {
+		if _, err := New(specs); err == nil {
+			t.Fatalf("%s: expected error\n", name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package alias_sample
+
+import (
+	"container/heap"
+)
+
+// WithSquaredHistogram builds the table with Marsaglia's squared histogram
+// ("Robin Hood") method instead of Vose's.  Rather than pairing small and
+// large entries in arbitrary order, each step tops up the poorest column
+// from the richest remaining entry.  That keeps every residual as far from
+// zero as possible, so the table rounds differently from Vose's, which is
+// useful for comparing the two on troublesome inputs.  Construction takes
+// O(n log n) rather than O(n) time, and it takes precedence over
+// WithParallelism.
+//
+// See G. Marsaglia, W. W. Tsang and J. Wang, "Fast Generation of Discrete
+// Random Variables", Journal of Statistical Software 11(3), 2004.
+func WithSquaredHistogram() Option {
+	return func(c *config) {
+		c.squared = true
+	}
+}
+
+func (v *vose) buildSquared() {
+	n := len(v.probs2)
+	v.diag.total = v.total(0, n)
+	v.normalize(0, n, v.diag.total)
+
+	small := &indexHeap{less: func(a, b int) bool { return v.probs2[a] < v.probs2[b] }}
+	large := &indexHeap{less: func(a, b int) bool { return v.probs2[a] > v.probs2[b] }}
+	for i, p := range v.probs2 {
+		if p >= v.average {
+			large.idx = append(large.idx, i)
+		} else {
+			small.idx = append(small.idx, i)
+		}
+	}
+	v.diag.small, v.diag.large = small.Len(), large.Len()
+	heap.Init(small)
+	heap.Init(large)
+
+	for small.Len() != 0 && large.Len() != 0 {
+		/* Rob from the richest to give to the poorest. */
+		less := heap.Pop(small).(int)
+		more := heap.Pop(large).(int)
+
+		v.setProb(less, v.probs2[less]*float64(n))
+		v.setAlias(less, more)
+
+		v.probs2[more] = (v.probs2[more] + v.probs2[less]) - v.average
+		if v.probs2[more] >= v.average {
+			heap.Push(large, more)
+		} else {
+			heap.Push(small, more)
+		}
+	}
+
+	/* As with Vose's method, whatever is left over should be full. */
+	for _, i := range append(small.idx, large.idx...) {
+		v.settle(i)
+	}
+}
+
+/* indexHeap is a heap of indices ordered by less. */
+type indexHeap struct {
+	idx  []int
+	less func(a, b int) bool
+}
+
+func (h *indexHeap) Len() int           { return len(h.idx) }
+func (h *indexHeap) Less(i, j int) bool { return h.less(h.idx[i], h.idx[j]) }
+func (h *indexHeap) Swap(i, j int)      { h.idx[i], h.idx[j] = h.idx[j], h.idx[i] }
+func (h *indexHeap) Push(x any)         { h.idx = append(h.idx, x.(int)) }
+
+func (h *indexHeap) Pop() any {
+	x := h.idx[len(h.idx)-1]
+	h.idx = h.idx[:len(h.idx)-1]
+	return x
+}
@@ -0,0 +1,29 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestSquaredHistogram(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 1e6), 2, 200).Draw(t, "probs")
+		probs[0] += 1
+		as, err := Init(probs, WithSquaredHistogram())
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		for i, p := range tableProbs(as) {
+			if math.Abs(p-probs[i]/tot) > 1e-12 {
+				t.Fatalf("index %d: got %g, want %g\n", i, p, probs[i]/tot)
+			}
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package alias_sample
+
+import (
+	"strconv"
+	"time"
+)
+
+// Stats describes a sampler's memory use and how its table was built.
+type Stats struct {
+	// TableBytes is the memory held by the probability and alias columns.
+	TableBytes int
+	// CacheBytes is the memory held by what has been built on demand
+	// since then: the distribution kept by Prob, the running sums kept by
+	// Quantile and NextInRange, and the counts kept by WithTracking.
+	CacheBytes int
+	// BuildTime is how long it took to build the table, not counting
+	// copying the weights.
+	BuildTime time.Duration
+	// Pairings is how many columns were filled by pairing a small entry
+	// with a large one.  The rest were left over at the end and rounded
+	// up to full columns.
+	Pairings int
+	// Residual is the largest amount by which one of those leftover
+	// columns had to be rounded, relative to a full column.  In exact
+	// arithmetic it is zero, so it measures the rounding error of the
+	// build.
+	Residual float64
+}
+
+// Stats returns the sampler's Stats.
+func (s *AliasSampler) Stats() Stats {
+	st := s.stats
+	st.TableBytes = 8*len(s.probability) + 4*len(s.probability32) +
+		2*len(s.probability16) + strconv.IntSize/8*len(s.alias) + 4*len(s.alias32)
+	st.CacheBytes = 8 * len(s.counts)
+	if s.cache != nil {
+		if dist := s.cache.dist.Load(); dist != nil {
+			st.CacheBytes += 8 * len(*dist)
+		}
+		if sums := s.cache.sums.Load(); sums != nil {
+			st.CacheBytes += 8 * len(sums.cumulative)
+		}
+	}
+	return st
+}
@@ -0,0 +1,59 @@
+package alias_sample
+
+import (
+	"strconv"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestStats(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0.001, 5.0), 2, 100).Draw(t, "probs")
+		probs[0] += 1
+		opts := []Option{}
+		perEntry, aliasBytes := 8, 4
+		switch rapid.IntRange(0, 4).Draw(t, "kind") {
+		case 1:
+			opts = append(opts, WithFloat32())
+			perEntry = 4
+		case 2:
+			opts = append(opts, WithFixed16())
+			perEntry = 2
+		case 3:
+			opts = append(opts, WithSquaredHistogram())
+		case 4:
+			opts = append(opts, WithIntAliases())
+			aliasBytes = strconv.IntSize / 8
+		}
+		as, _ := Init(probs, opts...)
+		if as.tiny {
+			aliasBytes = strconv.IntSize / 8
+		}
+
+		st := as.Stats()
+		if want := len(probs) * (perEntry + aliasBytes); st.TableBytes != want {
+			t.Fatalf("got %d table bytes, want %d\n", st.TableBytes, want)
+		}
+		if st.CacheBytes != 0 {
+			t.Fatalf("got %d cache bytes before any queries\n", st.CacheBytes)
+		}
+		if st.Pairings < 1 || st.Pairings >= len(probs) {
+			t.Fatalf("got %d pairings for %d entries\n", st.Pairings, len(probs))
+		}
+		if st.Residual > 1e-9 || st.BuildTime < 0 {
+			t.Fatalf("got stats %+v\n", st)
+		}
+
+		as.Prob(0)
+		as.CDF(0)
+		if got := as.Stats().CacheBytes; got != 16*len(probs) {
+			t.Fatalf("got %d cache bytes, want %d\n", got, 16*len(probs))
+		}
+	})
+
+	uniform, _ := Init([]float64{1, 1, 1})
+	if st := uniform.Stats(); st.TableBytes != 0 || st.Pairings != 0 {
+		t.Errorf("uniform sampler: got stats %+v\n", st)
+	}
+}
@@ -0,0 +1,210 @@
+package alias_sample
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+/* streamChunk is the most WriteTo and ReadFrom buffer at once, in bytes. */
+const streamChunk = 1 << 16
+
+var (
+	_ io.WriterTo   = (*AliasSampler)(nil)
+	_ io.ReaderFrom = (*AliasSampler)(nil)
+)
+
+// WriteTo writes the same encoding as MarshalBinary to w, a chunk at a
+// time, so that a table of any size can be saved without holding a second
+// copy of it in memory.
+func (s *AliasSampler) WriteTo(w io.Writer) (int64, error) {
+	sw := &streamWriter{w: w, crc: crc32.NewIEEE(), buf: make([]byte, 0, streamChunk)}
+	sw.buf = s.appendHeader(sw.buf)
+	if s.mode == modeTable {
+		f := binaryFields{column: s.column()}
+		sw.column(s.n, int(f.probWidth()), s.appendProbabilities)
+		sw.column(s.n, s.aliasWidth(), s.appendAliases)
+	}
+	sw.column(len(s.parent), 8, s.appendParents)
+	sw.flush()
+	var sum [binaryTrailer]byte
+	binary.LittleEndian.PutUint32(sum[:], sw.crc.Sum32())
+	sw.write(sum[:])
+	return sw.total, sw.err
+}
+
+// ReadFrom replaces s with a sampler read from r, in the encoding of
+// MarshalBinary, reading exactly as many bytes as that takes, so several
+// tables can be read in turn from one stream.  It grows the table as its
+// data arrives rather than trusting the header up front, so a corrupt or
+// truncated stream can't make it allocate much more than it has read.
+// As with UnmarshalBinary, s is only replaced if the whole table reads
+// and checks out.
+func (s *AliasSampler) ReadFrom(r io.Reader) (int64, error) {
+	sr := &streamReader{r: r, crc: crc32.NewIEEE(), buf: make([]byte, streamChunk)}
+	header := sr.read(binaryHeader)
+	if sr.err != nil {
+		return sr.total, sr.err
+	}
+	f, err := parseHeader(header)
+	if err != nil {
+		return sr.total, err
+	}
+	/* Rule out sizes that could overflow bodySize. */
+	if f.n > math.MaxInt64/32 {
+		return sr.total, &SampleError{"encoded alias table has a bad header"}
+	}
+
+	dec := f.newSampler()
+	n := dec.n
+	if f.mode == modeTable {
+		dec.allocColumns(f, 0)
+		err = sr.column(n, int(f.probWidth()), func(data []byte, lo, hi int) error {
+			switch f.column {
+			case columnFloat32:
+				dec.probability32 = extend(dec.probability32, hi, n)
+			case columnFixed16:
+				dec.probability16 = extend(dec.probability16, hi, n)
+			default:
+				dec.probability = extend(dec.probability, hi, n)
+			}
+			dec.decodeProbabilities(data, f, lo, hi)
+			return nil
+		})
+		if err == nil {
+			err = sr.column(n, f.width, func(data []byte, lo, hi int) error {
+				if dec.alias32 != nil {
+					dec.alias32 = extend(dec.alias32, hi, n)
+				} else {
+					dec.alias = extend(dec.alias, hi, n)
+				}
+				_, err := dec.decodeAliases(data, f, lo, hi)
+				return err
+			})
+		}
+	}
+	if err == nil && f.parents > 0 {
+		err = sr.column(n, 8, func(data []byte, lo, hi int) error {
+			dec.parent = extend(dec.parent, hi, n)
+			_, err := dec.decodeParents(data, lo, hi)
+			return err
+		})
+	}
+	if err != nil {
+		return sr.total, err
+	}
+
+	want := sr.crc.Sum32()
+	trailer := sr.read(binaryTrailer)
+	if sr.err != nil {
+		return sr.total, sr.err
+	}
+	if binary.LittleEndian.Uint32(trailer) != want {
+		return sr.total, &SampleError{"encoded alias table is corrupt"}
+	}
+	*s = *dec
+	s.inline()
+	return sr.total, nil
+}
+
+/* streamWriter buffers writes to w, checksumming what it flushes and
+ * keeping the first error.
+ */
+type streamWriter struct {
+	w     io.Writer
+	crc   hash.Hash32
+	buf   []byte
+	total int64
+	err   error
+}
+
+/* column encodes n entries of width bytes each with add, as many at a time
+ * as fit in the buffer.
+ */
+func (sw *streamWriter) column(n, width int, add func(b []byte, lo, hi int) []byte) {
+	per := streamChunk / width
+	for lo := 0; lo < n && sw.err == nil; lo += per {
+		hi := min(lo+per, n)
+		if len(sw.buf)+(hi-lo)*width > cap(sw.buf) {
+			sw.flush()
+		}
+		sw.buf = add(sw.buf, lo, hi)
+	}
+}
+
+func (sw *streamWriter) flush() {
+	sw.crc.Write(sw.buf)
+	sw.write(sw.buf)
+	sw.buf = sw.buf[:0]
+}
+
+func (sw *streamWriter) write(b []byte) {
+	if sw.err != nil {
+		return
+	}
+	k, err := sw.w.Write(b)
+	sw.total += int64(k)
+	sw.err = err
+}
+
+/* streamReader reads exact amounts from r into a reused buffer,
+ * checksumming them and keeping the first error.
+ */
+type streamReader struct {
+	r     io.Reader
+	crc   hash.Hash32
+	buf   []byte
+	total int64
+	err   error
+}
+
+/* read returns the next k bytes, which stay valid until the next read. */
+func (sr *streamReader) read(k int) []byte {
+	if sr.err != nil {
+		return nil
+	}
+	b := sr.buf[:k]
+	got, err := io.ReadFull(sr.r, b)
+	sr.total += int64(got)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		sr.err = err
+		return nil
+	}
+	sr.crc.Write(b)
+	return b
+}
+
+/* column reads n entries of width bytes each, handing them to decode as
+ * many at a time as fit in the buffer.
+ */
+func (sr *streamReader) column(n, width int, decode func(data []byte, lo, hi int) error) error {
+	per := streamChunk / width
+	for lo := 0; lo < n; lo += per {
+		hi := min(lo+per, n)
+		data := sr.read((hi - lo) * width)
+		if sr.err != nil {
+			return sr.err
+		}
+		if err := decode(data, lo, hi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* extend returns col lengthened to hi, doubling its capacity when it runs
+ * out but never past limit, the length the header promises.
+ */
+func extend[T any](col []T, hi, limit int) []T {
+	if hi <= cap(col) {
+		return col[:hi]
+	}
+	grown := make([]T, hi, min(limit, max(2*cap(col), hi)))
+	copy(grown, col)
+	return grown
+}
@@ -0,0 +1,126 @@
+package alias_sample
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestWriteToReadFrom(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		/* Big enough tables span several chunks. */
+		n := rapid.IntRange(1, 3*streamChunk/8).Draw(t, "n")
+		probs := make([]float64, n)
+		for i := range probs {
+			probs[i] = float64(i%13 + 1)
+		}
+		opts := []Option{WithSeed(rapid.Int64().Draw(t, "seed"))}
+		switch rapid.IntRange(0, 2).Draw(t, "column") {
+		case 1:
+			opts = append(opts, WithFloat32())
+		case 2:
+			opts = append(opts, WithFixed16())
+		}
+		as, _ := Init(probs, opts...)
+		if rapid.Bool().Draw(t, "subset") && n > 1 {
+			as, _ = as.Subset([]int{n - 1, 0})
+		}
+
+		/* The stream is the same encoding as MarshalBinary. */
+		var buf bytes.Buffer
+		written, err := as.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		data, _ := as.MarshalBinary()
+		if written != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("streamed %d bytes unlike the %d MarshalBinary gives\n", written, len(data))
+		}
+
+		/* Two tables back to back read back in turn. */
+		as.WriteTo(&buf)
+		for range 2 {
+			var loaded AliasSampler
+			read, err := loaded.ReadFrom(&buf)
+			if err != nil {
+				t.Fatalf("got err %v\n", err)
+			}
+			if read != written {
+				t.Fatalf("read %d bytes of %d\n", read, written)
+			}
+			again, _ := loaded.MarshalBinary()
+			if !bytes.Equal(again, data) {
+				t.Fatalf("table read back differs\n")
+			}
+		}
+
+		/* A truncated stream fails, and leaves the sampler alone. */
+		cut := rapid.IntRange(0, len(data)-1).Draw(t, "cut")
+		keep, _ := Init([]float64{1, 2})
+		before, _ := keep.MarshalBinary()
+		if _, err := keep.ReadFrom(bytes.NewReader(data[:cut])); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("truncated stream gave %v\n", err)
+		}
+		if after, _ := keep.MarshalBinary(); !bytes.Equal(after, before) {
+			t.Fatalf("failed read changed the sampler\n")
+		}
+
+		/* Any single corrupted byte is caught. */
+		bad := slices.Clone(data)
+		bad[rapid.IntRange(0, len(bad)-1).Draw(t, "corrupt")] ^= 0x10
+		if _, err := keep.ReadFrom(bytes.NewReader(bad)); err == nil {
+			t.Fatalf("corrupted stream was accepted\n")
+		}
+	})
+}
+
+type failingWriter struct {
+	left int
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if len(b) > w.left {
+		k := w.left
+		w.left = 0
+		return k, errors.New("disk full")
+	}
+	w.left -= len(b)
+	return len(b), nil
+}
+
+func TestWriteToError(t *testing.T) {
+	probs := make([]float64, streamChunk)
+	for i := range probs {
+		probs[i] = float64(i + 1)
+	}
+	as, _ := Init(probs)
+	w := &failingWriter{left: streamChunk + 10}
+	written, err := as.WriteTo(w)
+	if err == nil {
+		t.Fatalf("write error was lost\n")
+	}
+	if written != streamChunk+10 {
+		t.Fatalf("reported %d bytes written, want %d\n", written, streamChunk+10)
+	}
+}
+
+func TestReadFromHugeHeader(t *testing.T) {
+	/* A header promising a vast table, followed by nothing, must fail
+	 * without allocating for it.  The size is taken from MaxInt so that
+	 * it passes the header checks on 32-bit platforms too.
+	 */
+	as, _ := Init([]float64{1, 2, 3})
+	data, _ := as.MarshalBinary()
+	header := slices.Clone(data[:binaryHeader])
+	binary.LittleEndian.PutUint64(header[len(binaryMagic)+3+8:], math.MaxInt/64)
+	var s AliasSampler
+	if _, err := s.ReadFrom(bytes.NewReader(header)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("huge header gave %v\n", err)
+	}
+}
@@ -0,0 +1,52 @@
+package alias_sample
+
+// Subset returns a sampler over just the given indices of s, with their
+// probabilities renormalized to sum to 1.  It draws positions in indices,
+// so a draw of i stands for indices[i] in s; ParentIndex makes that
+// translation.  indices must be distinct, in range, and include at least
+// one index that s can draw.  Options are applied as for Init.
+func (s *AliasSampler) Subset(indices []int, opts ...Option) (*AliasSampler, error) {
+	if len(indices) == 0 {
+		return nil, &SampleError{"no indices provided"}
+	}
+
+	dist := s.distribution()
+	seen := make(map[int]struct{}, len(indices))
+	weights := make([]float64, len(indices))
+	for k, i := range indices {
+		if i < 0 || i >= s.n {
+			return nil, &SampleError{"subset index out of range"}
+		}
+		if _, dup := seen[i]; dup {
+			return nil, &SampleError{"duplicate subset index"}
+		}
+		seen[i] = struct{}{}
+		weights[k] = dist[i]
+	}
+	if err := checkWeights(weights); err != nil {
+		return nil, err
+	}
+
+	sub, err := InitInPlace(weights, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	/* Compose with the parent's own mapping, so that a subset of a subset
+	 * still maps back to the original index space.
+	 */
+	sub.parent = make([]int, len(indices))
+	for k, i := range indices {
+		sub.parent[k] = s.ParentIndex(i)
+	}
+	return sub, nil
+}
+
+// ParentIndex returns the index that i stands for in the sampler s was
+// derived from by Subset.  For samplers not made by Subset it returns i.
+func (s *AliasSampler) ParentIndex(i int) int {
+	if s.parent == nil {
+		return i
+	}
+	return s.parent[i]
+}
@@ -0,0 +1,41 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSubset(t *testing.T) {
+	as, err := Init([]float64{1, 2, 3, 4, 0})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	sub, err := as.Subset([]int{3, 1, 4})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	want := []float64{4.0 / 6, 2.0 / 6, 0}
+	for i, p := range tableProbs(sub) {
+		if math.Abs(p-want[i]) > 1e-12 {
+			t.Errorf("subset index %d: got %g, want %g\n", i, p, want[i])
+		}
+	}
+
+	subsub, err := sub.Subset([]int{1, 2})
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if subsub.ParentIndex(0) != 1 || subsub.ParentIndex(1) != 4 || sub.ParentIndex(0) != 3 {
+		t.Errorf("subset indices map back wrongly\n")
+	}
+	if d := subsub.Next(); d != 0 {
+		t.Errorf("drew %d, only position 0 has weight\n", d)
+	}
+
+	for _, bad := range [][]int{nil, {5}, {-1}, {1, 1}, {4}} {
+		if _, err := as.Subset(bad); err == nil {
+			t.Errorf("subset %v was accepted\n", bad)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package alias_sample
+
+import (
+	"fmt"
+	"hash/fnv"
+	r "math/rand"
+	"sync"
+)
+
+// TemplateFuncs returns template functions for picking weighted variants
+// inline.  The result can be passed to Funcs in either text/template or
+// html/template:
+//
+//	{{weightedChoice "red" 3 "green" 2 "blue" 1}}
+//	{{weightedChoiceKeyed .UserID "old" 9 "new" 1}}
+//
+// Both take alternating values and weights, where a weight is any integer
+// or float type.  weightedChoice draws from a source seeded with seed, so a
+// run of renders is reproducible (when they happen in the same order).
+// weightedChoiceKeyed derives its draw from seed and the key alone, so the
+// same key always picks the same variant, whichever render it is in.  The
+// functions are safe to use from concurrent renders.
+func TemplateFuncs(seed int64) map[string]any {
+	var mu sync.Mutex
+	rng := r.New(r.NewSource(seed))
+
+	return map[string]any{
+		"weightedChoice": func(pairs ...any) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return weightedChoice(rng, pairs)
+		},
+		"weightedChoiceKeyed": func(key any, pairs ...any) (any, error) {
+			h := fnv.New64a()
+			fmt.Fprintf(h, "%d\x00%v", seed, key)
+			return weightedChoice(r.New(r.NewSource(int64(h.Sum64()))), pairs)
+		},
+	}
+}
+
+func weightedChoice(rng *r.Rand, pairs []any) (any, error) {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return nil, &SampleError{"weightedChoice takes alternating values and weights"}
+	}
+	weights := make([]float64, len(pairs)/2)
+	for i := range weights {
+		w, ok := toWeight(pairs[2*i+1])
+		if !ok {
+			return nil, &SampleError{fmt.Sprintf("weightedChoice: weight %v is not a number", pairs[2*i+1])}
+		}
+		weights[i] = w
+	}
+	if err := checkWeights(weights); err != nil {
+		return nil, err
+	}
+
+	s, err := InitLinear(weights)
+	if err != nil {
+		return nil, err
+	}
+	return pairs[2*s.NextFrom(rng)], nil
+}
+
+func toWeight(x any) (float64, bool) {
+	switch w := x.(type) {
+	case int:
+		return float64(w), true
+	case int8:
+		return float64(w), true
+	case int16:
+		return float64(w), true
+	case int32:
+		return float64(w), true
+	case int64:
+		return float64(w), true
+	case uint:
+		return float64(w), true
+	case uint8:
+		return float64(w), true
+	case uint16:
+		return float64(w), true
+	case uint32:
+		return float64(w), true
+	case uint64:
+		return float64(w), true
+	case float32:
+		return float64(w), true
+	case float64:
+		return w, true
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package alias_sample
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl := template.Must(template.New("t").Funcs(TemplateFuncs(1)).Parse(
+		`{{weightedChoice "red" 3 "green" 1.5 "blue" 0}}`))
+	counts := map[string]int{}
+	for range 4000 {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, nil); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		counts[b.String()]++
+	}
+	if counts["blue"] != 0 || counts["red"] < 2400 || counts["red"] > 2900 {
+		t.Fatalf("got picks %v\n", counts)
+	}
+
+	/* A keyed pick depends only on the seed and the key. */
+	keyed := template.Must(template.New("k").Funcs(TemplateFuncs(1)).Parse(
+		`{{range .}}{{weightedChoiceKeyed . "a" 1 "b" 1 "c" 1}}{{end}}`))
+	render := func(keys []int) string {
+		var b strings.Builder
+		if err := keyed.Execute(&b, keys); err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		return b.String()
+	}
+	keys := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	first := render(keys)
+	if again := render(keys); again != first {
+		t.Fatalf("keyed picks changed between renders: %q then %q\n", first, again)
+	}
+	if single := render(keys[3:4]); single != first[3:4] {
+		t.Fatalf("key 4 picked %q alone but %q in a batch\n", single, first[3:4])
+	}
+
+	bad := template.Must(template.New("b").Funcs(TemplateFuncs(1)).Parse(`{{weightedChoice "a" "x"}}`))
+	if err := bad.Execute(&strings.Builder{}, nil); err == nil {
+		t.Fatalf("non-numeric weight was accepted\n")
+	}
+}
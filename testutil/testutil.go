@@ -0,0 +1,130 @@
+// Package testutil generates pathological weight vectors for property tests
+// and fuzzing of alias_sample and the code built on it.  Every generator is
+// a rapid generator, so it works in rapid.Check and, through
+// rapid.MakeFuzz, as a native Go fuzz target:
+//
+//	func FuzzBuild(f *testing.F) {
+//		f.Fuzz(rapid.MakeFuzz(func(t *rapid.T) {
+//			weights := testutil.Weights().Draw(t, "weights")
+//			...
+//		}))
+//	}
+//
+// The vectors are hostile but valid: every weight is finite and
+// non-negative, at least one is positive, and the total does not overflow.
+package testutil
+
+import (
+	"math"
+
+	"pgregory.net/rapid"
+)
+
+// MaxLen is the longest vector any generator here produces.
+const MaxLen = 1 << 10
+
+/* maxExp keeps MaxLen weights of up to 2^maxExp from overflowing when they
+ * are summed.
+ */
+const maxExp = 1023 - 11
+
+// Weights draws from all of the generators below.
+func Weights() *rapid.Generator[[]float64] {
+	return rapid.OneOf(HugeRange(), NearEqual(), Denormal(), ZeroRuns())
+}
+
+// HugeRange draws weights whose exponents span nearly the whole float64
+// range, from the smallest normal numbers to ones just short of
+// overflowing the total.
+func HugeRange() *rapid.Generator[[]float64] {
+	return rapid.Custom(func(t *rapid.T) []float64 {
+		n := rapid.IntRange(1, MaxLen).Draw(t, "n")
+		res := make([]float64, n)
+		for i := range res {
+			frac := rapid.Float64Range(1, 2).Draw(t, "frac")
+			exp := rapid.IntRange(-1021, maxExp).Draw(t, "exp")
+			res[i] = math.Ldexp(frac, exp)
+		}
+		return res
+	})
+}
+
+// NearEqual draws weights a few ulps either side of a common value, so
+// that nearly every scaled probability lands next to the 1 that splits a
+// table's small columns from its large ones.
+func NearEqual() *rapid.Generator[[]float64] {
+	return rapid.Custom(func(t *rapid.T) []float64 {
+		n := rapid.IntRange(1, MaxLen).Draw(t, "n")
+		base := math.Ldexp(1, rapid.IntRange(-100, 100).Draw(t, "scale"))
+		res := make([]float64, n)
+		for i := range res {
+			res[i] = ulps(base, rapid.IntRange(-4, 4).Draw(t, "ulps"))
+		}
+		return res
+	})
+}
+
+// Denormal draws subnormal weights, optionally mixed with zeros.
+func Denormal() *rapid.Generator[[]float64] {
+	return rapid.Custom(func(t *rapid.T) []float64 {
+		n := rapid.IntRange(1, MaxLen).Draw(t, "n")
+		zeros := rapid.Bool().Draw(t, "zeros")
+		res := make([]float64, n)
+		for i := range res {
+			if zeros && rapid.Bool().Draw(t, "zero") {
+				continue
+			}
+			res[i] = denormal(t)
+		}
+		ensurePositive(t, res, denormal)
+		return res
+	})
+}
+
+// ZeroRuns draws vectors that are mostly long runs of zeros, broken by
+// the occasional positive weight.
+func ZeroRuns() *rapid.Generator[[]float64] {
+	return rapid.Custom(func(t *rapid.T) []float64 {
+		n := rapid.IntRange(1, MaxLen).Draw(t, "n")
+		res := make([]float64, n)
+		for i := 0; i < n; {
+			i += rapid.IntRange(0, n).Draw(t, "run")
+			if i < n {
+				res[i] = rapid.Float64Range(0x1p-20, 0x1p20).Draw(t, "weight")
+				i++
+			}
+		}
+		ensurePositive(t, res, func(t *rapid.T) float64 {
+			return rapid.Float64Range(0x1p-20, 0x1p20).Draw(t, "weight")
+		})
+		return res
+	})
+}
+
+/* ensurePositive makes sure res has a positive weight, drawing one from
+ * weight and putting it at a random index if it doesn't.
+ */
+func ensurePositive(t *rapid.T, res []float64, weight func(*rapid.T) float64) {
+	for _, w := range res {
+		if w > 0 {
+			return
+		}
+	}
+	res[rapid.IntRange(0, len(res)-1).Draw(t, "index")] = weight(t)
+}
+
+func denormal(t *rapid.T) float64 {
+	return math.Float64frombits(rapid.Uint64Range(1, 1<<52-1).Draw(t, "bits"))
+}
+
+/* ulps steps x by k units in the last place. */
+func ulps(x float64, k int) float64 {
+	dir := math.Inf(1)
+	if k < 0 {
+		dir, k = math.Inf(-1), -k
+	}
+	for range k {
+		x = math.Nextafter(x, dir)
+	}
+	return x
+}
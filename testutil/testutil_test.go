@@ -0,0 +1,48 @@
+package testutil
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestWeights(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := Weights().Draw(t, "weights")
+		if len(weights) == 0 || len(weights) > MaxLen {
+			t.Fatalf("got %d weights\n", len(weights))
+		}
+		var total float64
+		for i, w := range weights {
+			if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+				t.Fatalf("weight %d is %v\n", i, w)
+			}
+			total += w
+		}
+		if total <= 0 || math.IsInf(total, 0) {
+			t.Fatalf("got total %v\n", total)
+		}
+	})
+}
+
+func TestDenormal(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		for i, w := range Denormal().Draw(t, "weights") {
+			if w >= math.SmallestNonzeroFloat64*(1<<52) {
+				t.Fatalf("weight %d is %v, which is normal\n", i, w)
+			}
+		}
+	})
+}
+
+func TestNearEqual(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		weights := NearEqual().Draw(t, "weights")
+		for i, w := range weights {
+			if math.Abs(w-weights[0]) > 16*(math.Nextafter(w, math.Inf(1))-w) {
+				t.Fatalf("weights 0 and %d are %v and %v\n", i, weights[0], w)
+			}
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package alias_sample
+
+import (
+	r "math/rand"
+	"slices"
+)
+
+// Ranked supports draws truncated to the most probable indices of a
+// sampler, as in top-k and nucleus (top-p) sampling.  It sorts the indices
+// once, by decreasing probability with ties broken by index, and keeps a
+// running sum over that order, so each truncated draw is a binary search
+// rather than a rebuild.  It draws from its sampler's random source.
+type Ranked struct {
+	s          *AliasSampler
+	order      []int     // indices by decreasing probability
+	cumulative []float64 // running sum of probabilities over order
+}
+
+func NewRanked(s *AliasSampler) *Ranked {
+	dist := s.distribution()
+	order := make([]int, s.n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case dist[a] > dist[b]:
+			return -1
+		case dist[a] < dist[b]:
+			return 1
+		}
+		return 0
+	})
+
+	cumulative := make([]float64, s.n)
+	var acc float64
+	for k, i := range order {
+		acc += dist[i]
+		cumulative[k] = acc
+	}
+	return &Ranked{s: s, order: order, cumulative: cumulative}
+}
+
+// NextTopK draws from the k most probable indices, renormalized.  k is
+// clamped to [1, n].
+func (t *Ranked) NextTopK(k int) int {
+	return t.NextTopKFrom(t.s.source(), k)
+}
+
+func (t *Ranked) NextTopKFrom(rng *r.Rand, k int) int {
+	k = min(max(k, 1), len(t.order))
+	return t.draw(rng, k)
+}
+
+// NextTopP draws from the smallest set of most probable indices whose
+// total probability is at least p, renormalized.  p is clamped to [0, 1];
+// p == 0 draws only the most probable index.
+func (t *Ranked) NextTopP(p float64) int {
+	return t.NextTopPFrom(t.s.source(), p)
+}
+
+func (t *Ranked) NextTopPFrom(rng *r.Rand, p float64) int {
+	return t.draw(rng, t.nucleus(p))
+}
+
+/* nucleus returns how many of the top indices it takes to reach mass p. */
+func (t *Ranked) nucleus(p float64) int {
+	target := min(max(p, 0), 1) * t.cumulative[len(t.cumulative)-1]
+	k, _ := slices.BinarySearch(t.cumulative, target)
+	return min(k+1, len(t.order))
+}
+
+/* draw samples among the first k indices of the ranking. */
+func (t *Ranked) draw(rng *r.Rand, k int) int {
+	u := rng.Float64() * t.cumulative[k-1]
+	j, found := slices.BinarySearch(t.cumulative[:k], u)
+	if found {
+		/* The running sum is the upper edge of an entry's interval. */
+		j++
+	}
+	return t.order[min(j, k-1)]
+}
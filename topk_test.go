@@ -0,0 +1,42 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRanked(t *testing.T) {
+	as, err := Init([]float64{1, 4, 2, 3}, WithSeed(1))
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	rk := NewRanked(as)
+
+	count := func(draw func() int) map[int]float64 {
+		sz := 100_000
+		counts := map[int]float64{}
+		for range sz {
+			counts[draw()]++
+		}
+		for i := range counts {
+			counts[i] /= float64(sz)
+		}
+		return counts
+	}
+	check := func(name string, got, want map[int]float64) {
+		for i := range 4 {
+			if math.Abs(got[i]-want[i]) > 0.01 {
+				t.Errorf("%s: P(%d) = %g, want %g\n", name, i, got[i], want[i])
+			}
+		}
+	}
+
+	check("top 2", count(func() int { return rk.NextTopK(2) }), map[int]float64{1: 4.0 / 7, 3: 3.0 / 7})
+	check("top 1", count(func() int { return rk.NextTopK(0) }), map[int]float64{1: 1})
+	check("top 9", count(func() int { return rk.NextTopK(9) }), map[int]float64{0: 0.1, 1: 0.4, 2: 0.2, 3: 0.3})
+
+	/* The top two indices hold 0.7 of the mass. */
+	check("top 0.69", count(func() int { return rk.NextTopP(0.69) }), map[int]float64{1: 4.0 / 7, 3: 3.0 / 7})
+	check("top 0.71", count(func() int { return rk.NextTopP(0.71) }), map[int]float64{1: 4.0 / 9, 3: 3.0 / 9, 2: 2.0 / 9})
+	check("top 0", count(func() int { return rk.NextTopP(0) }), map[int]float64{1: 1})
+}
@@ -0,0 +1,53 @@
+package alias_sample
+
+// WithTracking makes the sampler count how often Next, NextFrom and NextN
+// return each index, so that the realized frequencies can be checked
+// against the target distribution with Empirical.  Counting is atomic, so
+// NextFrom stays safe for concurrent use, but it does cost a contended
+// write per draw.  Draws made through other methods, such as NextExcluding
+// or NextInRange, are not counted, since they follow other distributions.
+func WithTracking() Option {
+	return func(c *config) {
+		c.track = true
+	}
+}
+
+// Empirical returns the fraction of counted draws that went to each index,
+// or nil if the sampler was built without WithTracking.  Before any draws
+// it returns all zeros.
+func (s *AliasSampler) Empirical() []float64 {
+	if s.counts == nil {
+		return nil
+	}
+	res := make([]float64, s.n)
+	var tot uint64
+	for i := range s.counts {
+		c := s.counts[i].Load()
+		res[i] = float64(c)
+		tot += c
+	}
+	if tot > 0 {
+		for i := range res {
+			res[i] /= float64(tot)
+		}
+	}
+	return res
+}
+
+// Draws returns how many draws have been counted since the sampler was
+// built or last reset, or 0 without WithTracking.
+func (s *AliasSampler) Draws() uint64 {
+	var tot uint64
+	for i := range s.counts {
+		tot += s.counts[i].Load()
+	}
+	return tot
+}
+
+// ResetEmpirical sets the draw counts back to zero.  Draws made
+// concurrently with it may or may not be counted.
+func (s *AliasSampler) ResetEmpirical() {
+	for i := range s.counts {
+		s.counts[i].Store(0)
+	}
+}
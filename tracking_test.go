@@ -0,0 +1,49 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestTracking(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 20).Draw(t, "probs")
+		probs[0] += 0.001
+		as, _ := Init(probs, WithTracking())
+
+		sz := 20_000
+		for range sz / 2 {
+			as.Next()
+		}
+		batch := make([]int, sz/2)
+		as.NextN(batch)
+		if got := as.Draws(); got != uint64(sz) {
+			t.Fatalf("counted %d draws, want %d\n", got, sz)
+		}
+
+		want := tableProbs(as)
+		for i, got := range as.Empirical() {
+			if math.Abs(got-want[i]) > 0.025 {
+				t.Fatalf("index %d: got %g, want %g\n", i, got, want[i])
+			}
+		}
+
+		as.ResetEmpirical()
+		if as.Draws() != 0 {
+			t.Fatalf("counted %d draws after a reset\n", as.Draws())
+		}
+		for i, got := range as.Empirical() {
+			if got != 0 {
+				t.Fatalf("index %d: got %g after a reset\n", i, got)
+			}
+		}
+	})
+
+	as, _ := Init([]float64{1, 2})
+	as.Next()
+	if as.Empirical() != nil || as.Draws() != 0 {
+		t.Errorf("counted draws without WithTracking\n")
+	}
+}
@@ -0,0 +1,50 @@
+// A small wrapper around alias_sample.wasm.  Load wasm_exec.js (from
+// $(go env GOROOT)/lib/wasm) first, then:
+//
+//   const { AliasSampler } = await loadAliasSample("alias_sample.wasm");
+//   const s = new AliasSampler(new Float64Array([1, 2, 3]), 42);
+//   s.next();          // one index
+//   s.nextN(1000);     // an Int32Array of indices
+//   s.destroy();       // release the Go side
+//
+// The same weights and seed give the same draws as alias_sample.InitWithSeed
+// in Go, with next matching Next and nextN matching NextN.
+
+export async function loadAliasSample(url) {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  go.run(instance);
+
+  class AliasSampler {
+    constructor(weights, seed) {
+      const s = globalThis.aliasSample.create(Float64Array.from(weights), seed);
+      if (s instanceof Error) {
+        throw s;
+      }
+      this.s = s;
+    }
+
+    next() {
+      return this.s.next();
+    }
+
+    nextN(n) {
+      const draws = this.s.nextN(n);
+      if (draws instanceof Error) {
+        throw draws;
+      }
+      return draws;
+    }
+
+    get length() {
+      return this.s.len();
+    }
+
+    destroy() {
+      this.s.destroy();
+      this.s = null;
+    }
+  }
+
+  return { AliasSampler };
+}
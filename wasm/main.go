@@ -0,0 +1,155 @@
+//go:build js && wasm
+
+// Command wasm exposes alias_sample to JavaScript, so that simulations and
+// visualizations in the browser draw exactly what the Go backend draws
+// for the same seed.  Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o alias_sample.wasm ./wasm
+//
+// and load it with alias_sample.js in this directory, together with the
+// wasm_exec.js that ships with Go.  Once running it defines a global
+// aliasSample object with one function,
+//
+//	aliasSample.create(weights: Float64Array, seed: number | string)
+//
+// which returns either an Error or a sampler object with next(),
+// nextN(n) returning an Int32Array, len(), and destroy().  The seed may be
+// a string to carry all 64 bits.
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"syscall/js"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func main() {
+	js.Global().Set("aliasSample", exports())
+	select {}
+}
+
+func exports() js.Value {
+	return js.ValueOf(map[string]any{
+		"create": js.FuncOf(create),
+	})
+}
+
+func create(this js.Value, args []js.Value) any {
+	if len(args) != 2 {
+		return jsError("create takes weights and a seed")
+	}
+	weights, err := float64s(args[0])
+	if err != nil {
+		return jsError(err.Error())
+	}
+	seed, err := seedOf(args[1])
+	if err != nil {
+		return jsError(err.Error())
+	}
+	if err := alias_sample.CheckWeights(weights); err != nil {
+		return jsError(err.Error())
+	}
+	s, err := alias_sample.InitWithSeed(weights, seed)
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return wrap(s)
+}
+
+/* wrap builds the JavaScript object for s.  Its functions hold on to s
+ * until destroy releases them.
+ */
+func wrap(s *alias_sample.AliasSampler) js.Value {
+	var funcs []js.Func
+	fn := func(f func(args []js.Value) any) js.Func {
+		jf := js.FuncOf(func(this js.Value, args []js.Value) any {
+			return f(args)
+		})
+		funcs = append(funcs, jf)
+		return jf
+	}
+
+	obj := js.Global().Get("Object").New()
+	obj.Set("next", fn(func([]js.Value) any {
+		return s.Next()
+	}))
+	obj.Set("nextN", fn(func(args []js.Value) any {
+		if len(args) != 1 || args[0].Type() != js.TypeNumber || args[0].Int() < 0 {
+			return jsError("nextN takes a non-negative count")
+		}
+		return int32s(s, args[0].Int())
+	}))
+	obj.Set("len", fn(func([]js.Value) any {
+		return s.Len()
+	}))
+	obj.Set("destroy", fn(func([]js.Value) any {
+		for _, f := range funcs {
+			f.Release()
+		}
+		return nil
+	}))
+	return obj
+}
+
+/* float64s copies a Float64Array (or any typed array view of float64s)
+ * into Go.
+ */
+func float64s(v js.Value) ([]float64, error) {
+	if !v.InstanceOf(js.Global().Get("Float64Array")) {
+		return nil, &jsErr{"weights must be a Float64Array"}
+	}
+	raw := make([]byte, v.Get("byteLength").Int())
+	view := js.Global().Get("Uint8Array").New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	js.CopyBytesToGo(raw, view)
+
+	res := make([]float64, len(raw)/8)
+	for i := range res {
+		res[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[8*i:]))
+	}
+	return res, nil
+}
+
+func int32s(s *alias_sample.AliasSampler, n int) js.Value {
+	draws := make([]int, n)
+	s.NextN(draws)
+	raw := make([]byte, 4*n)
+	for i, d := range draws {
+		binary.LittleEndian.PutUint32(raw[4*i:], uint32(d))
+	}
+	arr := js.Global().Get("Int32Array").New(n)
+	js.CopyBytesToJS(js.Global().Get("Uint8Array").New(arr.Get("buffer")), raw)
+	return arr
+}
+
+func seedOf(v js.Value) (int64, error) {
+	switch v.Type() {
+	case js.TypeNumber:
+		f := v.Float()
+		if f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+			return 0, &jsErr{"a numeric seed must be a safe integer; pass larger seeds as strings"}
+		}
+		return int64(f), nil
+	case js.TypeString:
+		seed, err := strconv.ParseInt(v.String(), 10, 64)
+		if err != nil {
+			return 0, &jsErr{"seed string is not a 64-bit integer"}
+		}
+		return seed, nil
+	}
+	return 0, &jsErr{"seed must be a number or a string"}
+}
+
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
+
+type jsErr struct {
+	msg string
+}
+
+func (e *jsErr) Error() string {
+	return e.msg
+}
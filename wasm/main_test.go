@@ -0,0 +1,51 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"math"
+	"syscall/js"
+	"testing"
+
+	alias_sample "github.com/evanmcc/alias_sample"
+)
+
+func TestCreate(t *testing.T) {
+	api := exports()
+	weights := js.Global().Get("Float64Array").Call("of", 1, 0, 3)
+
+	s := api.Call("create", weights, "7")
+	if s.InstanceOf(js.Global().Get("Error")) {
+		t.Fatalf("got error %v\n", s.Get("message"))
+	}
+	defer s.Call("destroy")
+
+	want, _ := alias_sample.InitWithSeed([]float64{1, 0, 3}, 7)
+	for range 100 {
+		if got, w := s.Call("next").Int(), want.Next(); got != w {
+			t.Fatalf("drew %d, Go drew %d\n", got, w)
+		}
+	}
+	batch := s.Call("nextN", 1000)
+	wantN := make([]int, 1000)
+	want.NextN(wantN)
+	for i := range wantN {
+		if got := batch.Index(i).Int(); got != wantN[i] {
+			t.Fatalf("batch draw %d: got %d, Go drew %d\n", i, got, wantN[i])
+		}
+	}
+
+	for _, bad := range [][]any{
+		{js.Global().Get("Array").Call("of", 1, 2), 1},
+		{weights, 1.5},
+		{js.Global().Get("Float64Array").New(0), 1},
+		{js.Global().Get("Float64Array").Call("of", 0, 0), 1},
+		{js.Global().Get("Float64Array").Call("of", 1, math.NaN()), 1},
+		{js.Global().Get("Float64Array").Call("of", 1, math.Inf(1)), 1},
+		{js.Global().Get("Float64Array").Call("of", -1, 2), 1},
+	} {
+		if res := api.Call("create", bad...); !res.InstanceOf(js.Global().Get("Error")) {
+			t.Errorf("create(%v) was accepted\n", bad)
+		}
+	}
+}
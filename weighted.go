@@ -0,0 +1,122 @@
+package alias_sample
+
+import (
+	"math/bits"
+	r "math/rand"
+)
+
+// Weighted samples without replacement, with the same methods as gonum's
+// sampleuv.Weighted: Take draws an index and removes it, and Reweight
+// changes the weight of one index, both in O(log n).  Unlike an alias
+// table, which must be rebuilt whenever a weight changes, it keeps the
+// weights in a tree of partial sums.
+type Weighted struct {
+	rand   *r.Rand
+	n      int
+	leaves int       // leaves of the tree, n rounded up to a power of two
+	tree   []float64 // tree[1] is the total; node k has children 2k, 2k+1
+}
+
+// NewWeighted returns a Weighted for weights, which must be non-negative
+// and finite, but may all be zero.
+func NewWeighted(weights []float64, opts ...Option) (*Weighted, error) {
+	cfg := newConfig(opts)
+	if len(weights) == 0 {
+		return nil, &SampleError{"no probabilities provided"}
+	}
+	w := &Weighted{rand: cfg.newRand(), n: len(weights)}
+	w.leaves = 1 << bits.Len(uint(len(weights)-1))
+	w.tree = make([]float64, 2*w.leaves)
+	if err := w.ReweightAll(weights); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Len returns the number of indices, taken or not.
+func (w *Weighted) Len() int {
+	return w.n
+}
+
+// Take draws an index with probability proportional to its weight and
+// sets its weight to zero, so it will not be drawn again until it is
+// reweighted.  ok is false if every weight is zero.
+func (w *Weighted) Take() (idx int, ok bool) {
+	return w.TakeFrom(w.rand)
+}
+
+func (w *Weighted) TakeFrom(rng *r.Rand) (idx int, ok bool) {
+	if !(w.tree[1] > 0) {
+		return -1, false
+	}
+
+	u := rng.Float64() * w.tree[1]
+	k := 1
+	for k < w.leaves {
+		left := w.tree[2*k]
+		/* Rounding can leave u just past the left subtree even when the
+		 * right one is empty; it belongs on the left then.
+		 */
+		if u < left || w.tree[2*k+1] == 0 {
+			k = 2 * k
+		} else {
+			u -= left
+			k = 2*k + 1
+		}
+	}
+	idx = k - w.leaves
+	w.set(idx, 0)
+	return idx, true
+}
+
+// Reweight sets the weight of index idx, which must be in range, to weight,
+// which must be non-negative and finite.
+func (w *Weighted) Reweight(idx int, weight float64) error {
+	if idx < 0 || idx >= w.n {
+		return &SampleError{"index out of range"}
+	}
+	if !ValidWeight(weight) {
+		return &SampleError{"weights must be finite and non-negative"}
+	}
+	w.set(idx, weight)
+	return nil
+}
+
+// ReweightAll replaces every weight at once, in O(n).
+func (w *Weighted) ReweightAll(weights []float64) error {
+	if len(weights) != w.n {
+		return &SampleError{"weights length does not match the sampler"}
+	}
+	for _, x := range weights {
+		if !ValidWeight(x) {
+			return &SampleError{"weights must be finite and non-negative"}
+		}
+	}
+	copy(w.tree[w.leaves:], weights)
+	for k := w.leaves - 1; k >= 1; k-- {
+		w.tree[k] = w.tree[2*k] + w.tree[2*k+1]
+	}
+	return nil
+}
+
+/* set updates one leaf and recomputes the sums above it from scratch, so
+ * that rounding errors don't accumulate over many updates.
+ */
+func (w *Weighted) set(idx int, weight float64) {
+	k := idx + w.leaves
+	w.tree[k] = weight
+	for k > 1 {
+		k /= 2
+		w.tree[k] = w.tree[2*k] + w.tree[2*k+1]
+	}
+}
+
+/* grow extends w to n indices, the new ones with weight zero. */
+func (w *Weighted) grow(n int) {
+	weights := make([]float64, n)
+	copy(weights, w.tree[w.leaves:w.leaves+w.n])
+	w.n = n
+	w.leaves = 1 << bits.Len(uint(n-1))
+	w.tree = make([]float64, 2*w.leaves)
+	w.ReweightAll(weights)
+}
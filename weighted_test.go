@@ -0,0 +1,69 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+func TestWeighted(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		probs := rapid.SliceOfN(rapid.Float64Range(0, 5.0), 1, 50).Draw(t, "probs")
+		probs[0] += 0.001
+
+		/* The first draw follows the weights. */
+		var tot float64
+		for _, p := range probs {
+			tot += p
+		}
+		sz := 20_000
+		counts := make([]int, len(probs))
+		w, err := NewWeighted(probs)
+		if err != nil {
+			t.Fatalf("got err %v\n", err)
+		}
+		for range sz {
+			i, ok := w.Take()
+			if !ok {
+				t.Fatalf("nothing to take\n")
+			}
+			counts[i]++
+			w.Reweight(i, probs[i])
+		}
+		for i, c := range counts {
+			if got := float64(c) / float64(sz); math.Abs(got-probs[i]/tot) > 0.025 {
+				t.Fatalf("index %d: got %g, want %g\n", i, got, probs[i]/tot)
+			}
+		}
+
+		/* Taking everything gives each nonzero index exactly once. */
+		taken := map[int]bool{}
+		for {
+			i, ok := w.Take()
+			if !ok {
+				break
+			}
+			if taken[i] || probs[i] == 0 {
+				t.Fatalf("took %d (weight %g) twice or by mistake\n", i, probs[i])
+			}
+			taken[i] = true
+		}
+		for i, p := range probs {
+			if p > 0 && !taken[i] {
+				t.Fatalf("never took %d\n", i)
+			}
+		}
+	})
+
+	w, _ := NewWeighted([]float64{0, 0})
+	if _, ok := w.Take(); ok {
+		t.Errorf("took from all-zero weights\n")
+	}
+	if err := w.Reweight(2, 1); err == nil {
+		t.Errorf("out of range index was accepted\n")
+	}
+	if err := w.ReweightAll([]float64{1, math.Inf(1)}); err == nil {
+		t.Errorf("infinite weight was accepted\n")
+	}
+}
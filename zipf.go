@@ -0,0 +1,32 @@
+package alias_sample
+
+import (
+	"math"
+)
+
+// NewZipf returns a sampler over 0..n-1 where P(k) is proportional to
+// (v + k) ** (-s), the same family as rand.Zipf.  Because the support is
+// finite, any s >= 0 is allowed (s == 0 is uniform), and v need only be
+// positive.  Any further options are applied as for Init.
+func NewZipf(n int, s, v float64, seed int64, opts ...Option) (*AliasSampler, error) {
+	if n <= 0 {
+		return nil, &SampleError{"zipf: n must be positive"}
+	}
+	if !(s >= 0) || math.IsInf(s, 1) {
+		return nil, &SampleError{"zipf: s must be finite and non-negative"}
+	}
+	if !(v > 0) || math.IsInf(v, 1) {
+		return nil, &SampleError{"zipf: v must be finite and positive"}
+	}
+
+	/* Work in logs relative to the first term, so that large s can't
+	 * underflow every weight to zero.
+	 */
+	weights := make([]float64, n)
+	base := math.Log(v)
+	for k := range weights {
+		weights[k] = math.Exp(-s * (math.Log(v+float64(k)) - base))
+	}
+
+	return InitInPlace(weights, append(opts[:len(opts):len(opts)], WithSeed(seed))...)
+}
@@ -0,0 +1,42 @@
+package alias_sample
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewZipf(t *testing.T) {
+	as, err := NewZipf(10, 1.5, 2, 1)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+
+	want := make([]float64, 10)
+	var tot float64
+	for k := range want {
+		want[k] = math.Pow(2+float64(k), -1.5)
+		tot += want[k]
+	}
+	for k, p := range tableProbs(as) {
+		if math.Abs(p-want[k]/tot) > 1e-12 {
+			t.Fatalf("index %d: got %g, want %g\n", k, p, want[k]/tot)
+		}
+	}
+
+	/* Huge exponents put essentially all the mass on 0, but must not
+	 * underflow to an empty distribution.
+	 */
+	as, err = NewZipf(5, 5000, 1, 1)
+	if err != nil {
+		t.Fatalf("got err %v\n", err)
+	}
+	if got := as.Next(); got != 0 {
+		t.Fatalf("drew %d, want 0\n", got)
+	}
+
+	for _, bad := range [][3]float64{{0, 1, 1}, {5, -1, 1}, {5, 1, 0}, {5, math.NaN(), 1}} {
+		if _, err := NewZipf(int(bad[0]), bad[1], bad[2], 1); err == nil {
+			t.Errorf("NewZipf(%v) was accepted\n", bad)
+		}
+	}
+}